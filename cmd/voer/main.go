@@ -25,6 +25,19 @@ func main() {
 			command.UploadCommand(config),
 			command.ServerCommand(config),
 			command.DownloadCommand(config),
+			command.LintCommand(config),
+			command.PullCommand(config),
+			command.GenerateCommand(config),
+			command.LoginCommand(config),
+			command.LogoutCommand(config),
+			command.TokenCommand(config),
+			command.RoleCommand(config),
+			command.DeprecateCommand(config),
+			command.DeleteCommand(config),
+			command.PackageCommand(config),
+			command.AuditCommand(config),
+			command.EventsCommand(config),
+			command.AdminCommand(config),
 		},
 	}
 
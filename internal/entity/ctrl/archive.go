@@ -0,0 +1,822 @@
+package ctrl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/proto"
+)
+
+const (
+	// ArchiveFormat identifies registry archives in their header record
+	ArchiveFormat = "voer-archive"
+
+	// ArchiveFormatVersion is the version of the archive format written by ExportRegistry
+	ArchiveFormatVersion = 1
+)
+
+// Archive record types
+const (
+	recordHeader         = "header"
+	recordBlob           = "blob"
+	recordPackage        = "package"
+	recordPackageVersion = "package_version"
+	recordFile           = "file"
+	recordMessage        = "message"
+	recordMessageVersion = "message_version"
+	recordRoleGrant      = "role_grant"
+)
+
+// archiveRecord is a single line of a registry archive. Type selects which of the other fields are set.
+type archiveRecord struct {
+	Type string `json:"type"`
+
+	// header
+	Format        string     `json:"format,omitempty"`
+	FormatVersion int        `json:"format_version,omitempty"`
+	ExportedAt    *time.Time `json:"exported_at,omitempty"`
+
+	// blob, file
+	Digest   string `json:"digest,omitempty"`
+	Contents string `json:"contents,omitempty"`
+
+	// package, package_version, file, message, message_version
+	Package string   `json:"package,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+
+	// package_version, file, message_version
+	Version     int        `json:"version,omitempty"`
+	ContentHash string     `json:"content_hash,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+
+	// package_version, message
+	Deprecation *archiveDeprecation `json:"deprecation,omitempty"`
+
+	// file
+	FileName   string `json:"file_name,omitempty"`
+	ImportPath string `json:"import_path,omitempty"`
+
+	// message, message_version
+	Message          string `json:"message,omitempty"`
+	ProtoBody        string `json:"proto_body,omitempty"`
+	SerializedSchema []byte `json:"serialized_schema,omitempty"`
+	PackageVersion   int    `json:"package_version,omitempty"`
+
+	// message_version: later package versions that contain the message version unchanged
+	LinkedVersions []int `json:"linked_versions,omitempty"`
+
+	// role_grant
+	Subject        string `json:"subject,omitempty"`
+	Role           string `json:"role,omitempty"`
+	PackagePattern string `json:"package_pattern,omitempty"`
+}
+
+// archiveDeprecation is the deprecation of a package version or message in an archive
+type archiveDeprecation struct {
+	DeprecatedAt time.Time `json:"deprecated_at"`
+	Reason       string    `json:"reason,omitempty"`
+	Replacement  string    `json:"replacement,omitempty"`
+}
+
+func toArchiveDeprecation(deprecation entity.Deprecation) *archiveDeprecation {
+	if !deprecation.IsDeprecated() {
+		return nil
+	}
+	return &archiveDeprecation{
+		DeprecatedAt: *deprecation.DeprecatedAt,
+		Reason:       deprecation.DeprecationReason,
+		Replacement:  deprecation.DeprecationReplacement,
+	}
+}
+
+func (d *archiveDeprecation) toEntity() entity.Deprecation {
+	if d == nil {
+		return entity.Deprecation{}
+	}
+	deprecatedAt := d.DeprecatedAt
+	return entity.Deprecation{
+		DeprecatedAt:           &deprecatedAt,
+		DeprecationReason:      d.Reason,
+		DeprecationReplacement: d.Replacement,
+	}
+}
+
+// ArchiveStats counts the entities written to or read from an archive
+type ArchiveStats struct {
+	Packages        int
+	Versions        int
+	Files           int
+	Blobs           int
+	Messages        int
+	MessageVersions int
+	RoleGrants      int
+
+	// Versions that already existed in the store and were left untouched by an import
+	SkippedVersions int
+}
+
+// ExportRegistry writes every package, including deleted versions, and every role grant to an archive of JSON lines.
+// API tokens and the audit log are not exported.
+func ExportRegistry(store repo.Store, w io.Writer) (*ArchiveStats, error) {
+	stats := &ArchiveStats{}
+
+	// Read everything in one transaction so SQLite exports are consistent
+	err := store.Transaction(func(tx repo.Store) error {
+		enc := json.NewEncoder(w)
+
+		if err := writeArchiveHeader(enc); err != nil {
+			return err
+		}
+
+		packages, err := tx.Packages().List(-1, 0, "")
+		if err != nil {
+			return err
+		}
+		sort.Slice(packages, func(i, j int) bool { return packages[i].PackageName < packages[j].PackageName })
+
+		writtenBlobs := make(map[string]bool)
+		for _, pkg := range packages {
+			if err := exportPackage(tx, enc, pkg, writtenBlobs, stats); err != nil {
+				return err
+			}
+		}
+
+		grants, err := tx.RoleGrants().List("")
+		if err != nil {
+			return err
+		}
+		for _, grant := range grants {
+			if err := enc.Encode(archiveRecord{Type: recordRoleGrant, Subject: grant.Subject, Role: grant.Role, PackagePattern: grant.PackagePattern}); err != nil {
+				return fmt.Errorf("failed to write archive: %w", err)
+			}
+			stats.RoleGrants++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// writeArchiveHeader writes the record that starts every archive
+func writeArchiveHeader(enc *json.Encoder) error {
+	exportedAt := time.Now().UTC()
+	if err := enc.Encode(archiveRecord{Type: recordHeader, Format: ArchiveFormat, FormatVersion: ArchiveFormatVersion, ExportedAt: &exportedAt}); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// exportPackage writes a package with its versions, files and messages. Blobs are written once, before the first file
+// that references them.
+func exportPackage(store repo.Store, enc *json.Encoder, pkg entity.Package, writtenBlobs map[string]bool, stats *ArchiveStats) error {
+	write := func(record archiveRecord) error {
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		return nil
+	}
+
+	aliases, err := store.Packages().ListAliases(pkg.ID)
+	if err != nil {
+		return err
+	}
+	record := archiveRecord{Type: recordPackage, Package: pkg.PackageName}
+	for _, alias := range aliases {
+		record.Aliases = append(record.Aliases, alias.AliasName)
+	}
+	if err := write(record); err != nil {
+		return err
+	}
+	stats.Packages++
+
+	pkgVersions, err := store.PackageVersions().List(pkg.ID, true)
+	if err != nil {
+		return err
+	}
+	slices.Reverse(pkgVersions)
+
+	versionNumbers := make(map[uint]int)
+	for _, pkgVersion := range pkgVersions {
+		versionNumbers[pkgVersion.ID] = pkgVersion.Version
+
+		createdAt := pkgVersion.CreatedAt
+		err := write(archiveRecord{
+			Type:        recordPackageVersion,
+			Package:     pkg.PackageName,
+			Version:     pkgVersion.Version,
+			ContentHash: pkgVersion.ContentHash,
+			CreatedAt:   &createdAt,
+			DeletedAt:   pkgVersion.DeletedAt,
+			Deprecation: toArchiveDeprecation(pkgVersion.Deprecation),
+		})
+		if err != nil {
+			return err
+		}
+		stats.Versions++
+
+		files, err := store.Files().List(pkgVersion.ID)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if !writtenBlobs[file.Digest] {
+				if err := write(archiveRecord{Type: recordBlob, Digest: file.Digest, Contents: file.FileContents}); err != nil {
+					return err
+				}
+				writtenBlobs[file.Digest] = true
+				stats.Blobs++
+			}
+
+			err := write(archiveRecord{
+				Type:       recordFile,
+				Package:    pkg.PackageName,
+				Version:    pkgVersion.Version,
+				FileName:   file.FileName,
+				ImportPath: file.ImportPath,
+				Digest:     file.Digest,
+			})
+			if err != nil {
+				return err
+			}
+			stats.Files++
+		}
+	}
+
+	messages, err := store.Messages().ListByPackage(pkg.ID)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		err := write(archiveRecord{
+			Type:        recordMessage,
+			Package:     pkg.PackageName,
+			Message:     message.Name,
+			ProtoBody:   message.ProtoBody,
+			Deprecation: toArchiveDeprecation(message.Deprecation),
+		})
+		if err != nil {
+			return err
+		}
+		stats.Messages++
+
+		versions, err := store.Messages().ListVersions(message.ID)
+		if err != nil {
+			return err
+		}
+
+		links, err := store.Messages().ListVersionLinks(message.ID)
+		if err != nil {
+			return err
+		}
+		linkedVersions := make(map[uint][]int)
+		for _, link := range links {
+			linkedVersions[link.MessageVersionID] = append(linkedVersions[link.MessageVersionID], versionNumbers[link.PackageVersionID])
+		}
+
+		for _, version := range versions {
+			// The package version introducing the message version is already recorded
+			linked := slices.DeleteFunc(linkedVersions[version.ID], func(number int) bool {
+				return number == versionNumbers[version.PackageVersionID]
+			})
+			slices.Sort(linked)
+
+			createdAt := version.CreatedAt
+			err := write(archiveRecord{
+				Type:             recordMessageVersion,
+				Package:          pkg.PackageName,
+				Message:          message.Name,
+				Version:          version.Version,
+				PackageVersion:   versionNumbers[version.PackageVersionID],
+				LinkedVersions:   linked,
+				ProtoBody:        version.ProtoBody,
+				SerializedSchema: []byte(version.SerializedSchema),
+				ContentHash:      version.ContentHash,
+				CreatedAt:        &createdAt,
+			})
+			if err != nil {
+				return err
+			}
+			stats.MessageVersions++
+		}
+	}
+
+	return nil
+}
+
+// ImportRegistry replays an archive into a store in a single transaction, so a failed import leaves the store
+// untouched. Packages and messages are matched by name and versions keep their numbers. Versions that already exist
+// are skipped when their content hash matches the archive and fail the import otherwise, which makes importing the
+// same archive twice a no-op.
+func ImportRegistry(ctx context.Context, store repo.Store, r io.Reader) (*ArchiveStats, error) {
+	return importArchive(ctx, store, r, false)
+}
+
+// importArchive replays an archive into a store in a single transaction. With replace set, the archive is
+// authoritative for the packages it contains: conflicting versions are replaced, versions missing from the archive
+// are purged, and deletions, deprecations and former names are overwritten. An import event is recorded for every
+// package of the archive in the same transaction, so the change feed lists imported packages.
+func importArchive(ctx context.Context, store repo.Store, r io.Reader, replace bool) (*ArchiveStats, error) {
+	stats := &ArchiveStats{}
+
+	err := store.Transaction(func(tx repo.Store) error {
+		importer := &archiveImporter{
+			store:    tx,
+			stats:    stats,
+			replace:  replace,
+			blobs:    make(map[string]string),
+			packages: make(map[string]uint),
+			versions: make(map[versionKey]*importedVersion),
+			messages: make(map[messageKey]uint),
+		}
+
+		dec := json.NewDecoder(r)
+		for line := 1; ; line++ {
+			var record archiveRecord
+			if err := dec.Decode(&record); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return fmt.Errorf("record %d: invalid archive: %w", line, err)
+			}
+
+			if line == 1 && record.Type != recordHeader {
+				return fmt.Errorf("record %d: archive does not start with a header", line)
+			}
+			if err := importer.importRecord(record); err != nil {
+				return fmt.Errorf("record %d: %w", line, err)
+			}
+		}
+
+		return importer.finish(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+type versionKey struct {
+	packageName string
+	version     int
+}
+
+type messageKey struct {
+	packageName string
+	name        string
+}
+
+// importedVersion is a package version referenced by an archive
+type importedVersion struct {
+	id uint
+
+	// Set when the version already existed, in which case its files and message versions are not imported
+	skipped bool
+}
+
+// archiveImporter tracks the entities created while replaying an archive, so later records can reference them
+type archiveImporter struct {
+	store repo.Store
+	stats *ArchiveStats
+
+	// Make the archive authoritative for the packages it contains
+	replace bool
+
+	// Blob contents by digest
+	blobs map[string]string
+
+	// IDs of imported entities
+	packages map[string]uint
+	versions map[versionKey]*importedVersion
+	messages map[messageKey]uint
+}
+
+func (i *archiveImporter) importRecord(record archiveRecord) error {
+	switch record.Type {
+	case recordHeader:
+		if record.Format != ArchiveFormat {
+			return fmt.Errorf("not a registry archive")
+		}
+		if record.FormatVersion > ArchiveFormatVersion {
+			return fmt.Errorf("archive format version %d is not supported, upgrade voer to import it", record.FormatVersion)
+		}
+		return nil
+	case recordBlob:
+		if proto.DigestFile(record.Contents) != record.Digest {
+			return fmt.Errorf("contents of blob %s do not match its digest", record.Digest)
+		}
+		i.blobs[record.Digest] = record.Contents
+		i.stats.Blobs++
+		return nil
+	case recordPackage:
+		return i.importPackage(record)
+	case recordPackageVersion:
+		return i.importPackageVersion(record)
+	case recordFile:
+		return i.importFile(record)
+	case recordMessage:
+		return i.importMessage(record)
+	case recordMessageVersion:
+		return i.importMessageVersion(record)
+	case recordRoleGrant:
+		return i.importRoleGrant(record)
+	default:
+		return fmt.Errorf("unknown record type '%s'", record.Type)
+	}
+}
+
+func (i *archiveImporter) importPackage(record archiveRecord) error {
+	pkg, err := i.findPackage(record)
+	if err != nil {
+		return err
+	}
+	i.packages[record.Package] = pkg.ID
+	i.stats.Packages++
+
+	if i.replace {
+		if err := i.removeStaleAliases(pkg.ID, record); err != nil {
+			return err
+		}
+	}
+
+	for _, aliasName := range record.Aliases {
+		alias, err := i.store.Packages().FindAlias(aliasName)
+		if err != nil {
+			return err
+		}
+
+		if alias != nil && alias.PackageID != pkg.ID {
+			if !i.replace {
+				return fmt.Errorf("alias %s of %s already points at another package", aliasName, record.Package)
+			}
+			if err := i.store.Packages().DeleteAlias(aliasName); err != nil {
+				return err
+			}
+			alias = nil
+		}
+
+		if alias == nil {
+			if err := i.store.Packages().CreateAlias(aliasName, pkg.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// findPackage fetches the package of a package record, creating it when it does not exist. When replacing, a package
+// known by one of the record's former names is renamed instead, which replays renames.
+func (i *archiveImporter) findPackage(record archiveRecord) (*entity.Package, error) {
+	pkg, err := i.store.Packages().FindByName(record.Package)
+	if err != nil || pkg != nil {
+		return pkg, err
+	}
+
+	if i.replace {
+		for _, aliasName := range record.Aliases {
+			pkg, err := i.store.Packages().FindByName(aliasName)
+			if err != nil {
+				return nil, err
+			}
+			if pkg != nil {
+				return pkg, i.store.Packages().Rename(pkg.ID, record.Package)
+			}
+		}
+	}
+
+	return i.store.Packages().FindOrCreate(record.Package)
+}
+
+// removeStaleAliases deletes the former names of a package that the record does not list, along with any alias
+// holding the package's current name
+func (i *archiveImporter) removeStaleAliases(packageID uint, record archiveRecord) error {
+	aliases, err := i.store.Packages().ListAliases(packageID)
+	if err != nil {
+		return err
+	}
+	for _, alias := range aliases {
+		if !slices.Contains(record.Aliases, alias.AliasName) {
+			if err := i.store.Packages().DeleteAlias(alias.AliasName); err != nil {
+				return err
+			}
+		}
+	}
+
+	alias, err := i.store.Packages().FindAlias(record.Package)
+	if err != nil || alias == nil {
+		return err
+	}
+	return i.store.Packages().DeleteAlias(record.Package)
+}
+
+// packageID returns the ID of a package imported by an earlier record
+func (i *archiveImporter) packageID(packageName string) (uint, error) {
+	id, ok := i.packages[packageName]
+	if !ok {
+		return 0, fmt.Errorf("package %s is referenced before it is defined", packageName)
+	}
+	return id, nil
+}
+
+// packageVersion returns a package version imported by an earlier record
+func (i *archiveImporter) packageVersion(packageName string, version int) (*importedVersion, error) {
+	imported, ok := i.versions[versionKey{packageName, version}]
+	if !ok {
+		return nil, fmt.Errorf("version %d of %s is referenced before it is defined", version, packageName)
+	}
+	return imported, nil
+}
+
+func (i *archiveImporter) importPackageVersion(record archiveRecord) error {
+	packageID, err := i.packageID(record.Package)
+	if err != nil {
+		return err
+	}
+
+	existing, err := i.store.PackageVersions().Find(packageID, record.Version)
+	if err != nil {
+		return err
+	}
+
+	key := versionKey{record.Package, record.Version}
+	if existing != nil && existing.ContentHash == record.ContentHash {
+		if i.replace {
+			if err := i.updatePackageVersion(existing, record); err != nil {
+				return err
+			}
+		}
+		i.versions[key] = &importedVersion{id: existing.ID, skipped: true}
+		i.stats.SkippedVersions++
+		return nil
+	}
+	if existing != nil {
+		if !i.replace {
+			return fmt.Errorf("version %d of %s already exists with different contents", record.Version, record.Package)
+		}
+		if err := i.store.PackageVersions().Purge(existing.ID); err != nil {
+			return err
+		}
+	}
+
+	pkgVersion := &entity.PackageVersion{
+		PackageID:   packageID,
+		Version:     record.Version,
+		ContentHash: record.ContentHash,
+		DeletedAt:   record.DeletedAt,
+		Deprecation: record.Deprecation.toEntity(),
+	}
+	if record.CreatedAt != nil {
+		pkgVersion.CreatedAt = *record.CreatedAt
+	}
+	if err := i.store.PackageVersions().Create(pkgVersion); err != nil {
+		return err
+	}
+
+	i.versions[key] = &importedVersion{id: pkgVersion.ID}
+	i.stats.Versions++
+	return nil
+}
+
+// updatePackageVersion overwrites the deletion and deprecation of an existing version with the archive's
+func (i *archiveImporter) updatePackageVersion(pkgVersion *entity.PackageVersion, record archiveRecord) error {
+	var err error
+	switch {
+	case record.DeletedAt != nil:
+		err = i.store.PackageVersions().SoftDelete(pkgVersion.ID, *record.DeletedAt)
+	case pkgVersion.DeletedAt != nil:
+		err = i.store.PackageVersions().Restore(pkgVersion.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	var deprecation *entity.Deprecation
+	if record.Deprecation != nil {
+		d := record.Deprecation.toEntity()
+		deprecation = &d
+	}
+	return i.store.PackageVersions().SetDeprecation(pkgVersion.ID, deprecation)
+}
+
+func (i *archiveImporter) importFile(record archiveRecord) error {
+	pkgVersion, err := i.packageVersion(record.Package, record.Version)
+	if err != nil {
+		return err
+	}
+	if pkgVersion.skipped {
+		return nil
+	}
+
+	contents, ok := i.blobs[record.Digest]
+	if !ok {
+		return fmt.Errorf("blob %s of %s is referenced before it is defined", record.Digest, record.FileName)
+	}
+
+	err = i.store.Files().Create(&entity.PackageVersionFile{
+		PackageVersionID: pkgVersion.id,
+		FileName:         record.FileName,
+		ImportPath:       record.ImportPath,
+		Digest:           record.Digest,
+		FileContents:     contents,
+	})
+	if err != nil {
+		return err
+	}
+
+	i.stats.Files++
+	return nil
+}
+
+func (i *archiveImporter) importMessage(record archiveRecord) error {
+	packageID, err := i.packageID(record.Package)
+	if err != nil {
+		return err
+	}
+
+	message, err := i.store.Messages().FindOrCreate(packageID, record.Message, record.ProtoBody)
+	if err != nil {
+		return err
+	}
+
+	// Unless replacing, deprecations are only added, so importing never undoes a deprecation made in the target registry
+	if record.Deprecation != nil || i.replace {
+		var deprecation *entity.Deprecation
+		if record.Deprecation != nil {
+			d := record.Deprecation.toEntity()
+			deprecation = &d
+		}
+		if err := i.store.Messages().SetDeprecation(message.ID, deprecation); err != nil {
+			return err
+		}
+	}
+
+	i.messages[messageKey{record.Package, record.Message}] = message.ID
+	i.stats.Messages++
+	return nil
+}
+
+func (i *archiveImporter) importMessageVersion(record archiveRecord) error {
+	pkgVersion, err := i.packageVersion(record.Package, record.PackageVersion)
+	if err != nil {
+		return err
+	}
+
+	messageID, ok := i.messages[messageKey{record.Package, record.Message}]
+	if !ok {
+		return fmt.Errorf("message %s of %s is referenced before it is defined", record.Message, record.Package)
+	}
+
+	if pkgVersion.skipped {
+		return i.linkExistingMessageVersion(record, messageID)
+	}
+
+	version := &entity.MessageVersion{
+		MessageID:        messageID,
+		PackageVersionID: pkgVersion.id,
+		Version:          record.Version,
+		ProtoBody:        record.ProtoBody,
+		SerializedSchema: string(record.SerializedSchema),
+		ContentHash:      record.ContentHash,
+	}
+	if record.CreatedAt != nil {
+		version.CreatedAt = *record.CreatedAt
+	}
+	if err := i.store.Messages().CreateVersion(version); err != nil {
+		return err
+	}
+
+	i.stats.MessageVersions++
+	return i.linkMessageVersion(record, version)
+}
+
+// linkExistingMessageVersion links the new package versions of a record to a message version that was skipped along
+// with the package version introducing it
+func (i *archiveImporter) linkExistingMessageVersion(record archiveRecord, messageID uint) error {
+	if len(record.LinkedVersions) == 0 {
+		return nil
+	}
+
+	versions, err := i.store.Messages().ListVersions(messageID)
+	if err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		if version.Version == record.Version {
+			return i.linkMessageVersion(record, &version)
+		}
+	}
+	return fmt.Errorf("version %d of message %s of %s is missing from the store", record.Version, record.Message, record.Package)
+}
+
+// linkMessageVersion links the package versions of a record that contain the message version unchanged. Package
+// versions skipped by the import already have their links.
+func (i *archiveImporter) linkMessageVersion(record archiveRecord, version *entity.MessageVersion) error {
+	for _, number := range record.LinkedVersions {
+		pkgVersion, err := i.packageVersion(record.Package, number)
+		if err != nil {
+			return err
+		}
+		if pkgVersion.skipped {
+			continue
+		}
+
+		if err := i.store.Messages().LinkVersion(pkgVersion.id, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *archiveImporter) importRoleGrant(record archiveRecord) error {
+	existing, err := i.store.RoleGrants().Find(record.Subject, record.Role, record.PackagePattern)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		err := i.store.RoleGrants().Create(&entity.RoleGrant{Subject: record.Subject, Role: record.Role, PackagePattern: record.PackagePattern})
+		if err != nil {
+			return err
+		}
+	}
+
+	i.stats.RoleGrants++
+	return nil
+}
+
+// finish points every imported package and its messages at their latest versions. When replacing, versions missing
+// from the archive are purged first, along with messages left without versions. Then records an import event for
+// every package.
+func (i *archiveImporter) finish(ctx context.Context) error {
+	for packageName, packageID := range i.packages {
+		if i.replace {
+			if err := i.purgeMissingVersions(packageName, packageID); err != nil {
+				return err
+			}
+		}
+
+		if err := i.store.Packages().AssignLatestVersion(packageID); err != nil {
+			return err
+		}
+		if err := i.store.Messages().AssignLatestVersions(packageID); err != nil {
+			return err
+		}
+
+		if i.replace {
+			if err := i.store.Messages().DeleteOrphaned(packageID); err != nil {
+				return err
+			}
+		}
+	}
+
+	detail := "imported"
+	if i.replace {
+		detail = "replicated"
+	}
+	for _, packageName := range slices.Sorted(maps.Keys(i.packages)) {
+		err := audit.RecordSuccess(ctx, i.store.AuditEvents(), audit.Event{
+			Actor:       audit.SystemActor,
+			Action:      audit.ActionImport,
+			PackageName: packageName,
+			Detail:      detail,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeMissingVersions purges the versions of a package that the archive does not contain
+func (i *archiveImporter) purgeMissingVersions(packageName string, packageID uint) error {
+	pkgVersions, err := i.store.PackageVersions().List(packageID, true)
+	if err != nil {
+		return err
+	}
+
+	for _, pkgVersion := range pkgVersions {
+		if _, ok := i.versions[versionKey{packageName, pkgVersion.Version}]; ok {
+			continue
+		}
+		if err := i.store.PackageVersions().Purge(pkgVersion.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package ctrl
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/infra/sqlite"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := repo.NewMemoryStore()
+
+	uploadTestPackage(t, source, "archive", "message A { string x = 1; }\n")
+	uploadTestPackage(t, source, "archive", "message A { string x = 1; string y = 2; }\nmessage B { string z = 1; }\n")
+	uploadTestPackage(t, source, "archive", "message A { string x = 1; string y = 2; }\nmessage B { string z = 1; }\nmessage C { string x = 1; }\n")
+
+	if _, err := DeletePackageVersion(ctx, source, nil, nil, &v1.DeletePackageVersionRequest{PackageName: "archive", Version: 3}); err != nil {
+		t.Fatalf("Failed to delete version: %v", err)
+	}
+	if _, err := RenamePackage(ctx, source, nil, &v1.RenamePackageRequest{PackageName: "archive", NewName: "archive.v1"}); err != nil {
+		t.Fatalf("Failed to rename package: %v", err)
+	}
+	if _, err := DeprecatePackageVersion(ctx, source, nil, &v1.DeprecatePackageVersionRequest{PackageName: "archive.v1", Version: 1, Reason: "old"}); err != nil {
+		t.Fatalf("Failed to deprecate version: %v", err)
+	}
+	if err := source.RoleGrants().Create(&entity.RoleGrant{Subject: "token:ci", Role: "publisher", PackagePattern: "archive.*"}); err != nil {
+		t.Fatalf("Failed to grant role: %v", err)
+	}
+
+	var archive bytes.Buffer
+	exported, err := ExportRegistry(source, &archive)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if exported.Versions != 3 || exported.MessageVersions != 4 || exported.RoleGrants != 1 {
+		t.Fatalf("Unexpected export: %+v", exported)
+	}
+
+	db, err := sqlite.NewDB(filepath.Join(t.TempDir(), "voer.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	target := repo.NewSQLStore(db)
+
+	imported, err := ImportRegistry(ctx, target, bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if imported.Versions != 3 || imported.Files != 3 || imported.SkippedVersions != 0 {
+		t.Fatalf("Unexpected import: %+v", imported)
+	}
+	if changes, _, err := ListChanges(ctx, target, nil, 0, 100); err != nil || len(changes) != 1 || changes[0].Action != audit.ActionImport || changes[0].PackageName != "archive.v1" {
+		t.Fatalf("Expected the import of archive.v1 to be listed as a change, got %v (%v)", changes, err)
+	}
+
+	// Version numbers, deletions, deprecations and former names are preserved
+	pkg, err := target.Packages().FindByName("archive.v1")
+	if err != nil || pkg == nil || pkg.LatestVersion == nil || pkg.LatestVersion.Version != 2 {
+		t.Fatalf("Expected latest version 2, got %v (%v)", pkg, err)
+	}
+	got, err := GetPackageVersion(ctx, target, &v1.GetPackageVersionRequest{PackageName: "archive", Version: 1})
+	if err != nil || got.PackageVersion.Deprecation.GetReason() != "old" {
+		t.Fatalf("Expected deprecated version 1 by former name, got %v (%v)", got, err)
+	}
+	if count := countTestMessages(t, target, "archive.v1"); count != 2 {
+		t.Fatalf("Expected 2 visible messages, got %d", count)
+	}
+
+	// Binary schemas survive the round trip
+	sourcePkg, _ := source.Packages().FindByName("archive.v1")
+	sourceMsg, _ := source.Messages().FindByName(sourcePkg.ID, "A")
+	targetMsg, err := target.Messages().FindByName(pkg.ID, "A")
+	if err != nil || targetMsg.LatestVersion.SerializedSchema != sourceMsg.LatestVersion.SerializedSchema {
+		t.Fatalf("Expected identical schemas for message A (%v)", err)
+	}
+
+	// Version 3 left message A unchanged, so it shares A's version from version 2
+	links, err := target.Messages().ListVersionLinks(targetMsg.ID)
+	if err != nil || len(links) != 3 {
+		t.Fatalf("Expected message A in 3 package versions, got %v (%v)", links, err)
+	}
+	if links[2].MessageVersionID != links[1].MessageVersionID || links[2].PackageVersionID == links[1].PackageVersionID {
+		t.Fatalf("Expected versions 2 and 3 to share a version of message A, got %v", links)
+	}
+
+	// New uploads continue the version sequence
+	if next, err := target.PackageVersions().NextVersion(pkg.ID); err != nil || next != 4 {
+		t.Fatalf("Expected next version 4, got %d (%v)", next, err)
+	}
+
+	// Importing again leaves existing versions untouched
+	reimported, err := ImportRegistry(ctx, target, bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to import again: %v", err)
+	}
+	if reimported.Versions != 0 || reimported.SkippedVersions != 3 {
+		t.Fatalf("Unexpected second import: %+v", reimported)
+	}
+
+	// Versions that differ from the archive are rejected
+	conflicting := repo.NewMemoryStore()
+	uploadTestPackage(t, conflicting, "archive.v1", "message Z { string z = 1; }\n")
+	if _, err := ImportRegistry(ctx, conflicting, bytes.NewReader(archive.Bytes())); err == nil {
+		t.Fatal("Expected import over a different version 1 to fail")
+	}
+}
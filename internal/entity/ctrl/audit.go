@@ -0,0 +1,71 @@
+package ctrl
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/auth"
+)
+
+// defaultAuditEventLimit is the number of events listed when a request sets no limit
+const defaultAuditEventLimit = 100
+
+func toAuditEventProto(event *entity.AuditEvent) *v1.AuditEvent {
+	return &v1.AuditEvent{
+		Id:            uint64(event.ID),
+		CreatedAt:     timestamppb.New(event.CreatedAt),
+		Actor:         event.Actor,
+		Action:        event.Action,
+		PackageName:   event.PackageName,
+		Version:       uint64(event.Version),
+		Detail:        event.Detail,
+		Method:        event.Method,
+		ClientAddress: event.ClientAddress,
+		UserAgent:     event.UserAgent,
+		Result:        event.Result,
+		Error:         event.Error,
+	}
+}
+
+// ListAuditEvents lists audit events matching the request's filters, newest first.
+// The caller must be an admin of every package.
+func ListAuditEvents(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.ListAuditEventsRequest) (*v1.ListAuditEventsResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "ListAuditEvents")
+	defer span.End()
+
+	if err := authorizer.AuthorizePattern(ctx, auth.RoleAdmin, "*"); err != nil {
+		return nil, err
+	}
+
+	filter := entity.AuditEventFilter{
+		Actor:       req.Actor,
+		Action:      req.Action,
+		PackageName: req.PackageName,
+		Limit:       int(req.Limit),
+	}
+	if req.Since != nil {
+		filter.Since = req.Since.AsTime()
+	}
+	if req.Until != nil {
+		filter.Until = req.Until.AsTime()
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditEventLimit
+	}
+
+	events, err := store.AuditEvents().List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &v1.ListAuditEventsResponse{}
+	for i := range events {
+		res.Events = append(res.Events, toAuditEventProto(&events[i]))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,262 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/postgres"
+	"github.com/cgund98/voer/internal/infra/sqlite"
+	"github.com/cgund98/voer/internal/proto"
+)
+
+// testDatabaseURLEnv points the backend tests at a PostgreSQL database, e.g. the one in docker-compose.yml
+const testDatabaseURLEnv = "VOER_TEST_DATABASE_URL"
+
+func TestBackends(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		runRegistryScenario(t, repo.NewMemoryStore())
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := sqlite.NewDB(filepath.Join(t.TempDir(), "voer.db"))
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		packageName := runRegistryScenario(t, repo.NewSQLStore(db))
+		checkAuditAppendOnly(t, db, packageName)
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		databaseURL := os.Getenv(testDatabaseURLEnv)
+		if databaseURL == "" {
+			t.Skipf("%s is not set", testDatabaseURLEnv)
+		}
+
+		db, err := postgres.NewDB(databaseURL)
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		packageName := runRegistryScenario(t, repo.NewSQLStore(db))
+		checkAuditAppendOnly(t, db, packageName)
+	})
+}
+
+// checkAuditAppendOnly checks that the database rejects deleting audit events
+func checkAuditAppendOnly(t *testing.T, db *gorm.DB, packageName string) {
+	t.Helper()
+
+	if err := db.Exec("DELETE FROM audit_events WHERE package_name = ?", packageName).Error; err == nil {
+		t.Fatal("Expected deleting audit events to fail")
+	}
+}
+
+// testPackageFile builds a package with a single test.proto file, whose body follows the syntax and package lines
+func testPackageFile(packageName, body string) *v1.PackageFile {
+	return &v1.PackageFile{
+		PackageName: packageName,
+		Files: []*v1.ProtoFile{{
+			FileName:     "test.proto",
+			FileContents: fmt.Sprintf("syntax = \"proto3\";\npackage %s;\n%s", packageName, body),
+		}},
+	}
+}
+
+func uploadTestPackage(t *testing.T, store repo.Store, packageName, body string) *v1.UploadPackageVersionResponse {
+	t.Helper()
+
+	res, err := CreatePackageVersion(context.Background(), store, nil, UploadPolicy{}, &v1.UploadPackageVersionRequest{
+		Packages: []*v1.PackageFile{testPackageFile(packageName, body)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to upload %s: %v", packageName, err)
+	}
+	return res
+}
+
+func countTestMessages(t *testing.T, store repo.Store, packageName string) int64 {
+	t.Helper()
+
+	pkg, err := store.Packages().FindByName(packageName)
+	if err != nil || pkg == nil {
+		t.Fatalf("Failed to find %s: %v", packageName, err)
+	}
+
+	count, err := store.Messages().CountByPackage(pkg.ID)
+	if err != nil {
+		t.Fatalf("Failed to count messages: %v", err)
+	}
+	return count
+}
+
+// runRegistryScenario exercises uploads, deletion, renames and deprecation against a store.
+// Returns the final name of the package it created.
+func runRegistryScenario(t *testing.T, store repo.Store) string {
+	ctx := context.Background()
+
+	// Unique names keep runs against a shared database apart
+	packageName := fmt.Sprintf("backend.t%d", time.Now().UnixNano())
+	newName := packageName + ".v1"
+
+	uploadTestPackage(t, store, packageName, "message A { string x = 1; }\n")
+	res := uploadTestPackage(t, store, packageName, "message A { string x = 1; string y = 2; }\nmessage B { string z = 1; }\n")
+	if len(res.MessageChanges) != 2 {
+		t.Fatalf("Expected 2 message changes, got %d", len(res.MessageChanges))
+	}
+
+	got, err := GetPackageVersion(ctx, store, &v1.GetPackageVersionRequest{PackageName: packageName, Version: 2})
+	if err != nil {
+		t.Fatalf("Failed to get version: %v", err)
+	}
+	if len(got.Files) != 1 || got.PackageName != packageName {
+		t.Fatalf("Unexpected version: %v", got)
+	}
+	if got.Files[0].Digest != proto.DigestFile(got.Files[0].ProtoContents) {
+		t.Fatalf("Unexpected digest %s", got.Files[0].Digest)
+	}
+	if created := res.PackageVersions[0]; created.Id != got.PackageVersion.Id || created.PackageId == created.Id {
+		t.Fatalf("Expected the upload to return version ID %d, got %d", got.PackageVersion.Id, created.Id)
+	}
+
+	// Deleting the latest version hides the message only it contains
+	_, err = DeletePackageVersion(ctx, store, nil, nil, &v1.DeletePackageVersionRequest{PackageName: packageName, Version: 2})
+	if err != nil {
+		t.Fatalf("Failed to delete version: %v", err)
+	}
+	if count := countTestMessages(t, store, packageName); count != 1 {
+		t.Fatalf("Expected 1 visible message after delete, got %d", count)
+	}
+	pkg, err := store.Packages().FindByName(packageName)
+	if err != nil || pkg.LatestVersion == nil || pkg.LatestVersion.Version != 1 {
+		t.Fatalf("Expected latest version 1 after delete, got %v (%v)", pkg, err)
+	}
+
+	_, err = DeletePackageVersion(ctx, store, nil, nil, &v1.DeletePackageVersionRequest{PackageName: packageName, Version: 2, Restore: true})
+	if err != nil {
+		t.Fatalf("Failed to restore version: %v", err)
+	}
+	if count := countTestMessages(t, store, packageName); count != 2 {
+		t.Fatalf("Expected 2 visible messages after restore, got %d", count)
+	}
+
+	// Renamed packages resolve by their former name
+	_, err = RenamePackage(ctx, store, nil, &v1.RenamePackageRequest{PackageName: packageName, NewName: newName})
+	if err != nil {
+		t.Fatalf("Failed to rename package: %v", err)
+	}
+	got, err = GetPackageVersion(ctx, store, &v1.GetPackageVersionRequest{PackageName: packageName, Version: 1})
+	if err != nil {
+		t.Fatalf("Failed to get version by former name: %v", err)
+	}
+	if got.PackageName != newName {
+		t.Fatalf("Expected package name %s, got %s", newName, got.PackageName)
+	}
+
+	_, err = DeprecatePackageVersion(ctx, store, nil, &v1.DeprecatePackageVersionRequest{PackageName: newName, Version: 1, Reason: "test"})
+	if err != nil {
+		t.Fatalf("Failed to deprecate version: %v", err)
+	}
+	got, err = GetPackageVersion(ctx, store, &v1.GetPackageVersionRequest{PackageName: newName, Version: 1})
+	if err != nil || got.PackageVersion.Deprecation.GetReason() != "test" {
+		t.Fatalf("Expected deprecated version, got %v (%v)", got, err)
+	}
+
+	_, err = DeletePackage(ctx, store, nil, nil, &v1.DeletePackageRequest{PackageName: newName})
+	if err != nil {
+		t.Fatalf("Failed to delete package: %v", err)
+	}
+	if _, err := GetPackageVersion(ctx, store, &v1.GetPackageVersionRequest{PackageName: packageName, Version: 1}); err == nil {
+		t.Fatal("Expected deleted package not to resolve")
+	}
+
+	// Messages left unchanged by a later version stay visible when the version introducing them is deleted or purged
+	sharedName := packageName + ".shared"
+	uploadTestPackage(t, store, sharedName, "message A { string x = 1; }\nmessage B { string z = 1; }\n")
+	uploadTestPackage(t, store, sharedName, "message A { string x = 1; string y = 2; }\nmessage B { string z = 1; }\n")
+
+	_, err = DeletePackageVersion(ctx, store, nil, nil, &v1.DeletePackageVersionRequest{PackageName: sharedName, Version: 1})
+	if err != nil {
+		t.Fatalf("Failed to delete version: %v", err)
+	}
+	if count := countTestMessages(t, store, sharedName); count != 2 {
+		t.Fatalf("Expected 2 visible messages after deleting version 1, got %d", count)
+	}
+
+	if _, err := PurgeDeletedPackageVersions(ctx, store, time.Now()); err != nil {
+		t.Fatalf("Failed to purge versions: %v", err)
+	}
+	if count := countTestMessages(t, store, sharedName); count != 2 {
+		t.Fatalf("Expected 2 visible messages after purging version 1, got %d", count)
+	}
+
+	// The kept message is still checked for compatibility
+	_, err = CreatePackageVersion(ctx, store, nil, UploadPolicy{}, &v1.UploadPackageVersionRequest{
+		Packages: []*v1.PackageFile{testPackageFile(sharedName, "message A { string x = 1; string y = 2; }\nmessage B { int64 z = 1; }\n")},
+	})
+	var incompatErr *IncompatibleSchemaError
+	if !errors.As(err, &incompatErr) || len(incompatErr.Violations) != 1 || incompatErr.Violations[0].MessageName != "B" {
+		t.Fatalf("Expected a breaking change to message B, got %v", err)
+	}
+
+	// Every change was recorded
+	events, err := store.AuditEvents().List(entity.AuditEventFilter{PackageName: newName})
+	if err != nil {
+		t.Fatalf("Failed to list audit events: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 audit events for %s, got %d", newName, len(events))
+	}
+
+	checkAPITokens(t, store, packageName)
+
+	return newName
+}
+
+// checkAPITokens issues a token, authenticates with it and revokes it
+func checkAPITokens(t *testing.T, store repo.Store, name string) {
+	t.Helper()
+
+	token, apiToken, err := auth.IssueAPIToken(store, name, nil)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	if _, _, err := auth.IssueAPIToken(store, name, nil); err == nil {
+		t.Fatal("Expected issuing a token with a taken name to fail")
+	}
+
+	authenticator := &auth.TokenAuthenticator{Store: store}
+	principal, err := authenticator.Authenticate(context.Background(), token)
+	if err != nil || principal.Subject != "token:"+name {
+		t.Fatalf("Expected token to authenticate as token:%s, got %v (%v)", name, principal, err)
+	}
+	found, err := store.APITokens().FindByHash(auth.HashToken(token))
+	if err != nil || found == nil || found.ID != apiToken.ID || found.LastUsedAt == nil {
+		t.Fatalf("Expected authenticating to record token usage, got %v (%v)", found, err)
+	}
+
+	tokens, err := store.APITokens().List()
+	if err != nil || !slices.ContainsFunc(tokens, func(token entity.APIToken) bool { return token.Name == name }) {
+		t.Fatalf("Expected token %s to be listed, got %v (%v)", name, tokens, err)
+	}
+
+	if deleted, err := store.APITokens().Delete(name); err != nil || !deleted {
+		t.Fatalf("Failed to revoke token: %v", err)
+	}
+	if deleted, err := store.APITokens().Delete(name); err != nil || deleted {
+		t.Fatalf("Expected revoking a revoked token to report it missing, got %v (%v)", deleted, err)
+	}
+	if _, err := authenticator.Authenticate(context.Background(), token); err == nil {
+		t.Fatal("Expected a revoked token not to authenticate")
+	}
+}
@@ -0,0 +1,158 @@
+package ctrl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/events"
+)
+
+// findPackageVersion fetches a version of a package by package name and version number
+func findPackageVersion(store repo.Store, packageName string, version uint64) (*entity.PackageVersion, error) {
+	pkg, err := store.Packages().FindByName(packageName)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		return nil, notFound(ResourcePackage, packageName)
+	}
+
+	pkgVer, err := store.PackageVersions().Find(pkg.ID, int(version))
+	if err != nil {
+		return nil, err
+	}
+	if pkgVer == nil {
+		return nil, notFound(ResourcePackageVersion, packageVersionName(packageName, version))
+	}
+
+	pkgVer.Package = *pkg
+	return pkgVer, nil
+}
+
+// DeletePackageVersion soft deletes a package version, or restores a deleted one. The latest versions of the package
+// and its messages are recomputed in the same transaction, and messages that no remaining version contains are hidden.
+// The caller must be an admin of the package. Attempts are recorded in the audit log, and changes are published.
+func DeletePackageVersion(ctx context.Context, store repo.Store, bus *events.Bus, authorizer *auth.Authorizer, req *v1.DeletePackageVersionRequest) (*v1.DeletePackageVersionResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "DeletePackageVersion")
+	defer span.End()
+
+	action, eventType := audit.ActionDeleteVersion, events.TypePackageVersionDeleted
+	if req.Restore {
+		action, eventType = audit.ActionRestoreVersion, events.TypePackageVersionRestored
+	}
+	event := audit.Event{Action: action, PackageName: req.PackageName, Version: uint(req.Version)}
+
+	res, err := deletePackageVersion(ctx, store, authorizer, req, event)
+	if err != nil {
+		audit.Record(ctx, store.AuditEvents(), event, err)
+		return nil, err
+	}
+
+	bus.Publish(ctx, events.Event{Type: eventType, PackageName: req.PackageName, Version: uint(req.Version)})
+	return res, nil
+}
+
+func deletePackageVersion(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.DeletePackageVersionRequest, event audit.Event) (*v1.DeletePackageVersionResponse, error) {
+	if err := authorizer.Authorize(ctx, auth.RoleAdmin, req.PackageName); err != nil {
+		return nil, err
+	}
+
+	var pkgVer *entity.PackageVersion
+	err := store.Transaction(func(tx repo.Store) error {
+		// Read the version in the transaction, so concurrent requests cannot both pass the checks below
+		var err error
+		pkgVer, err = findPackageVersion(tx, req.PackageName, req.Version)
+		if err != nil {
+			return err
+		}
+
+		if req.Restore {
+			if pkgVer.DeletedAt == nil {
+				return failedPrecondition(ReasonVersionNotDeleted, packageVersionName(req.PackageName, req.Version),
+					"version %d of %s is not deleted", pkgVer.Version, req.PackageName)
+			}
+			if err := tx.PackageVersions().Restore(pkgVer.ID); err != nil {
+				return err
+			}
+			pkgVer.DeletedAt = nil
+		} else {
+			if pkgVer.DeletedAt != nil {
+				return failedPrecondition(ReasonVersionDeleted, packageVersionName(req.PackageName, req.Version),
+					"version %d of %s is already deleted", pkgVer.Version, req.PackageName)
+			}
+			now := time.Now()
+			if err := tx.PackageVersions().SoftDelete(pkgVer.ID, now); err != nil {
+				return err
+			}
+			pkgVer.DeletedAt = &now
+		}
+
+		return assignLatestVersions(ctx, tx, pkgVer, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.DeletePackageVersionResponse{PackageVersion: toPackageVersionProto(pkgVer)}, nil
+}
+
+// assignLatestVersions recomputes the latest versions of a changed version's package and messages, then records the
+// change in the audit log of the transaction
+func assignLatestVersions(ctx context.Context, tx repo.Store, pkgVer *entity.PackageVersion, event audit.Event) error {
+	if err := tx.Packages().AssignLatestVersion(pkgVer.PackageID); err != nil {
+		return err
+	}
+
+	if err := tx.Messages().AssignLatestVersions(pkgVer.PackageID); err != nil {
+		return fmt.Errorf("failed to update messages: %w", err)
+	}
+
+	return audit.RecordSuccess(ctx, tx.AuditEvents(), event)
+}
+
+// PurgeDeletedPackageVersions permanently deletes package versions that were soft deleted before a given time,
+// along with messages that no other package version contains. Each purge is recorded in the audit log.
+// Returns the number of purged versions.
+func PurgeDeletedPackageVersions(ctx context.Context, store repo.Store, deletedBefore time.Time) (int, error) {
+	ctx, store, span := startSpan(ctx, store, "PurgeDeletedPackageVersions")
+	defer span.End()
+
+	pkgVersions, err := store.PackageVersions().ListPurgeable(deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, pkgVer := range pkgVersions {
+		event := audit.Event{
+			Actor:       audit.SystemActor,
+			Action:      audit.ActionPurgeVersion,
+			PackageName: pkgVer.Package.PackageName,
+			Version:     uint(pkgVer.Version),
+		}
+
+		err := store.Transaction(func(tx repo.Store) error {
+			// Latest versions never point at deleted versions, so they are unaffected
+			if err := tx.PackageVersions().Purge(pkgVer.ID); err != nil {
+				return err
+			}
+			if err := tx.Messages().DeleteOrphaned(pkgVer.PackageID); err != nil {
+				return err
+			}
+			return audit.RecordSuccess(ctx, tx.AuditEvents(), event)
+		})
+		if err != nil {
+			audit.Record(ctx, store.AuditEvents(), event, err)
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
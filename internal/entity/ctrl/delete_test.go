@@ -0,0 +1,71 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+)
+
+// barrierStore holds transactions back until every caller has started one, so reads made before opening a
+// transaction all see the store as it was
+type barrierStore struct {
+	repo.Store
+	arrived *sync.WaitGroup
+}
+
+func (s *barrierStore) Transaction(fn func(tx repo.Store) error) error {
+	s.arrived.Done()
+	s.arrived.Wait()
+	return s.Store.Transaction(fn)
+}
+
+func (s *barrierStore) WithContext(ctx context.Context) repo.Store {
+	return s
+}
+
+func TestConcurrentDeletePackageVersion(t *testing.T) {
+	memoryStore := repo.NewMemoryStore()
+	uploadTestPackage(t, memoryStore, "concurrent.v1", "message A { string x = 1; }\n")
+
+	const attempts = 8
+	arrived := &sync.WaitGroup{}
+	arrived.Add(attempts)
+	store := &barrierStore{Store: memoryStore, arrived: arrived}
+
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = DeletePackageVersion(context.Background(), store, nil, nil, &v1.DeletePackageVersionRequest{PackageName: "concurrent.v1", Version: 1})
+		}()
+	}
+	wg.Wait()
+
+	// Exactly one request deletes the version, the others find it already deleted
+	deleted := 0
+	for _, err := range errs {
+		var preconditionErr *FailedPreconditionError
+		switch {
+		case err == nil:
+			deleted++
+		case !errors.As(err, &preconditionErr) || preconditionErr.Reason != ReasonVersionDeleted:
+			t.Fatalf("Expected the version to be already deleted, got %v", err)
+		}
+	}
+	if deleted != 1 {
+		t.Fatalf("Expected 1 request to delete the version, got %d", deleted)
+	}
+
+	events, err := store.AuditEvents().List(entity.AuditEventFilter{Action: audit.ActionDeleteVersion, Result: audit.ResultSuccess})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Expected 1 recorded deletion, got %v (%v)", events, err)
+	}
+}
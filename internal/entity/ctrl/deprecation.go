@@ -0,0 +1,259 @@
+package ctrl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/protocompile/linker"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/proto"
+)
+
+// toDeprecationProto converts a deprecation into its API representation. Returns nil when not deprecated.
+func toDeprecationProto(deprecation entity.Deprecation) *v1.Deprecation {
+	if !deprecation.IsDeprecated() {
+		return nil
+	}
+
+	return &v1.Deprecation{
+		DeprecatedAt: timestamppb.New(*deprecation.DeprecatedAt),
+		Reason:       deprecation.DeprecationReason,
+		Replacement:  deprecation.DeprecationReplacement,
+	}
+}
+
+// newDeprecation builds the deprecation to store for a request. Returns nil when the deprecation is cleared.
+func newDeprecation(reason, replacement string, undeprecate bool) (*entity.Deprecation, error) {
+	if undeprecate {
+		return nil, nil
+	}
+
+	if strings.TrimSpace(reason) == "" {
+		return nil, invalidArgument("reason", "a deprecation reason is required")
+	}
+
+	now := time.Now()
+	return &entity.Deprecation{
+		DeprecatedAt:           &now,
+		DeprecationReason:      reason,
+		DeprecationReplacement: replacement,
+	}, nil
+}
+
+// deprecationEvent builds the audit event for a deprecation change
+func deprecationEvent(packageName string, version uint64, reason string, undeprecate bool) audit.Event {
+	if undeprecate {
+		return audit.Event{Action: audit.ActionUndeprecate, PackageName: packageName, Version: uint(version)}
+	}
+	return audit.Event{Action: audit.ActionDeprecate, PackageName: packageName, Version: uint(version), Detail: reason}
+}
+
+// DeprecatePackageVersion deprecates a package version, or clears its deprecation.
+// The caller must be a publisher of the package. Attempts are recorded in the audit log.
+func DeprecatePackageVersion(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.DeprecatePackageVersionRequest) (*v1.DeprecatePackageVersionResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "DeprecatePackageVersion")
+	defer span.End()
+
+	event := deprecationEvent(req.PackageName, req.Version, req.Reason, req.Undeprecate)
+	res, err := deprecatePackageVersion(ctx, store, authorizer, req, event)
+	if err != nil {
+		audit.Record(ctx, store.AuditEvents(), event, err)
+	}
+	return res, err
+}
+
+func deprecatePackageVersion(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.DeprecatePackageVersionRequest, event audit.Event) (*v1.DeprecatePackageVersionResponse, error) {
+	if err := authorizer.Authorize(ctx, auth.RolePublisher, req.PackageName); err != nil {
+		return nil, err
+	}
+
+	deprecation, err := newDeprecation(req.Reason, req.Replacement, req.Undeprecate)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgVer, err := findPackageVersion(store, req.PackageName, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.Transaction(func(tx repo.Store) error {
+		if err := tx.PackageVersions().SetDeprecation(pkgVer.ID, deprecation); err != nil {
+			return err
+		}
+		return audit.RecordSuccess(ctx, tx.AuditEvents(), event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if deprecation != nil {
+		pkgVer.Deprecation = *deprecation
+	} else {
+		pkgVer.Deprecation = entity.Deprecation{}
+	}
+
+	return &v1.DeprecatePackageVersionResponse{PackageVersion: toPackageVersionProto(pkgVer)}, nil
+}
+
+// DeprecateMessage deprecates a message across all versions of its package, or clears its deprecation.
+// The caller must be a publisher of the package. Attempts are recorded in the audit log.
+func DeprecateMessage(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.DeprecateMessageRequest) (*v1.DeprecateMessageResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "DeprecateMessage")
+	defer span.End()
+
+	event := deprecationEvent(req.PackageName, 0, req.Reason, req.Undeprecate)
+	event.Detail = strings.TrimSpace(fmt.Sprintf("message %s %s", req.MessageName, event.Detail))
+
+	res, err := deprecateMessage(ctx, store, authorizer, req, event)
+	if err != nil {
+		audit.Record(ctx, store.AuditEvents(), event, err)
+	}
+
+	return res, err
+}
+
+func deprecateMessage(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.DeprecateMessageRequest, event audit.Event) (*v1.DeprecateMessageResponse, error) {
+	if err := authorizer.Authorize(ctx, auth.RolePublisher, req.PackageName); err != nil {
+		return nil, err
+	}
+
+	deprecation, err := newDeprecation(req.Reason, req.Replacement, req.Undeprecate)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := store.Packages().FindByName(req.PackageName)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		return nil, notFound(ResourcePackage, req.PackageName)
+	}
+
+	message, err := store.Messages().FindByName(pkg.ID, req.MessageName)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, notFound(ResourceMessage, req.MessageName)
+	}
+
+	err = store.Transaction(func(tx repo.Store) error {
+		if err := tx.Messages().SetDeprecation(message.ID, deprecation); err != nil {
+			return err
+		}
+		return audit.RecordSuccess(ctx, tx.AuditEvents(), event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &v1.DeprecateMessageResponse{}
+	if deprecation != nil {
+		res.Message = &v1.DeprecatedMessage{Name: message.Name, Deprecation: toDeprecationProto(*deprecation)}
+	}
+
+	return res, nil
+}
+
+// listDeprecatedMessages lists the deprecated messages of a package in their API representation
+func listDeprecatedMessages(store repo.Store, packageID uint) ([]*v1.DeprecatedMessage, error) {
+	messages, err := store.Messages().ListDeprecated(packageID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*v1.DeprecatedMessage, 0, len(messages))
+	for _, message := range messages {
+		results = append(results, &v1.DeprecatedMessage{Name: message.Name, Deprecation: toDeprecationProto(message.Deprecation)})
+	}
+
+	return results, nil
+}
+
+// latestImports returns the imports of the files in a package's latest version
+func latestImports(store repo.Store, packageName string) (map[string]bool, error) {
+	imports := make(map[string]bool)
+
+	pkg, err := store.Packages().FindByName(packageName)
+	if err != nil || pkg == nil || pkg.LatestVersionID == nil {
+		return imports, err
+	}
+
+	files, err := store.Files().List(*pkg.LatestVersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		fileImports, err := proto.ParseImports(file.FileName, file.FileContents)
+		if err != nil {
+			return nil, err
+		}
+		for _, importPath := range fileImports {
+			imports[importPath] = true
+		}
+	}
+
+	return imports, nil
+}
+
+// checkDeprecatedImports rejects imports of files from deprecated package versions in the registry.
+// Imports already present in the package's latest version are allowed, so only new dependencies are blocked.
+func checkDeprecatedImports(store repo.Store, packageName string, reqPkgs []*v1.PackageFile, protoFiles linker.Files) error {
+	reqFiles := make(map[string]bool)
+	for _, reqPkg := range reqPkgs {
+		for _, file := range reqPkg.Files {
+			reqFiles[importPathOf(file)] = true
+		}
+	}
+
+	var existingImports map[string]bool
+	for _, protoFile := range protoFiles {
+		imports := protoFile.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			importPath := imports.Get(i).Path()
+			if reqFiles[importPath] || proto.IsStandardImport(importPath) {
+				continue
+			}
+
+			file, err := store.Files().FindLatestByImportPath(importPath)
+			if err != nil {
+				return err
+			}
+			if file == nil || !file.PackageVersion.IsDeprecated() {
+				continue
+			}
+
+			// Only load the latest version's imports when a deprecated import is found
+			if existingImports == nil {
+				existingImports, err = latestImports(store, packageName)
+				if err != nil {
+					return err
+				}
+			}
+			if existingImports[importPath] {
+				continue
+			}
+
+			pkgVer := file.PackageVersion
+			msg := fmt.Sprintf("import %s resolves to deprecated version %d of %s: %s",
+				importPath, pkgVer.Version, pkgVer.Package.PackageName, pkgVer.DeprecationReason)
+			if pkgVer.DeprecationReplacement != "" {
+				msg += fmt.Sprintf(" (use %s instead)", pkgVer.DeprecationReplacement)
+			}
+			return &FailedPreconditionError{Reason: ReasonDeprecatedImport, Subject: importPath, Description: msg}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package ctrl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/protocompile/reporter"
+)
+
+// Resources reported by NotFoundError and AlreadyExistsError
+const (
+	ResourcePackage        = "package"
+	ResourcePackageVersion = "package version"
+	ResourceMessage        = "message"
+	ResourceImport         = "import"
+	ResourceRoleGrant      = "role grant"
+)
+
+// Reasons a request fails a precondition
+const (
+	ReasonPackageRenamed    = "PACKAGE_RENAMED"
+	ReasonVersionDeleted    = "VERSION_DELETED"
+	ReasonVersionNotDeleted = "VERSION_NOT_DELETED"
+	ReasonDeprecatedImport  = "DEPRECATED_IMPORT"
+)
+
+// NotFoundError is returned when a resource named in a request does not exist
+type NotFoundError struct {
+	Resource string
+	Name     string
+
+	// Explains why the resource is not found, e.g. that it was deleted. Optional.
+	Err error
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s %s not found", e.Resource, e.Name)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// notFound returns a NotFoundError for a resource
+func notFound(resource, name string) error {
+	return &NotFoundError{Resource: resource, Name: name}
+}
+
+// packageVersionName names a version of a package in errors, e.g. foo.v1@2
+func packageVersionName(packageName string, version uint64) string {
+	return fmt.Sprintf("%s@%d", packageName, version)
+}
+
+// AlreadyExistsError is returned when a request would create a resource that already exists
+type AlreadyExistsError struct {
+	Resource string
+	Name     string
+
+	// Explains what the resource already belongs to. Optional.
+	Err error
+}
+
+func (e *AlreadyExistsError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s %s already exists", e.Resource, e.Name)
+}
+
+func (e *AlreadyExistsError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidArgumentError is returned when a field of a request is missing or malformed
+type InvalidArgumentError struct {
+	Field       string
+	Description string
+}
+
+func (e *InvalidArgumentError) Error() string {
+	return e.Description
+}
+
+// invalidArgument returns an InvalidArgumentError for a field
+func invalidArgument(field, format string, args ...any) error {
+	return &InvalidArgumentError{Field: field, Description: fmt.Sprintf(format, args...)}
+}
+
+// FailedPreconditionError is returned when a request is well formed, but the registry is not in a state that allows it
+type FailedPreconditionError struct {
+	// One of the Reason constants
+	Reason string
+
+	// The resource that is not in the required state, e.g. a package name
+	Subject     string
+	Description string
+}
+
+func (e *FailedPreconditionError) Error() string {
+	return e.Description
+}
+
+// failedPrecondition returns a FailedPreconditionError for a subject
+func failedPrecondition(reason, subject, format string, args ...any) error {
+	return &FailedPreconditionError{Reason: reason, Subject: subject, Description: fmt.Sprintf(format, args...)}
+}
+
+// SchemaViolation is a change to a message that breaks backwards compatibility
+type SchemaViolation struct {
+	MessageName string
+
+	// One of the proto.Rule constants
+	Rule        string
+	Description string
+}
+
+func (v SchemaViolation) String() string {
+	return fmt.Sprintf("%s: [%s] %s", v.MessageName, v.Rule, v.Description)
+}
+
+// IncompatibleSchemaError is returned when a package version is not backwards compatible with the latest version.
+// It lists every violation found in the package.
+type IncompatibleSchemaError struct {
+	PackageName string
+	Violations  []SchemaViolation
+}
+
+func (e *IncompatibleSchemaError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		msgs = append(msgs, violation.String())
+	}
+	return fmt.Sprintf("package %s has %d backwards incompatible change(s):\n%s", e.PackageName, len(e.Violations), strings.Join(msgs, "\n"))
+}
+
+// ProtoViolation is a problem with the proto files of a package, e.g. a syntax error or a lint violation
+type ProtoViolation struct {
+	// File and line of the problem. Both are optional.
+	FileName string
+	Line     int
+
+	// Lint rule that was violated. Empty for compilation errors.
+	Rule        string
+	Description string
+}
+
+func (v ProtoViolation) String() string {
+	var b strings.Builder
+	if v.FileName != "" {
+		b.WriteString(v.FileName)
+		if v.Line > 0 {
+			fmt.Fprintf(&b, ":%d", v.Line)
+		}
+		b.WriteString(": ")
+	}
+	if v.Rule != "" {
+		fmt.Fprintf(&b, "[%s] ", v.Rule)
+	}
+	b.WriteString(v.Description)
+	return b.String()
+}
+
+// InvalidProtoError is returned when the proto files of a package do not compile or do not pass lint rules
+type InvalidProtoError struct {
+	PackageName string
+	Violations  []ProtoViolation
+}
+
+func (e *InvalidProtoError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		msgs = append(msgs, violation.String())
+	}
+	return fmt.Sprintf("package %s has %d invalid proto definition(s):\n%s", e.PackageName, len(e.Violations), strings.Join(msgs, "\n"))
+}
+
+// invalidProto converts an error compiling the files of a package into an InvalidProtoError, keeping the position of
+// compilation errors
+func invalidProto(packageName string, err error) error {
+	violation := ProtoViolation{Description: err.Error()}
+
+	var posErr reporter.ErrorWithPos
+	if errors.As(err, &posErr) {
+		pos := posErr.GetPosition()
+		violation = ProtoViolation{FileName: pos.Filename, Line: pos.Line, Description: posErr.Unwrap().Error()}
+	}
+
+	return &InvalidProtoError{PackageName: packageName, Violations: []ProtoViolation{violation}}
+}
@@ -0,0 +1,59 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/proto"
+)
+
+func TestDomainErrors(t *testing.T) {
+	ctx := context.Background()
+	store := repo.NewMemoryStore()
+
+	uploadTestPackage(t, store, "errors", "message A { string x = 1; string y = 2; }\nmessage B { string z = 1; }\n")
+
+	upload := func(contents string) error {
+		_, err := CreatePackageVersion(ctx, store, nil, UploadPolicy{}, &v1.UploadPackageVersionRequest{
+			Packages: []*v1.PackageFile{{
+				PackageName: "errors",
+				Files:       []*v1.ProtoFile{{FileName: "test.proto", FileContents: contents}},
+			}},
+		})
+		return err
+	}
+
+	// Every incompatible change of the package is reported
+	err := upload("syntax = \"proto3\";\npackage errors;\nmessage A { string x = 1; }\n")
+	var incompatErr *IncompatibleSchemaError
+	if !errors.As(err, &incompatErr) {
+		t.Fatalf("Expected an IncompatibleSchemaError, got %v", err)
+	}
+	rules := make(map[string]string)
+	for _, violation := range incompatErr.Violations {
+		rules[violation.MessageName] = violation.Rule
+	}
+	if len(rules) != 2 || rules["A"] != proto.RuleFieldRemoved || rules["B"] != proto.RuleMessageRemoved {
+		t.Fatalf("Expected a removed field and a removed message, got %v", incompatErr.Violations)
+	}
+
+	// Compilation errors keep their position
+	err = upload("syntax = \"proto3\";\npackage errors;\nmessage A { strin x = 1; }\n")
+	var invalidErr *InvalidProtoError
+	if !errors.As(err, &invalidErr) {
+		t.Fatalf("Expected an InvalidProtoError, got %v", err)
+	}
+	if violation := invalidErr.Violations[0]; violation.FileName != "test.proto" || violation.Line != 3 {
+		t.Fatalf("Expected the violation to be at test.proto:3, got %s", violation)
+	}
+
+	// Missing resources are named
+	_, err = GetPackageVersion(ctx, store, &v1.GetPackageVersionRequest{PackageName: "errors", Version: 7})
+	var notFoundErr *NotFoundError
+	if !errors.As(err, &notFoundErr) || notFoundErr.Resource != ResourcePackageVersion || notFoundErr.Name != "errors@7" {
+		t.Fatalf("Expected version 7 to be not found, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package ctrl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/metrics"
+	"github.com/cgund98/voer/internal/proto"
+)
+
+func TestUploadMetrics(t *testing.T) {
+	ctx := context.Background()
+	store := repo.NewMemoryStore()
+
+	// Counters are shared by every test, so only their increments are checked
+	created := testutil.ToFloat64(metrics.Uploads.WithLabelValues(metrics.ResultCreated))
+	unchanged := testutil.ToFloat64(metrics.Uploads.WithLabelValues(metrics.ResultUnchanged))
+	incompatible := testutil.ToFloat64(metrics.Uploads.WithLabelValues(metrics.ResultIncompatible))
+	invalid := testutil.ToFloat64(metrics.Validations.WithLabelValues(metrics.ResultIncompatible))
+	removed := testutil.ToFloat64(metrics.CompatibilityViolations.WithLabelValues(proto.RuleFieldRemoved))
+
+	uploadTestPackage(t, store, "metrics", "message A { string x = 1; string y = 2; }\n")
+	uploadTestPackage(t, store, "metrics", "message A { string x = 1; string y = 2; }\n")
+
+	files := []*v1.PackageFile{{
+		PackageName: "metrics",
+		Files: []*v1.ProtoFile{{
+			FileName:     "test.proto",
+			FileContents: "syntax = \"proto3\";\npackage metrics;\nmessage A { string x = 1; }\n",
+		}},
+	}}
+	if _, err := CreatePackageVersion(ctx, store, nil, UploadPolicy{}, &v1.UploadPackageVersionRequest{Packages: files}); err == nil {
+		t.Fatal("Expected removing a field to be rejected")
+	}
+	if res, err := ValidatePackageVersion(ctx, store, UploadPolicy{}, &v1.ValidatePackageVersionRequest{Packages: files}); err != nil || res.IsValid {
+		t.Fatalf("Expected removing a field to be invalid, got %v (%v)", res, err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		before   float64
+		after    float64
+		expected float64
+	}{
+		{"created uploads", created, testutil.ToFloat64(metrics.Uploads.WithLabelValues(metrics.ResultCreated)), 1},
+		{"unchanged uploads", unchanged, testutil.ToFloat64(metrics.Uploads.WithLabelValues(metrics.ResultUnchanged)), 1},
+		{"incompatible uploads", incompatible, testutil.ToFloat64(metrics.Uploads.WithLabelValues(metrics.ResultIncompatible)), 1},
+		{"incompatible validations", invalid, testutil.ToFloat64(metrics.Validations.WithLabelValues(metrics.ResultIncompatible)), 1},
+		{"removed fields", removed, testutil.ToFloat64(metrics.CompatibilityViolations.WithLabelValues(proto.RuleFieldRemoved)), 2},
+	} {
+		if tt.after-tt.before != tt.expected {
+			t.Errorf("Expected %v more %s, got %v", tt.expected, tt.name, tt.after-tt.before)
+		}
+	}
+}
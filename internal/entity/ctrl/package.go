@@ -0,0 +1,201 @@
+package ctrl
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/events"
+)
+
+// resolvePackage fetches a package by its current name or one of its former names.
+// Returns nil if no package is known by the given name.
+func resolvePackage(store repo.Store, packageName string) (*entity.Package, error) {
+	pkg, err := store.Packages().FindByName(packageName)
+	if err != nil || pkg != nil {
+		return pkg, err
+	}
+
+	alias, err := store.Packages().FindAlias(packageName)
+	if err != nil || alias == nil {
+		return nil, err
+	}
+
+	return &alias.Package, nil
+}
+
+// ResolvePackageName returns the current name of a package, following renames.
+// Unknown names are returned unchanged.
+func ResolvePackageName(store repo.Store, packageName string) (string, error) {
+	pkg, err := resolvePackage(store, packageName)
+	if err != nil || pkg == nil {
+		return packageName, err
+	}
+
+	return pkg.PackageName, nil
+}
+
+// checkNotRenamed rejects a package name that is the former name of a renamed package
+func checkNotRenamed(store repo.Store, packageName string) error {
+	alias, err := store.Packages().FindAlias(packageName)
+	if err != nil {
+		return err
+	}
+	if alias != nil {
+		return failedPrecondition(ReasonPackageRenamed, packageName, "package %s was renamed to %s, update the package declaration", packageName, alias.Package.PackageName)
+	}
+
+	return nil
+}
+
+// DeletePackage permanently deletes a package with all of its versions, messages and former names.
+// The caller must be an admin of the package. Attempts are recorded in the audit log, and the deletion of every
+// version that was not already deleted is published.
+func DeletePackage(ctx context.Context, store repo.Store, bus *events.Bus, authorizer *auth.Authorizer, req *v1.DeletePackageRequest) (*v1.DeletePackageResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "DeletePackage")
+	defer span.End()
+
+	event := audit.Event{Action: audit.ActionDeletePackage, PackageName: req.PackageName}
+	res, err := deletePackage(ctx, store, bus, authorizer, req, event)
+	if err != nil {
+		audit.Record(ctx, store.AuditEvents(), event, err)
+	}
+	return res, err
+}
+
+func deletePackage(ctx context.Context, store repo.Store, bus *events.Bus, authorizer *auth.Authorizer, req *v1.DeletePackageRequest, event audit.Event) (*v1.DeletePackageResponse, error) {
+	if err := authorizer.Authorize(ctx, auth.RoleAdmin, req.PackageName); err != nil {
+		return nil, err
+	}
+
+	pkg, err := store.Packages().FindByName(req.PackageName)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		return nil, notFound(ResourcePackage, req.PackageName)
+	}
+
+	var pkgVersions []entity.PackageVersion
+	err = store.Transaction(func(tx repo.Store) error {
+		pkgVersions, err = tx.PackageVersions().List(pkg.ID, false)
+		if err != nil {
+			return err
+		}
+		if err := tx.Packages().Delete(pkg.ID); err != nil {
+			return err
+		}
+		return audit.RecordSuccess(ctx, tx.AuditEvents(), event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkgVersion := range pkgVersions {
+		bus.Publish(ctx, events.Event{Type: events.TypePackageVersionDeleted, PackageName: pkg.PackageName, Version: uint(pkgVersion.Version)})
+	}
+
+	return &v1.DeletePackageResponse{}, nil
+}
+
+// RenamePackage renames a package, keeping its former name as an alias so it still resolves to the package.
+// Renaming a package back to one of its former names removes that alias.
+// The caller must be an admin of both names. Attempts are recorded in the audit log.
+func RenamePackage(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.RenamePackageRequest) (*v1.RenamePackageResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "RenamePackage")
+	defer span.End()
+
+	event := audit.Event{
+		Action:      audit.ActionRenamePackage,
+		PackageName: req.PackageName,
+		Detail:      fmt.Sprintf("renamed to %s", req.NewName),
+	}
+
+	res, err := renamePackage(ctx, store, authorizer, req, event)
+	if err != nil {
+		audit.Record(ctx, store.AuditEvents(), event, err)
+	}
+	return res, err
+}
+
+func renamePackage(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.RenamePackageRequest, event audit.Event) (*v1.RenamePackageResponse, error) {
+	if err := authorizer.Authorize(ctx, auth.RoleAdmin, req.PackageName); err != nil {
+		return nil, err
+	}
+	if err := authorizer.Authorize(ctx, auth.RoleAdmin, req.NewName); err != nil {
+		return nil, err
+	}
+
+	if !protoreflect.FullName(req.NewName).IsValid() {
+		return nil, invalidArgument("new_name", "invalid package name: %q", req.NewName)
+	}
+
+	pkg, err := store.Packages().FindByName(req.PackageName)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		return nil, notFound(ResourcePackage, req.PackageName)
+	}
+
+	// The new name must not belong to another package
+	existing, err := resolvePackage(store, req.NewName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != pkg.ID {
+		return nil, &AlreadyExistsError{
+			Resource: ResourcePackage,
+			Name:     req.NewName,
+			Err:      fmt.Errorf("package name %s is already used by %s", req.NewName, existing.PackageName),
+		}
+	}
+	if existing != nil && existing.PackageName == req.NewName {
+		return nil, invalidArgument("new_name", "package is already named %s", req.NewName)
+	}
+
+	err = store.Transaction(func(tx repo.Store) error {
+		// Reclaim the new name if it is one of the package's former names
+		if err := tx.Packages().DeleteAlias(req.NewName); err != nil {
+			return err
+		}
+
+		if err := tx.Packages().CreateAlias(pkg.PackageName, pkg.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Packages().Rename(pkg.ID, req.NewName); err != nil {
+			return err
+		}
+		return audit.RecordSuccess(ctx, tx.AuditEvents(), event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	aliases, err := store.Packages().ListAliases(pkg.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &v1.RenamePackageResponse{
+		Package: &v1.Package{
+			Id:        uint64(pkg.ID),
+			CreatedAt: timestamppb.New(pkg.CreatedAt),
+			UpdatedAt: timestamppb.New(pkg.UpdatedAt),
+			Name:      req.NewName,
+		},
+	}
+	for _, alias := range aliases {
+		res.Aliases = append(res.Aliases, alias.AliasName)
+	}
+
+	return res, nil
+}
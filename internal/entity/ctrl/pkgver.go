@@ -2,35 +2,57 @@ package ctrl
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"path/filepath"
+	"time"
 
 	"github.com/bufbuild/protocompile/linker"
 	v1 "github.com/cgund98/voer/api/v1"
 	entity "github.com/cgund98/voer/internal/entity/db"
-	"github.com/cgund98/voer/internal/infra/sqlite"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/infra/events"
+	"github.com/cgund98/voer/internal/infra/metrics"
 	"github.com/cgund98/voer/internal/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
+)
 
-	"gorm.io/gorm"
+const (
+	changeTypeNew     = "new"
+	changeTypeChanged = "changed"
 )
 
+// errDryRun is returned from within a transaction to force a rollback
+var errDryRun = errors.New("dry run")
+
+// UploadPolicy holds the server-side rules enforced on uploads and validations
+type UploadPolicy struct {
+	// Lint rules. Linting is disabled when nil.
+	LintConfig *proto.LintConfig
+
+	// Reject uploads that add an import of a deprecated package version
+	BlockDeprecatedImports bool
+}
+
+// ErrPackageVersionDeleted is wrapped in the NotFoundError returned when fetching a version that was soft deleted
+var ErrPackageVersionDeleted = errors.New("package version deleted")
+
 // checkBackwardsCompatible checks if a message is backwards compatible with the latest version of the message.
-func checkBackwardsCompatible(ctx context.Context, db *gorm.DB, packageID uint, parsedMsg proto.ParsedMessage) error {
+func checkBackwardsCompatible(ctx context.Context, store repo.Store, packageID uint, parsedMsg proto.ParsedMessage) error {
 
 	// Check if message exists
-	results := []entity.Message{}
-	err := db.Model(&entity.Message{}).Preload("LatestVersion").Where("package_id = ? AND name = ?", packageID, parsedMsg.Name).Limit(1).Find(&results).Error
+	msg, err := store.Messages().FindByName(packageID, parsedMsg.Name)
 	if err != nil {
 		return fmt.Errorf("failed to check if message exists: %w", err)
 	}
 
 	// Check if message version exists
-	if len(results) == 0 || results[0].LatestVersion == nil {
+	if msg == nil || msg.LatestVersion == nil {
 		return nil
 	}
 
-	msg := results[0]
 	msgVersion := msg.LatestVersion
 
 	// Parse schema
@@ -41,6 +63,10 @@ func checkBackwardsCompatible(ctx context.Context, db *gorm.DB, packageID uint,
 
 	// Check if message version is backwards compatible
 	err = proto.ValidateBackwardsCompatibleMessage(ctx, msgSchema, parsedMsg)
+	var compatErr *proto.CompatibilityError
+	if errors.As(err, &compatErr) {
+		metrics.CompatibilityViolations.WithLabelValues(compatErr.Rule).Inc()
+	}
 	if err != nil {
 		return fmt.Errorf("failed to validate backwards compatible message %s: %w", parsedMsg.Name, err)
 	}
@@ -48,9 +74,90 @@ func checkBackwardsCompatible(ctx context.Context, db *gorm.DB, packageID uint,
 	return nil
 }
 
+// importPathOf returns the path a file is compiled under, defaulting to its file name
+func importPathOf(file *v1.ProtoFile) string {
+	if file.ImportPath != "" {
+		return file.ImportPath
+	}
+	return file.FileName
+}
+
+// newImportResolver resolves imports against the other packages in a request, then the latest
+// versions of all registered packages.
+func newImportResolver(store repo.Store, reqPkgs []*v1.PackageFile) proto.ImportResolver {
+	reqFiles := make(map[string]string)
+	for _, reqPkg := range reqPkgs {
+		for _, file := range reqPkg.Files {
+			reqFiles[importPathOf(file)] = file.FileContents
+		}
+	}
+
+	return func(importPath string) (string, error) {
+		if contents, ok := reqFiles[importPath]; ok {
+			return contents, nil
+		}
+
+		file, err := store.Files().FindLatestByImportPath(importPath)
+		if err != nil {
+			return "", err
+		}
+		if file == nil {
+			return "", fmt.Errorf("import %s not found in registry: %w", importPath, fs.ErrNotExist)
+		}
+
+		return file.FileContents, nil
+	}
+}
+
+// toLintViolationProtos converts lint violations for a package into their API representation
+func toLintViolationProtos(packageName string, violations []proto.LintViolation) []*v1.LintViolation {
+	results := make([]*v1.LintViolation, 0, len(violations))
+	for _, violation := range violations {
+		results = append(results, &v1.LintViolation{
+			PackageName: packageName,
+			Rule:        violation.Rule,
+			FileName:    violation.FileName,
+			Line:        uint32(violation.Line),
+			Element:     violation.Element,
+			Message:     violation.Message,
+		})
+	}
+	return results
+}
+
+// lintError reports the lint violations of a package as an InvalidProtoError
+func lintError(packageName string, violations []proto.LintViolation) error {
+	protoViolations := make([]ProtoViolation, 0, len(violations))
+	for _, violation := range violations {
+		protoViolations = append(protoViolations, ProtoViolation{
+			FileName:    violation.FileName,
+			Line:        violation.Line,
+			Rule:        violation.Rule,
+			Description: violation.Message,
+		})
+	}
+	return &InvalidProtoError{PackageName: packageName, Violations: protoViolations}
+}
+
+// isUnchangedMessageVersion checks if a message's latest version matches a newly parsed schema.
+// Versions created before content hashes were introduced are compared by their serialized schema.
+func isUnchangedMessageVersion(latest *entity.MessageVersion, contentHash, serializedSchema string) bool {
+	if latest == nil {
+		return false
+	}
+
+	if latest.ContentHash != "" {
+		return latest.ContentHash == contentHash
+	}
+
+	return latest.SerializedSchema == serializedSchema
+}
+
 // createMessageEntities creates message entities for a given package.
 // This includes creating the message and message version entities.
-func createMessageEntities(ctx context.Context, tx *gorm.DB, reqPkg *v1.PackageFile, packageID uint, packageVersionID uint, fileContentsMap map[string]string, protoFiles []linker.File) error {
+// Compatibility violations are recorded on the response when dryRun is set, and returned together as an
+// IncompatibleSchemaError otherwise.
+func createMessageEntities(ctx context.Context, tx repo.Store, reqPkg *v1.PackageFile, packageID uint, packageVersionID uint, fileContentsMap map[string]string, protoFiles []linker.File, res *v1.UploadPackageVersionResponse, dryRun bool) error {
 
 	// Build mapping of msg name to file name
 	msgNameToFileNameMap := make(map[string]string)
@@ -64,7 +171,10 @@ func createMessageEntities(ctx context.Context, tx *gorm.DB, reqPkg *v1.PackageF
 	for _, protoFile := range protoFiles {
 		// Ensure package name matches
 		if string(protoFile.Package()) != reqPkg.PackageName {
-			return fmt.Errorf("package name mismatch: %s != %s", string(protoFile.Package()), reqPkg.PackageName)
+			return &InvalidProtoError{PackageName: reqPkg.PackageName, Violations: []ProtoViolation{{
+				FileName:    protoFile.Path(),
+				Description: fmt.Sprintf("package name mismatch: %s != %s", string(protoFile.Package()), reqPkg.PackageName),
+			}}}
 		}
 
 		// Parse messages from file
@@ -73,16 +183,35 @@ func createMessageEntities(ctx context.Context, tx *gorm.DB, reqPkg *v1.PackageF
 	}
 
 	// Fetch all existing messages for this package
-	curMessages := make([]entity.Message, 0)
-	err := tx.Model(&entity.Message{}).Where("package_id = ?", packageID).Find(&curMessages).Error
+	curMessages, err := tx.Messages().ListByPackage(packageID)
 	if err != nil {
 		return fmt.Errorf("failed to get current messages: %w", err)
 	}
 
-	// Build a lookup of current message names
+	// Build a lookup of current messages by name
+	curMessagesByName := make(map[string]entity.Message)
 	curMessageNames := make(map[string]bool)
 	for _, msg := range curMessages {
-		curMessageNames[msg.Name] = true
+		curMessagesByName[msg.Name] = msg
+
+		// Messages whose versions were all deleted may be removed
+		if msg.LatestVersion != nil {
+			curMessageNames[msg.Name] = true
+		}
+	}
+
+	// Collect every compatibility violation so they can be reported together
+	incompatErr := &IncompatibleSchemaError{PackageName: reqPkg.PackageName}
+	addViolation := func(violation SchemaViolation, err error) {
+		if !dryRun {
+			incompatErr.Violations = append(incompatErr.Violations, violation)
+			return
+		}
+		res.Violations = append(res.Violations, &v1.Violation{
+			PackageName: reqPkg.PackageName,
+			MessageName: violation.MessageName,
+			Error:       err.Error(),
+		})
 	}
 
 	for _, msg := range parsedMsgs {
@@ -91,7 +220,10 @@ func createMessageEntities(ctx context.Context, tx *gorm.DB, reqPkg *v1.PackageF
 
 		// Check each message for backwards compatibility
 		err := checkBackwardsCompatible(ctx, tx, packageID, msg)
-		if err != nil {
+		var compatErr *proto.CompatibilityError
+		if errors.As(err, &compatErr) {
+			addViolation(SchemaViolation{MessageName: msg.Name, Rule: compatErr.Rule, Description: compatErr.Message}, err)
+		} else if err != nil {
 			return fmt.Errorf("failed to check backwards compatible message: %w", err)
 		}
 
@@ -103,25 +235,34 @@ func createMessageEntities(ctx context.Context, tx *gorm.DB, reqPkg *v1.PackageF
 		}
 
 		// Persist message
-		message := entity.Message{
-			PackageID: packageID,
-			Name:      msg.Name,
-			ProtoBody: protoBody,
+		message, err := tx.Messages().FindOrCreate(packageID, msg.Name, protoBody)
+		if err != nil {
+			return err
 		}
-		result := tx.Where(entity.Message{PackageID: packageID, Name: msg.Name}).FirstOrCreate(&message)
-		if result.Error != nil {
-			return fmt.Errorf("failed to create message: %w", result.Error)
+
+		serializedSchema, err := proto.SerializeMessage(msg)
+		if err != nil {
+			return fmt.Errorf("failed to serialize message: %w", err)
 		}
 
-		// Persist message version
-		nextMessageVersion, err := entity.GetNextMessageVersion(tx, message.ID)
+		contentHash, err := proto.HashMessage(msg)
 		if err != nil {
-			return fmt.Errorf("failed to get next message version: %w", err)
+			return fmt.Errorf("failed to hash message: %w", err)
 		}
 
-		serializedSchema, err := proto.SerializeMessage(msg)
+		// Link the package version to the latest message version if the schema is unchanged, instead of storing it again
+		prevMsg, exists := curMessagesByName[msg.Name]
+		if exists && isUnchangedMessageVersion(prevMsg.LatestVersion, contentHash, serializedSchema) {
+			if err := tx.Messages().LinkVersion(packageVersionID, prevMsg.LatestVersion); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Persist message version
+		nextMessageVersion, err := tx.Messages().NextVersion(message.ID)
 		if err != nil {
-			return fmt.Errorf("failed to serialize message: %w", err)
+			return fmt.Errorf("failed to get next message version: %w", err)
 		}
 
 		messageVersion := entity.MessageVersion{
@@ -129,24 +270,40 @@ func createMessageEntities(ctx context.Context, tx *gorm.DB, reqPkg *v1.PackageF
 			Version:          nextMessageVersion,
 			ProtoBody:        protoBody,
 			SerializedSchema: serializedSchema,
+			ContentHash:      contentHash,
 			PackageVersionID: packageVersionID,
 		}
-		result = tx.Create(&messageVersion)
-		if result.Error != nil {
-			return fmt.Errorf("failed to create message version: %w", result.Error)
+		if err := tx.Messages().CreateVersion(&messageVersion); err != nil {
+			return err
 		}
 
+		// Record whether the message is new or has a changed schema
+		changeType := changeTypeChanged
+		if !exists {
+			changeType = changeTypeNew
+		}
+		res.MessageChanges = append(res.MessageChanges, &v1.MessageChange{
+			PackageName: reqPkg.PackageName,
+			MessageName: msg.Name,
+			ChangeType:  changeType,
+			Version:     uint64(nextMessageVersion),
+		})
+
 		// Persist latest message version
-		message.LatestVersionID = &messageVersion.ID
-		result = tx.Save(&message)
-		if result.Error != nil {
-			return fmt.Errorf("failed to save message: %w", result.Error)
+		if err := tx.Messages().SetLatestVersion(message.ID, &messageVersion); err != nil {
+			return err
 		}
 	}
 
 	// Check that no messages were deleted
 	for msgName := range curMessageNames {
-		return fmt.Errorf("backwards incompatible change: message %s was deleted", msgName)
+		err := fmt.Errorf("backwards incompatible change: message %s was deleted", msgName)
+		metrics.CompatibilityViolations.WithLabelValues(proto.RuleMessageRemoved).Inc()
+		addViolation(SchemaViolation{MessageName: msgName, Rule: proto.RuleMessageRemoved, Description: fmt.Sprintf("message %s was deleted", msgName)}, err)
+	}
+
+	if len(incompatErr.Violations) > 0 {
+		return incompatErr
 	}
 
 	return nil
@@ -154,37 +311,33 @@ func createMessageEntities(ctx context.Context, tx *gorm.DB, reqPkg *v1.PackageF
 
 // createPackageEntities creates package version entities for a given package.
 // This includes creating the package and package version entities.
-func createPackageEntities(tx *gorm.DB, reqPkg *v1.PackageFile) (*entity.Package, *entity.PackageVersion, error) {
+func createPackageEntities(tx repo.Store, reqPkg *v1.PackageFile, contentHash string) (*entity.Package, *entity.PackageVersion, error) {
 	// Persist package
-	pkg := entity.Package{
-		PackageName: reqPkg.PackageName,
-	}
-	result := tx.Where(entity.Package{PackageName: pkg.PackageName}).FirstOrCreate(&pkg)
-	if result.Error != nil {
-		return nil, nil, fmt.Errorf("failed to create package: %w", result.Error)
+	pkg, err := tx.Packages().FindOrCreate(reqPkg.PackageName)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Persist package version
-	nextPackageVersion, err := entity.GetNextPackageVersion(tx, pkg.ID)
+	nextPackageVersion, err := tx.PackageVersions().NextVersion(pkg.ID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get next package version: %w", err)
 	}
 
 	pkgVersion := entity.PackageVersion{
-		PackageID: pkg.ID,
-		Version:   nextPackageVersion,
+		PackageID:   pkg.ID,
+		Version:     nextPackageVersion,
+		ContentHash: contentHash,
 	}
-	err = tx.Create(&pkgVersion).Error
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create package version: %w", err)
+	if err := tx.PackageVersions().Create(&pkgVersion); err != nil {
+		return nil, nil, err
 	}
 
 	// Persist latest package version
-	pkg.LatestVersionID = &pkgVersion.ID
-	result = tx.Save(&pkg)
-	if result.Error != nil {
-		return nil, nil, fmt.Errorf("failed to save package: %w", result.Error)
+	if err := tx.Packages().SetLatestVersion(pkg.ID, &pkgVersion.ID); err != nil {
+		return nil, nil, err
 	}
+	pkg.LatestVersionID = &pkgVersion.ID
 
 	// Persist package version files
 	for _, file := range reqPkg.Files {
@@ -192,29 +345,50 @@ func createPackageEntities(tx *gorm.DB, reqPkg *v1.PackageFile) (*entity.Package
 			PackageVersionID: pkgVersion.ID,
 			FileName:         file.FileName,
 			FileContents:     file.FileContents,
+			Digest:           proto.DigestFile(file.FileContents),
+			ImportPath:       importPathOf(file),
 		}
 
-		result = tx.Create(&pkgVersionFile)
-		if result.Error != nil {
-			return nil, nil, fmt.Errorf("failed to create package version file: %w", result.Error)
+		if err := tx.Files().Create(&pkgVersionFile); err != nil {
+			return nil, nil, err
 		}
 	}
 
-	return &pkg, &pkgVersion, nil
+	return pkg, &pkgVersion, nil
 }
 
-func CreatePackageVersion(ctx context.Context, db *gorm.DB, req *v1.UploadPackageVersionRequest) (*v1.UploadPackageVersionResponse, error) {
-	res := &v1.UploadPackageVersionResponse{}
+// CreatePackageVersion creates a new package version for each package in the request, publishing an event for each
+// once they are committed. Each package's upload is recorded in the audit log within the same transaction. If the
+// request is a dry run, all changes are rolled back and the response describes what would have been created.
+func CreatePackageVersion(ctx context.Context, store repo.Store, bus *events.Bus, policy UploadPolicy, req *v1.UploadPackageVersionRequest) (*v1.UploadPackageVersionResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "CreatePackageVersion")
+	defer span.End()
 
-	_, err := sqlite.WithTx(db, func(tx *gorm.DB) (*entity.Package, error) {
+	res := &v1.UploadPackageVersionResponse{
+		DryRun: req.DryRun,
+	}
+	var created []events.Event
+	var uploads []audit.Event
+
+	// Count the upload's result once it is known
+	result := metrics.ResultFailed
+	defer func() { metrics.Uploads.WithLabelValues(result).Inc() }()
+
+	err := store.Transaction(func(tx repo.Store) error {
+		resolver := newImportResolver(tx, req.Packages)
 
 		for _, reqPkg := range req.Packages {
+			// Renamed packages only accept uploads under their new name
+			if err := checkNotRenamed(tx, reqPkg.PackageName); err != nil {
+				return err
+			}
+
 			// Generate list of inputs for proto.ParseStrings
 			parseInputs := make([]proto.ParseStringInput, 0)
 
 			for _, file := range reqPkg.Files {
 				parseInputs = append(parseInputs, proto.ParseStringInput{
-					FileName:     file.FileName,
+					FileName:     importPathOf(file),
 					FileContents: file.FileContents,
 				})
 			}
@@ -226,57 +400,154 @@ func CreatePackageVersion(ctx context.Context, db *gorm.DB, req *v1.UploadPackag
 			}
 
 			// Parse strings into proto files
-			protoFiles, err := proto.ParseStrings(ctx, parseInputs...)
+			protoFiles, err := proto.ParseStringsWithOptions(ctx, proto.ParseOptions{Resolver: resolver}, parseInputs...)
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse proto files: %w", err)
+				return invalidProto(reqPkg.PackageName, err)
 			}
 
 			// Validate no duplicate file names
 			err = proto.ValidateNoDuplicateFileNames(ctx, protoFiles)
 			if err != nil {
-				return nil, fmt.Errorf("failed to validate proto files: %w", err)
+				return invalidProto(reqPkg.PackageName, err)
+			}
+
+			// Enforce lint rules
+			if policy.LintConfig != nil {
+				violations := proto.Lint(ctx, protoFiles, policy.LintConfig)
+				if len(violations) > 0 && !req.DryRun {
+					result = metrics.ResultLintFailed
+					return lintError(reqPkg.PackageName, violations)
+				}
+				res.LintViolations = append(res.LintViolations, toLintViolationProtos(reqPkg.PackageName, violations)...)
+			}
+
+			// Reject new imports of deprecated package versions
+			if policy.BlockDeprecatedImports {
+				if err := checkDeprecatedImports(tx, reqPkg.PackageName, req.Packages, protoFiles); err != nil {
+					result = metrics.ResultDeprecatedImport
+					return err
+				}
+			}
+
+			// Skip packages whose contents match the latest version
+			contentHash := proto.HashFiles(parseInputs...)
+
+			existingPkg, err := tx.Packages().FindByName(reqPkg.PackageName)
+			if err != nil {
+				return err
+			}
+
+			if existingPkg != nil && existingPkg.LatestVersion != nil && existingPkg.LatestVersion.ContentHash == contentHash {
+				res.UnchangedPackageVersions = append(res.UnchangedPackageVersions, &v1.PackageVersion{
+					Id:        uint64(existingPkg.LatestVersion.ID),
+					Version:   uint64(existingPkg.LatestVersion.Version),
+					CreatedAt: timestamppb.New(existingPkg.LatestVersion.CreatedAt),
+					UpdatedAt: timestamppb.New(existingPkg.LatestVersion.UpdatedAt),
+					PackageId: uint64(existingPkg.ID),
+				})
+				uploads = append(uploads, audit.Event{
+					Action:      audit.ActionUpload,
+					PackageName: reqPkg.PackageName,
+					Version:     uint(existingPkg.LatestVersion.Version),
+					Detail:      "unchanged",
+				})
+				continue
 			}
 
 			// Create package entities
-			pkg, pkgVersion, err := createPackageEntities(tx, reqPkg)
+			pkg, pkgVersion, err := createPackageEntities(tx, reqPkg, contentHash)
 			if err != nil {
-				return nil, fmt.Errorf("failed to create package version entities: %w", err)
+				return fmt.Errorf("failed to create package version entities: %w", err)
 			}
 
 			res.PackageVersions = append(res.PackageVersions, &v1.PackageVersion{
-				Id:        uint64(pkg.ID),
+				Id:        uint64(pkgVersion.ID),
 				Version:   uint64(pkgVersion.Version),
-				CreatedAt: timestamppb.New(pkg.CreatedAt),
-				UpdatedAt: timestamppb.New(pkg.UpdatedAt),
+				CreatedAt: timestamppb.New(pkgVersion.CreatedAt),
+				UpdatedAt: timestamppb.New(pkgVersion.UpdatedAt),
 				PackageId: uint64(pkg.ID),
 			})
+			created = append(created, events.Event{
+				Type:        events.TypePackageVersionCreated,
+				PackageName: pkg.PackageName,
+				Version:     uint(pkgVersion.Version),
+			})
+			uploads = append(uploads, audit.Event{
+				Action:      audit.ActionUpload,
+				PackageName: pkg.PackageName,
+				Version:     uint(pkgVersion.Version),
+			})
 
 			// Create message entities
-			err = createMessageEntities(ctx, tx, reqPkg, pkg.ID, pkgVersion.ID, fileContentsMap, protoFiles)
+			err = createMessageEntities(ctx, tx, reqPkg, pkg.ID, pkgVersion.ID, fileContentsMap, protoFiles, res, req.DryRun)
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 		}
 
-		return nil, nil
+		res.Unchanged = len(res.PackageVersions) == 0 && len(res.UnchangedPackageVersions) > 0
+
+		// Roll back the transaction so nothing is persisted
+		if req.DryRun {
+			return errDryRun
+		}
+
+		for _, event := range uploads {
+			if err := audit.RecordSuccess(ctx, tx.AuditEvents(), event); err != nil {
+				return err
+			}
+		}
+
+		return nil
 	})
-	if err != nil {
+	var incompatErr *IncompatibleSchemaError
+	if errors.Is(err, errDryRun) {
+		result = metrics.ResultDryRun
+		return res, nil
+	} else if errors.As(err, &incompatErr) {
+		result = metrics.ResultIncompatible
+		return nil, err
+	} else if err != nil {
 		return nil, err
 	}
 
+	result = metrics.ResultCreated
+	if res.Unchanged {
+		result = metrics.ResultUnchanged
+	}
+
+	for _, event := range created {
+		bus.Publish(ctx, event)
+	}
+
 	return res, nil
 }
 
-func ValidatePackageVersion(ctx context.Context, db *gorm.DB, req *v1.ValidatePackageVersionRequest) (*v1.ValidatePackageVersionResponse, error) {
+// ValidatePackageVersion checks that each package in the request is backwards compatible with its latest version.
+// Lint rules are only enforced when lintConfig is non-nil.
+func ValidatePackageVersion(ctx context.Context, store repo.Store, policy UploadPolicy, req *v1.ValidatePackageVersionRequest) (*v1.ValidatePackageVersionResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "ValidatePackageVersion")
+	defer span.End()
+
+	// Count the validation's result once it is known
+	result := metrics.ResultFailed
+	defer func() { metrics.Validations.WithLabelValues(result).Inc() }()
+
+	resolver := newImportResolver(store, req.Packages)
 
 	for _, reqPkg := range req.Packages {
+		// Renamed packages only accept uploads under their new name
+		if err := checkNotRenamed(store, reqPkg.PackageName); err != nil {
+			return nil, err
+		}
+
 		// Generate list of inputs for proto.ParseStrings
 		parseInputs := make([]proto.ParseStringInput, 0)
 
 		for _, file := range reqPkg.Files {
 			parseInputs = append(parseInputs, proto.ParseStringInput{
-				FileName:     file.FileName,
+				FileName:     importPathOf(file),
 				FileContents: file.FileContents,
 			})
 		}
@@ -288,15 +559,39 @@ func ValidatePackageVersion(ctx context.Context, db *gorm.DB, req *v1.ValidatePa
 		}
 
 		// Parse strings into proto files
-		protoFiles, err := proto.ParseStrings(ctx, parseInputs...)
+		protoFiles, err := proto.ParseStringsWithOptions(ctx, proto.ParseOptions{Resolver: resolver}, parseInputs...)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse proto files: %w", err)
+			return nil, invalidProto(reqPkg.PackageName, err)
 		}
 
 		// Validate no duplicate file names
 		err = proto.ValidateNoDuplicateFileNames(ctx, protoFiles)
 		if err != nil {
-			return nil, fmt.Errorf("failed to validate proto files: %w", err)
+			return nil, invalidProto(reqPkg.PackageName, err)
+		}
+
+		// Enforce lint rules
+		if policy.LintConfig != nil {
+			violations := proto.Lint(ctx, protoFiles, policy.LintConfig)
+			if len(violations) > 0 {
+				result = metrics.ResultLintFailed
+				return &v1.ValidatePackageVersionResponse{
+					IsValid:        false,
+					Error:          lintError(reqPkg.PackageName, violations).Error(),
+					LintViolations: toLintViolationProtos(reqPkg.PackageName, violations),
+				}, nil
+			}
+		}
+
+		// Reject new imports of deprecated package versions
+		if policy.BlockDeprecatedImports {
+			if err := checkDeprecatedImports(store, reqPkg.PackageName, req.Packages, protoFiles); err != nil {
+				result = metrics.ResultDeprecatedImport
+				return &v1.ValidatePackageVersionResponse{
+					IsValid: false,
+					Error:   err.Error(),
+				}, nil
+			}
 		}
 
 		// Parse messages from files
@@ -307,80 +602,106 @@ func ValidatePackageVersion(ctx context.Context, db *gorm.DB, req *v1.ValidatePa
 		}
 
 		// Check if package exists
-		pkgs := make([]entity.Package, 0)
-		err = db.Model(&entity.Package{}).Where("package_name = ?", reqPkg.PackageName).Find(&pkgs).Error
+		pkg, err := store.Packages().FindByName(reqPkg.PackageName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get packages: %w", err)
 		}
 
-		if len(pkgs) == 0 {
-			return &v1.ValidatePackageVersionResponse{
-				IsValid: true,
-				Error:   "",
-			}, nil
+		// New packages have nothing to be compatible with
+		if pkg == nil {
+			continue
 		}
-		pkg := pkgs[0]
 
 		// Validate messages
 		for _, msg := range parsedMsgs {
-			err = checkBackwardsCompatible(ctx, db, pkg.ID, msg)
-			if err != nil {
+			err = checkBackwardsCompatible(ctx, store, pkg.ID, msg)
+			var compatErr *proto.CompatibilityError
+			if errors.As(err, &compatErr) {
+				result = metrics.ResultIncompatible
 				return &v1.ValidatePackageVersionResponse{
 					IsValid: false,
 					Error:   err.Error(),
 				}, nil
+			} else if err != nil {
+				return nil, err
 			}
 		}
-
 	}
 
+	result = metrics.ResultValid
 	return &v1.ValidatePackageVersionResponse{
 		IsValid: true,
 		Error:   "",
 	}, nil
 }
 
+// toPackageVersionProto converts a package version into its API representation
+func toPackageVersionProto(pkgVer *entity.PackageVersion) *v1.PackageVersion {
+	res := &v1.PackageVersion{
+		Id:          uint64(pkgVer.ID),
+		Version:     uint64(pkgVer.Version),
+		CreatedAt:   timestamppb.New(pkgVer.CreatedAt),
+		UpdatedAt:   timestamppb.New(pkgVer.UpdatedAt),
+		PackageId:   uint64(pkgVer.PackageID),
+		ContentHash: pkgVer.ContentHash,
+		Deprecation: toDeprecationProto(pkgVer.Deprecation),
+	}
+
+	if pkgVer.DeletedAt != nil {
+		res.DeletedAt = timestamppb.New(*pkgVer.DeletedAt)
+	}
+
+	return res
+}
+
 // GetPackageVersion gets a package version by package name and version.
 // Returns the package version and all files in the package version.
-func GetPackageVersion(ctx context.Context, db *gorm.DB, req *v1.GetPackageVersionRequest) (*v1.GetPackageVersionResponse, error) {
+func GetPackageVersion(ctx context.Context, store repo.Store, req *v1.GetPackageVersionRequest) (*v1.GetPackageVersionResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "GetPackageVersion")
+	defer span.End()
 
-	// Fetch package
-	pkgs := []entity.Package{}
-	err := db.Model(&entity.Package{}).Where("package_name = ?", req.PackageName).Find(&pkgs).Error
+	// Fetch package, following renames
+	pkg, err := resolvePackage(store, req.PackageName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get packages: %w", err)
+		return nil, err
 	}
-
-	if len(pkgs) == 0 {
-		return nil, fmt.Errorf("package not found")
+	if pkg == nil {
+		return nil, notFound(ResourcePackage, req.PackageName)
 	}
 
-	pkg := pkgs[0]
-
 	// Fetch package version
-	pkgVersions := []entity.PackageVersion{}
-	err = db.Model(&entity.PackageVersion{}).Where("package_id = ? AND version = ?", pkg.ID, req.Version).Find(&pkgVersions).Error
+	pkgVer, err := store.PackageVersions().Find(pkg.ID, int(req.Version))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get package versions: %w", err)
 	}
 
-	if len(pkgVersions) == 0 {
-		return nil, fmt.Errorf("package version not found")
+	if pkgVer == nil {
+		return nil, notFound(ResourcePackageVersion, packageVersionName(pkg.PackageName, req.Version))
 	}
 
-	pkgVer := pkgVersions[0]
+	if pkgVer.DeletedAt != nil {
+		return nil, &NotFoundError{
+			Resource: ResourcePackageVersion,
+			Name:     packageVersionName(pkg.PackageName, req.Version),
+			Err: fmt.Errorf("%w: version %d of %s was deleted on %s and can be restored by an admin until it is purged",
+				ErrPackageVersionDeleted, pkgVer.Version, pkg.PackageName, pkgVer.DeletedAt.UTC().Format(time.RFC3339)),
+		}
+	}
 
-	files := []entity.PackageVersionFile{}
-	err = db.Model(&entity.PackageVersionFile{}).Where("package_version_id = ?", pkgVer.ID).Find(&files).Error
+	files, err := store.Files().List(pkgVer.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get package version files: %w", err)
 	}
 
+	deprecatedMessages, err := listDeprecatedMessages(store, pkg.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	res := &v1.GetPackageVersionResponse{
-		PackageVersion: &v1.PackageVersion{
-			Id:      uint64(pkgVer.ID),
-			Version: uint64(pkgVer.Version),
-		},
+		PackageVersion:     toPackageVersionProto(pkgVer),
+		DeprecatedMessages: deprecatedMessages,
+		PackageName:        pkg.PackageName,
 	}
 
 	for _, file := range files {
@@ -391,9 +712,68 @@ func GetPackageVersion(ctx context.Context, db *gorm.DB, req *v1.GetPackageVersi
 			UpdatedAt:        timestamppb.New(file.UpdatedAt),
 			PackageVersionId: uint64(pkgVer.ID),
 			FileName:         file.FileName,
+			ImportPath:       file.ImportPath,
+			Digest:           file.Digest,
 		})
 	}
 
 	return res, nil
 
 }
+
+// ResolveImport finds the file for an import path among the latest versions of all registered packages.
+func ResolveImport(ctx context.Context, store repo.Store, req *v1.ResolveImportRequest) (*v1.ResolveImportResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "ResolveImport")
+	defer span.End()
+
+	file, err := store.Files().FindLatestByImportPath(req.ImportPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if file == nil {
+		return nil, notFound(ResourceImport, req.ImportPath)
+	}
+
+	return &v1.ResolveImportResponse{
+		PackageName: file.PackageVersion.Package.PackageName,
+		Version:     uint64(file.PackageVersion.Version),
+		File: &v1.PackageVersionFile{
+			Id:               uint64(file.ID),
+			ProtoContents:    file.FileContents,
+			CreatedAt:        timestamppb.New(file.CreatedAt),
+			UpdatedAt:        timestamppb.New(file.UpdatedAt),
+			PackageVersionId: uint64(file.PackageVersionID),
+			FileName:         file.FileName,
+			ImportPath:       file.ImportPath,
+			Digest:           file.Digest,
+		},
+	}, nil
+}
+
+// ListPackageVersions lists all versions of a package that are not deleted, newest first.
+func ListPackageVersions(ctx context.Context, store repo.Store, req *v1.ListPackageVersionsRequest) (*v1.ListPackageVersionsResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "ListPackageVersions")
+	defer span.End()
+
+	pkg, err := store.Packages().FindByName(req.PackageName)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkg == nil {
+		return nil, notFound(ResourcePackage, req.PackageName)
+	}
+
+	pkgVersions, err := store.PackageVersions().List(pkg.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list package versions: %w", err)
+	}
+
+	res := &v1.ListPackageVersionsResponse{}
+	for i := range pkgVersions {
+		res.PackageVersions = append(res.PackageVersions, toPackageVersionProto(&pkgVersions[i]))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,40 @@
+package ctrl
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/repo"
+)
+
+func TestValidatePackageVersion(t *testing.T) {
+	ctx := context.Background()
+	store := repo.NewMemoryStore()
+
+	uploadTestPackage(t, store, "validate.existing", "message A { string x = 1; string y = 2; }\n")
+
+	// A new package earlier in the request does not end the validation of the packages after it
+	res, err := ValidatePackageVersion(ctx, store, UploadPolicy{}, &v1.ValidatePackageVersionRequest{
+		Packages: []*v1.PackageFile{
+			testPackageFile("validate.fresh", "message B { string z = 1; }\n"),
+			testPackageFile("validate.existing", "message A { string x = 1; }\n"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to validate: %v", err)
+	}
+	if res.IsValid {
+		t.Fatal("Expected removing a field from an existing package to be invalid")
+	}
+
+	res, err = ValidatePackageVersion(ctx, store, UploadPolicy{}, &v1.ValidatePackageVersionRequest{
+		Packages: []*v1.PackageFile{
+			testPackageFile("validate.fresh", "message B { string z = 1; }\n"),
+			testPackageFile("validate.existing", "message A { string x = 1; string y = 2; string w = 3; }\n"),
+		},
+	})
+	if err != nil || !res.IsValid {
+		t.Fatalf("Expected compatible packages to be valid, got %v (%v)", res, err)
+	}
+}
@@ -0,0 +1,192 @@
+package ctrl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/infra/auth"
+)
+
+const (
+	// Change actions that are not audit actions
+	ChangeSync      = "sync"
+	ChangeHeartbeat = "heartbeat"
+)
+
+// ErrPackageNotFound is wrapped in the NotFoundError returned when exporting a package that does not exist
+var ErrPackageNotFound = errors.New("package not found")
+
+// replicatedActions are the audit actions that change a package
+var replicatedActions = map[string]bool{
+	audit.ActionUpload:         true,
+	audit.ActionDeleteVersion:  true,
+	audit.ActionRestoreVersion: true,
+	audit.ActionPurgeVersion:   true,
+	audit.ActionDeprecate:      true,
+	audit.ActionUndeprecate:    true,
+	audit.ActionDeletePackage:  true,
+	audit.ActionRenamePackage:  true,
+	audit.ActionImport:         true,
+}
+
+// ListChanges lists up to limit successful package mutations recorded after a change, oldest first. Changes to
+// packages the caller cannot read are left out. Also returns the ID of the last event scanned, which is where the
+// next call should resume even when every scanned event was left out.
+func ListChanges(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, afterID uint64, limit int) ([]*v1.Change, uint64, error) {
+	ctx, store, span := startSpan(ctx, store, "ListChanges")
+	defer span.End()
+
+	events, err := store.AuditEvents().List(entity.AuditEventFilter{
+		Result:      audit.ResultSuccess,
+		AfterID:     uint(afterID),
+		OldestFirst: true,
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, afterID, err
+	}
+
+	var changes []*v1.Change
+	for _, event := range events {
+		afterID = uint64(event.ID)
+
+		if !replicatedActions[event.Action] || event.Detail == "unchanged" {
+			continue
+		}
+		if err := authorizer.Authorize(ctx, auth.RoleReader, event.PackageName); err != nil {
+			continue
+		}
+
+		changes = append(changes, &v1.Change{
+			Id:          uint64(event.ID),
+			CreatedAt:   timestamppb.New(event.CreatedAt),
+			Action:      event.Action,
+			PackageName: event.PackageName,
+			Version:     uint64(event.Version),
+		})
+	}
+
+	return changes, afterID, nil
+}
+
+// SnapshotChanges lists a sync change for every package the caller can read, along with the ID of the latest change
+// the snapshot covers. Watching from that ID afterwards misses no change.
+func SnapshotChanges(ctx context.Context, store repo.Store, authorizer *auth.Authorizer) ([]*v1.Change, uint64, error) {
+	ctx, store, span := startSpan(ctx, store, "SnapshotChanges")
+	defer span.End()
+
+	// Read the latest change before the packages, so changes made in between are sent again rather than lost
+	var headID uint64
+	latest, err := store.AuditEvents().List(entity.AuditEventFilter{Limit: 1})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(latest) > 0 {
+		headID = uint64(latest[0].ID)
+	}
+
+	packages, err := store.Packages().List(-1, 0, "")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var changes []*v1.Change
+	for _, pkg := range packages {
+		if err := authorizer.Authorize(ctx, auth.RoleReader, pkg.PackageName); err != nil {
+			continue
+		}
+		changes = append(changes, &v1.Change{
+			CreatedAt:   timestamppb.New(pkg.UpdatedAt),
+			Action:      ChangeSync,
+			PackageName: pkg.PackageName,
+		})
+	}
+
+	return changes, headID, nil
+}
+
+// ExportPackage exports a package, found by its current or a former name, in the archive format.
+// The caller must be a reader of the package.
+func ExportPackage(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.ExportPackageRequest) (*v1.ExportPackageResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "ExportPackage")
+	defer span.End()
+
+	res := &v1.ExportPackageResponse{}
+
+	err := store.Transaction(func(tx repo.Store) error {
+		pkg, err := resolvePackage(tx, req.PackageName)
+		if err != nil {
+			return err
+		}
+		// Packages the caller cannot read are reported as not found, so callers cannot tell whether they exist
+		notFoundErr := &NotFoundError{
+			Resource: ResourcePackage,
+			Name:     req.PackageName,
+			Err:      fmt.Errorf("%w: %s", ErrPackageNotFound, req.PackageName),
+		}
+		if pkg == nil {
+			return notFoundErr
+		}
+		if err := authorizer.Authorize(ctx, auth.RoleReader, pkg.PackageName); errors.Is(err, auth.ErrPermissionDenied) {
+			return notFoundErr
+		} else if err != nil {
+			return err
+		}
+
+		var archive bytes.Buffer
+		enc := json.NewEncoder(&archive)
+		if err := writeArchiveHeader(enc); err != nil {
+			return err
+		}
+		if err := exportPackage(tx, enc, *pkg, make(map[string]bool), &ArchiveStats{}); err != nil {
+			return err
+		}
+
+		res.PackageName = pkg.PackageName
+		res.Archive = archive.Bytes()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ReplicatePackages makes a store match the packages of an archive exported by another registry, in a single
+// transaction. Versions keep their numbers, versions missing from the archive are purged, and renames, deletions and
+// deprecations are replayed. Each package is recorded as imported, so mirrors of the store replicate it in turn.
+func ReplicatePackages(ctx context.Context, store repo.Store, r io.Reader) (*ArchiveStats, error) {
+	return importArchive(ctx, store, r, true)
+}
+
+// RemoveReplicatedPackage deletes a package, found by its current or a former name, that no longer exists in the
+// registry it was replicated from. Unknown packages are ignored.
+func RemoveReplicatedPackage(ctx context.Context, store repo.Store, packageName string) error {
+	return store.Transaction(func(tx repo.Store) error {
+		pkg, err := resolvePackage(tx, packageName)
+		if err != nil || pkg == nil {
+			return err
+		}
+		if err := tx.Packages().Delete(pkg.ID); err != nil {
+			return err
+		}
+
+		return audit.RecordSuccess(ctx, tx.AuditEvents(), audit.Event{
+			Actor:       audit.SystemActor,
+			Action:      audit.ActionDeletePackage,
+			PackageName: pkg.PackageName,
+			Detail:      "replicated",
+		})
+	})
+}
@@ -0,0 +1,142 @@
+package ctrl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+)
+
+// replicateTestPackage copies a package from one store to another the way a mirror does
+func replicateTestPackage(t *testing.T, upstream, mirror repo.Store, packageName string) {
+	t.Helper()
+
+	res, err := ExportPackage(context.Background(), upstream, nil, &v1.ExportPackageRequest{PackageName: packageName})
+	if errors.Is(err, ErrPackageNotFound) {
+		if err := RemoveReplicatedPackage(context.Background(), mirror, packageName); err != nil {
+			t.Fatalf("Failed to remove %s: %v", packageName, err)
+		}
+		return
+	} else if err != nil {
+		t.Fatalf("Failed to export %s: %v", packageName, err)
+	}
+
+	if _, err := ReplicatePackages(context.Background(), mirror, bytes.NewReader(res.Archive)); err != nil {
+		t.Fatalf("Failed to replicate %s: %v", packageName, err)
+	}
+}
+
+func TestReplication(t *testing.T) {
+	ctx := context.Background()
+	upstream := repo.NewMemoryStore()
+	mirror := repo.NewMemoryStore()
+
+	uploadTestPackage(t, upstream, "mirror", "message A { string x = 1; }\n")
+	uploadTestPackage(t, upstream, "mirror", "message A { string x = 1; string y = 2; }\nmessage B { string z = 1; }\n")
+	replicateTestPackage(t, upstream, mirror, "mirror")
+
+	pkg, err := mirror.Packages().FindByName("mirror")
+	if err != nil || pkg == nil || pkg.LatestVersion == nil || pkg.LatestVersion.Version != 2 {
+		t.Fatalf("Expected latest version 2, got %v (%v)", pkg, err)
+	}
+
+	// Deletions, renames and deprecations are replayed, even when replicating by a former name
+	if _, err := DeletePackageVersion(ctx, upstream, nil, nil, &v1.DeletePackageVersionRequest{PackageName: "mirror", Version: 2}); err != nil {
+		t.Fatalf("Failed to delete version: %v", err)
+	}
+	if _, err := RenamePackage(ctx, upstream, nil, &v1.RenamePackageRequest{PackageName: "mirror", NewName: "mirror.v1"}); err != nil {
+		t.Fatalf("Failed to rename package: %v", err)
+	}
+	if _, err := DeprecatePackageVersion(ctx, upstream, nil, &v1.DeprecatePackageVersionRequest{PackageName: "mirror.v1", Version: 1, Reason: "old"}); err != nil {
+		t.Fatalf("Failed to deprecate version: %v", err)
+	}
+	replicateTestPackage(t, upstream, mirror, "mirror")
+
+	got, err := GetPackageVersion(ctx, mirror, &v1.GetPackageVersionRequest{PackageName: "mirror", Version: 1})
+	if err != nil || got.PackageName != "mirror.v1" || got.PackageVersion.Deprecation.GetReason() != "old" {
+		t.Fatalf("Expected deprecated version 1 of mirror.v1, got %v (%v)", got, err)
+	}
+	if _, err := GetPackageVersion(ctx, mirror, &v1.GetPackageVersionRequest{PackageName: "mirror.v1", Version: 2}); !errors.Is(err, ErrPackageVersionDeleted) {
+		t.Fatalf("Expected version 2 to be deleted, got %v", err)
+	}
+	if count := countTestMessages(t, mirror, "mirror.v1"); count != 1 {
+		t.Fatalf("Expected 1 visible message, got %d", count)
+	}
+
+	// Purged versions are removed
+	if _, err := PurgeDeletedPackageVersions(ctx, upstream, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to purge versions: %v", err)
+	}
+	replicateTestPackage(t, upstream, mirror, "mirror.v1")
+
+	pkg, _ = mirror.Packages().FindByName("mirror.v1")
+	if version, err := mirror.PackageVersions().Find(pkg.ID, 2); err != nil || version != nil {
+		t.Fatalf("Expected version 2 to be purged, got %v (%v)", version, err)
+	}
+
+	// Dry runs are rolled back along with their audit events, so they are never listed
+	_, err = CreatePackageVersion(ctx, upstream, nil, UploadPolicy{}, &v1.UploadPackageVersionRequest{
+		Packages: []*v1.PackageFile{testPackageFile("mirror.v1", "message C { string x = 1; }\n")},
+		DryRun:   true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to dry run upload: %v", err)
+	}
+
+	// Changes are listed oldest first, and resume after the last event scanned
+	changes, lastID, err := ListChanges(ctx, upstream, nil, 0, 100)
+	if err != nil {
+		t.Fatalf("Failed to list changes: %v", err)
+	}
+	actions := []string{audit.ActionUpload, audit.ActionUpload, audit.ActionDeleteVersion, audit.ActionRenamePackage, audit.ActionDeprecate, audit.ActionPurgeVersion}
+	if len(changes) != len(actions) {
+		t.Fatalf("Expected %d changes, got %v", len(actions), changes)
+	}
+	for i, action := range actions {
+		if changes[i].Action != action {
+			t.Fatalf("Expected change %d to be %s, got %s", i, action, changes[i].Action)
+		}
+	}
+	if lastID != changes[len(changes)-1].Id {
+		t.Fatalf("Expected last ID %d, got %d", changes[len(changes)-1].Id, lastID)
+	}
+	if rest, _, err := ListChanges(ctx, upstream, nil, changes[3].Id, 100); err != nil || len(rest) != 2 {
+		t.Fatalf("Expected 2 changes after the rename, got %v (%v)", rest, err)
+	}
+
+	// Packages deleted upstream are deleted from the mirror
+	if _, err := DeletePackage(ctx, upstream, nil, nil, &v1.DeletePackageRequest{PackageName: "mirror.v1"}); err != nil {
+		t.Fatalf("Failed to delete package: %v", err)
+	}
+	replicateTestPackage(t, upstream, mirror, "mirror.v1")
+
+	if pkg, err := mirror.Packages().FindByName("mirror.v1"); err != nil || pkg != nil {
+		t.Fatalf("Expected mirror.v1 to be deleted, got %v (%v)", pkg, err)
+	}
+	if alias, err := mirror.Packages().FindAlias("mirror"); err != nil || alias != nil {
+		t.Fatalf("Expected the former name to be deleted, got %v (%v)", alias, err)
+	}
+
+	// The mirror lists what it replicated, so another mirror can follow it
+	mirrorChanges, _, err := ListChanges(ctx, mirror, nil, 0, 100)
+	if err != nil {
+		t.Fatalf("Failed to list mirror changes: %v", err)
+	}
+	actions = []string{audit.ActionImport, audit.ActionImport, audit.ActionImport, audit.ActionDeletePackage}
+	if len(mirrorChanges) != len(actions) {
+		t.Fatalf("Expected %d mirror changes, got %v", len(actions), mirrorChanges)
+	}
+	for i, action := range actions {
+		if mirrorChanges[i].Action != action {
+			t.Fatalf("Expected mirror change %d to be %s, got %s", i, action, mirrorChanges[i].Action)
+		}
+	}
+	if last := mirrorChanges[len(mirrorChanges)-1]; last.PackageName != "mirror.v1" {
+		t.Fatalf("Expected the deletion of mirror.v1, got %s", last.PackageName)
+	}
+}
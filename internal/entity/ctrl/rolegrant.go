@@ -0,0 +1,180 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/infra/auth"
+)
+
+func toRoleGrantProto(grant *entity.RoleGrant) *v1.RoleGrant {
+	return &v1.RoleGrant{
+		Id:             uint64(grant.ID),
+		CreatedAt:      timestamppb.New(grant.CreatedAt),
+		Subject:        grant.Subject,
+		Role:           grant.Role,
+		PackagePattern: grant.PackagePattern,
+	}
+}
+
+// roleGrantDetail describes a grant in the audit log
+func roleGrantDetail(role, subject string) string {
+	return fmt.Sprintf("%s for %s", role, subject)
+}
+
+// GrantRole grants a role to a subject on a package pattern.
+// The caller must be an admin of every package matching the pattern. Attempts are recorded in the audit log.
+func GrantRole(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.GrantRoleRequest) (*v1.GrantRoleResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "GrantRole")
+	defer span.End()
+
+	event := audit.Event{
+		Action:      audit.ActionGrantRole,
+		PackageName: req.PackagePattern,
+		Detail:      roleGrantDetail(req.Role, req.Subject),
+	}
+
+	res, err := grantRole(ctx, store, authorizer, req, event)
+	if err != nil {
+		audit.Record(ctx, store.AuditEvents(), event, err)
+	}
+
+	return res, err
+}
+
+func grantRole(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.GrantRoleRequest, event audit.Event) (*v1.GrantRoleResponse, error) {
+	if req.Subject == "" {
+		return nil, invalidArgument("subject", "subject is required")
+	}
+
+	role, err := auth.ParseRole(req.Role)
+	if err != nil {
+		return nil, &InvalidArgumentError{Field: "role", Description: err.Error()}
+	}
+
+	if err := auth.ValidatePackagePattern(req.PackagePattern); err != nil {
+		return nil, &InvalidArgumentError{Field: "package_pattern", Description: err.Error()}
+	}
+
+	if err := authorizer.AuthorizePattern(ctx, auth.RoleAdmin, req.PackagePattern); err != nil {
+		return nil, err
+	}
+
+	var grant *entity.RoleGrant
+	err = store.Transaction(func(tx repo.Store) error {
+		// Granting an existing role is a no-op
+		grant, err = tx.RoleGrants().Find(req.Subject, string(role), req.PackagePattern)
+		if err != nil {
+			return err
+		}
+
+		if grant == nil {
+			grant = &entity.RoleGrant{
+				Subject:        req.Subject,
+				Role:           string(role),
+				PackagePattern: req.PackagePattern,
+			}
+			if err := tx.RoleGrants().Create(grant); err != nil {
+				return err
+			}
+		}
+
+		return audit.RecordSuccess(ctx, tx.AuditEvents(), event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.GrantRoleResponse{Grant: toRoleGrantProto(grant)}, nil
+}
+
+// RevokeRole removes a grant. The caller must be an admin of every package matching the grant's pattern.
+// Attempts are recorded in the audit log.
+func RevokeRole(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.RevokeRoleRequest) (*v1.RevokeRoleResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "RevokeRole")
+	defer span.End()
+
+	grant, err := revokeRole(ctx, store, authorizer, req)
+	if err != nil {
+		audit.Record(ctx, store.AuditEvents(), revokeRoleEvent(req, grant), err)
+		return nil, err
+	}
+	return &v1.RevokeRoleResponse{}, nil
+}
+
+// revokeRoleEvent builds the audit event for revoking a grant, which is nil when the grant does not exist
+func revokeRoleEvent(req *v1.RevokeRoleRequest, grant *entity.RoleGrant) audit.Event {
+	event := audit.Event{Action: audit.ActionRevokeRole, Detail: fmt.Sprintf("grant #%d", req.Id)}
+	if grant != nil {
+		event.PackageName = grant.PackagePattern
+		event.Detail = roleGrantDetail(grant.Role, grant.Subject)
+	}
+	return event
+}
+
+// revokeRole deletes a grant, returning it when it exists
+func revokeRole(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.RevokeRoleRequest) (*entity.RoleGrant, error) {
+	grant, err := store.RoleGrants().Get(uint(req.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	if grant == nil {
+		return nil, notFound(ResourceRoleGrant, strconv.FormatUint(req.Id, 10))
+	}
+
+	if err := authorizer.AuthorizePattern(ctx, auth.RoleAdmin, grant.PackagePattern); err != nil {
+		return grant, err
+	}
+
+	err = store.Transaction(func(tx repo.Store) error {
+		if err := tx.RoleGrants().Delete(grant.ID); err != nil {
+			return err
+		}
+		return audit.RecordSuccess(ctx, tx.AuditEvents(), revokeRoleEvent(req, grant))
+	})
+	if err != nil {
+		return grant, err
+	}
+
+	return grant, nil
+}
+
+// ListRoleGrants lists role grants, optionally filtered by subject. The caller must be an admin of some package, and
+// only sees the grants on patterns it is an admin of, as those are the grants it may revoke.
+func ListRoleGrants(ctx context.Context, store repo.Store, authorizer *auth.Authorizer, req *v1.ListRoleGrantsRequest) (*v1.ListRoleGrantsResponse, error) {
+	ctx, store, span := startSpan(ctx, store, "ListRoleGrants")
+	defer span.End()
+
+	if err := authorizer.AuthorizeAny(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	grants, err := store.RoleGrants().List(req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &v1.ListRoleGrantsResponse{}
+	for i := range grants {
+		err := authorizer.AuthorizePattern(ctx, auth.RoleAdmin, grants[i].PackagePattern)
+		if errors.Is(err, auth.ErrPermissionDenied) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		res.Grants = append(res.Grants, toRoleGrantProto(&grants[i]))
+	}
+
+	return res, nil
+}
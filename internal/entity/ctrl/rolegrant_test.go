@@ -0,0 +1,43 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/auth"
+)
+
+func TestListRoleGrants(t *testing.T) {
+	store := repo.NewMemoryStore()
+	for _, grant := range []entity.RoleGrant{
+		{Subject: "alice", Role: string(auth.RolePublisher), PackagePattern: "payments.*"},
+		{Subject: "bob", Role: string(auth.RoleAdmin), PackagePattern: "payments.*"},
+		{Subject: "carol", Role: string(auth.RoleAdmin), PackagePattern: "orders.v1"},
+	} {
+		if err := store.RoleGrants().Create(&grant); err != nil {
+			t.Fatalf("Failed to grant role: %v", err)
+		}
+	}
+	authorizer := &auth.Authorizer{Store: store}
+
+	// Admins only see the grants they could revoke
+	bob := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "bob", Method: auth.MethodToken})
+	res, err := ListRoleGrants(bob, store, authorizer, &v1.ListRoleGrantsRequest{})
+	if err != nil || len(res.Grants) != 2 {
+		t.Fatalf("Expected 2 grants on payments.*, got %v (%v)", res, err)
+	}
+
+	alice := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice", Method: auth.MethodToken})
+	if _, err := ListRoleGrants(alice, store, authorizer, &v1.ListRoleGrantsRequest{}); !errors.Is(err, auth.ErrPermissionDenied) {
+		t.Fatalf("Expected listing grants to require an admin, got %v", err)
+	}
+
+	res, err = ListRoleGrants(context.Background(), store, nil, &v1.ListRoleGrantsRequest{})
+	if err != nil || len(res.Grants) != 3 {
+		t.Fatalf("Expected every grant without authorization, got %v (%v)", res, err)
+	}
+}
@@ -0,0 +1,16 @@
+package ctrl
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/tracing"
+)
+
+// startSpan starts the span of a ctrl function and binds the store's queries to it
+func startSpan(ctx context.Context, store repo.Store, name string) (context.Context, repo.Store, trace.Span) {
+	ctx, span := tracing.Start(ctx, "ctrl."+name)
+	return ctx, store.WithContext(ctx), span
+}
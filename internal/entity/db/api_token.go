@@ -0,0 +1,71 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIToken is the database model for a static API token.
+// Only the SHA-256 hash of the token is stored.
+type APIToken struct {
+	ID        uint      `gorm:"primaryKey,autoIncrement"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	Name      string `gorm:"unique"`
+	TokenHash string `gorm:"unique"`
+
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+func CreateAPIToken(db *gorm.DB, token *APIToken) error {
+	if err := db.Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create api token: %w", err)
+	}
+	return nil
+}
+
+// FindAPITokenByHash fetches a token by its hash.
+// Returns nil if no token exists with the given hash.
+func FindAPITokenByHash(db *gorm.DB, tokenHash string) (*APIToken, error) {
+	var tokens []APIToken
+	err := db.Model(&APIToken{}).Where("token_hash = ?", tokenHash).Limit(1).Find(&tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find api token: %w", err)
+	}
+
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	return &tokens[0], nil
+}
+
+func ListAPITokens(db *gorm.DB) ([]APIToken, error) {
+	var tokens []APIToken
+	if err := db.Model(&APIToken{}).Order("name").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to list api tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteAPIToken deletes a token by name. Returns false if no token exists with the given name.
+func DeleteAPIToken(db *gorm.DB, name string) (bool, error) {
+	result := db.Where("name = ?", name).Delete(&APIToken{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete api token: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// TouchAPIToken records when a token was last used
+func TouchAPIToken(db *gorm.DB, id uint, usedAt time.Time) error {
+	err := db.Model(&APIToken{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to update api token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditEvent is the database model for a recorded registry mutation.
+// The table is append-only: events are never updated or deleted.
+type AuditEvent struct {
+	ID        uint      `gorm:"primaryKey,autoIncrement"`
+	CreatedAt time.Time `gorm:"autoCreateTime,index"`
+
+	Actor       string `gorm:"not null,index"`
+	Action      string `gorm:"not null"`
+	PackageName string `gorm:"index"`
+	Version     uint
+	Detail      string
+
+	// Request metadata
+	Method        string
+	ClientAddress string
+	UserAgent     string
+
+	Result string `gorm:"not null"`
+	Error  string
+}
+
+// AuditEventFilter narrows the events returned by ListAuditEvents. Zero values match everything.
+type AuditEventFilter struct {
+	Actor       string
+	Action      string
+	PackageName string
+	Since       time.Time
+	Until       time.Time
+	Result      string
+
+	// Only match events recorded after the event with this ID
+	AfterID uint
+
+	// List events oldest first instead of newest first
+	OldestFirst bool
+
+	Limit int
+}
+
+// auditEventLockKey identifies the PostgreSQL advisory lock serializing audit event inserts
+const auditEventLockKey = 0x766f6572
+
+// CreateAuditEvent appends an event to the audit log. Change feeds read events after the last ID they have seen, so
+// event IDs must become visible in order. PostgreSQL hands out IDs before transactions commit, so each insert takes a
+// lock held until its transaction commits, and the next event only gets an ID once the previous one is visible.
+// SQLite allows a single writer at a time, which already commits events in order.
+func CreateAuditEvent(db *gorm.DB, event *AuditEvent) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditEventLockKey).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEvents lists events matching a filter, newest first unless the filter asks for the oldest first
+func ListAuditEvents(db *gorm.DB, filter AuditEventFilter) ([]AuditEvent, error) {
+	var events []AuditEvent
+
+	query := db.Model(&AuditEvent{})
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.PackageName != "" {
+		query = query.Where("package_name = ?", filter.PackageName)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	if filter.Result != "" {
+		query = query.Where("result = ?", filter.Result)
+	}
+	if filter.AfterID > 0 {
+		query = query.Where("id > ?", filter.AfterID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	order := "created_at DESC, id DESC"
+	if filter.OldestFirst {
+		order = "id ASC"
+	}
+
+	if err := query.Order(order).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Blob is the database model for the contents of a file, addressed by their digest.
+// Files with identical contents share a single blob across package versions.
+type Blob struct {
+	// "sha256:" followed by the hex SHA-256 of the contents
+	Digest    string    `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	Size     int64  `gorm:"not null"`
+	Contents string `gorm:"not null"`
+}
+
+// PutBlob stores contents under a digest unless a blob with that digest already exists
+func PutBlob(db *gorm.DB, digest, contents string) error {
+	blob := Blob{Digest: digest, Size: int64(len(contents)), Contents: contents}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&blob).Error; err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+	return nil
+}
+
+// DeleteOrphanedBlobs deletes blobs no longer referenced by any file
+func DeleteOrphanedBlobs(db *gorm.DB) error {
+	err := db.Where("NOT EXISTS (SELECT 1 FROM package_version_files WHERE package_version_files.digest = blobs.digest)").
+		Delete(&Blob{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete orphaned blobs: %w", err)
+	}
+	return nil
+}
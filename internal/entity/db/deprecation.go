@@ -0,0 +1,69 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Deprecation marks a package version or message as deprecated. Embedded in the models that can be deprecated.
+type Deprecation struct {
+	DeprecatedAt *time.Time
+
+	// Why the entity is deprecated
+	DeprecationReason string
+
+	// What to use instead, e.g. a newer package version or message
+	DeprecationReplacement string
+}
+
+// IsDeprecated returns true when the entity is deprecated
+func (d Deprecation) IsDeprecated() bool {
+	return d.DeprecatedAt != nil
+}
+
+// deprecationColumns returns the columns to update when setting a deprecation. A nil deprecation clears it.
+func deprecationColumns(deprecation *Deprecation) map[string]interface{} {
+	if deprecation == nil {
+		return map[string]interface{}{
+			"deprecated_at":           nil,
+			"deprecation_reason":      "",
+			"deprecation_replacement": "",
+		}
+	}
+
+	return map[string]interface{}{
+		"deprecated_at":           deprecation.DeprecatedAt,
+		"deprecation_reason":      deprecation.DeprecationReason,
+		"deprecation_replacement": deprecation.DeprecationReplacement,
+	}
+}
+
+// SetPackageVersionDeprecation deprecates a package version, or clears its deprecation when deprecation is nil
+func SetPackageVersionDeprecation(db *gorm.DB, packageVersionID uint, deprecation *Deprecation) error {
+	result := db.Model(&PackageVersion{}).Where("id = ?", packageVersionID).Updates(deprecationColumns(deprecation))
+	if result.Error != nil {
+		return fmt.Errorf("failed to update package version deprecation: %w", result.Error)
+	}
+	return nil
+}
+
+// SetMessageDeprecation deprecates a message, or clears its deprecation when deprecation is nil
+func SetMessageDeprecation(db *gorm.DB, messageID uint, deprecation *Deprecation) error {
+	result := db.Model(&Message{}).Where("id = ?", messageID).Updates(deprecationColumns(deprecation))
+	if result.Error != nil {
+		return fmt.Errorf("failed to update message deprecation: %w", result.Error)
+	}
+	return nil
+}
+
+// ListDeprecatedMessages lists the deprecated messages of a package
+func ListDeprecatedMessages(db *gorm.DB, packageID uint) ([]Message, error) {
+	var messages []Message
+	err := db.Model(&Message{}).Where("package_id = ? AND deprecated_at IS NOT NULL AND latest_version_id IS NOT NULL", packageID).Order("name").Find(&messages).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deprecated messages: %w", err)
+	}
+	return messages, nil
+}
@@ -16,17 +16,20 @@ type Message struct {
 	Package   Package `gorm:"constraint:OnDelete:CASCADE,references:PackageID"`
 	Name      string  `gorm:"not null,index:idx_msg_package,uniqueIndex:message_name_unique"`
 
-	LatestVersionID *uint           `gorm:"not null,index"`
+	// Unset when every version of the message belongs to a deleted package version, which hides the message
+	LatestVersionID *uint           `gorm:"index"`
 	LatestVersion   *MessageVersion `gorm:"constraint:OnDelete:SET NULL,references:LatestVersionID"`
 
 	ProtoBody string `gorm:"not null"`
+
+	Deprecation `gorm:"embedded"`
 }
 
 // ListMessages lists messages from the database
 func ListMessages(db *gorm.DB, limit, offset int, searchTerm string) ([]Message, error) {
 	var messages []Message
 
-	query := db.Model(&Message{}).Preload("LatestVersion").Preload("Package")
+	query := db.Model(&Message{}).Preload("LatestVersion").Preload("Package").Where("latest_version_id IS NOT NULL")
 
 	// If search term is provided, filter messages by name
 	if searchTerm != "" {
@@ -49,7 +52,7 @@ func ListMessages(db *gorm.DB, limit, offset int, searchTerm string) ([]Message,
 func CountMessages(db *gorm.DB, searchTerm string) (int64, error) {
 	var count int64
 
-	query := db.Model(&Message{})
+	query := db.Model(&Message{}).Where("latest_version_id IS NOT NULL")
 
 	if searchTerm != "" {
 		query = query.Where("name LIKE ?", "%"+searchTerm+"%")
@@ -66,7 +69,7 @@ func CountMessages(db *gorm.DB, searchTerm string) (int64, error) {
 func CountMessagesByPackage(db *gorm.DB, packageID uint) (int64, error) {
 	var count int64
 
-	query := db.Model(&Message{}).Where("package_id = ?", packageID)
+	query := db.Model(&Message{}).Where("package_id = ? AND latest_version_id IS NOT NULL", packageID)
 
 	err := query.Count(&count).Error
 	if err != nil {
@@ -76,7 +79,54 @@ func CountMessagesByPackage(db *gorm.DB, packageID uint) (int64, error) {
 	return count, nil
 }
 
-// AssignLatestVersion will find all the messages for a given package and assign the latest version to the message
+// ListPackageMessages lists the messages of a package with their latest versions, including hidden messages
+func ListPackageMessages(db *gorm.DB, packageID uint) ([]Message, error) {
+	var messages []Message
+	if err := db.Model(&Message{}).Preload("LatestVersion").Where("package_id = ?", packageID).Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to list package messages: %w", err)
+	}
+	return messages, nil
+}
+
+// FindMessageByName fetches a message of a package and its latest version by name. Returns nil if no such message exists.
+func FindMessageByName(db *gorm.DB, packageID uint, name string) (*Message, error) {
+	var messages []Message
+	err := db.Model(&Message{}).Preload("LatestVersion").Where("package_id = ? AND name = ?", packageID, name).Limit(1).Find(&messages).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find message: %w", err)
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	return &messages[0], nil
+}
+
+// FindOrCreateMessage fetches a message of a package by name, creating it with the given body when it does not exist
+func FindOrCreateMessage(db *gorm.DB, packageID uint, name, protoBody string) (*Message, error) {
+	message := Message{PackageID: packageID, Name: name, ProtoBody: protoBody}
+	if err := db.Where(Message{PackageID: packageID, Name: name}).FirstOrCreate(&message).Error; err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+	return &message, nil
+}
+
+// SetMessageLatestVersion points a message at one of its versions and copies the version's body
+func SetMessageLatestVersion(db *gorm.DB, messageID uint, version *MessageVersion) error {
+	err := db.Model(&Message{}).Where("id = ?", messageID).Updates(map[string]any{
+		"latest_version_id": version.ID,
+		"proto_body":        version.ProtoBody,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save message: %w", err)
+	}
+	return nil
+}
+
+// AssignLatestVersion will find all the messages for a given package and assign the highest version to the message.
+// Only versions contained in a package version that is not deleted count, including package versions that left the
+// message unchanged, and messages without such a version are unassigned.
 func AssignLatestVersion(db *gorm.DB, packageID uint) error {
 	// Fetch all messages for the package
 	var messages []Message
@@ -88,19 +138,28 @@ func AssignLatestVersion(db *gorm.DB, packageID uint) error {
 	for _, message := range messages {
 		// Fetch the latest version for the message
 		var msgVersions []MessageVersion
-		err = db.Model(&MessageVersion{}).Where("message_id = ?", message.ID).Find(&msgVersions).Error
+		err = db.Model(&MessageVersion{}).
+			Where("message_versions.message_id = ?", message.ID).
+			Where("EXISTS (?)", db.Model(&PackageVersionMessage{}).
+				Select("1").
+				Joins("JOIN package_versions ON package_versions.id = package_version_messages.package_version_id").
+				Where("package_version_messages.message_version_id = message_versions.id AND package_versions.deleted_at IS NULL")).
+			Order("message_versions.version DESC").
+			Limit(1).
+			Find(&msgVersions).Error
 		if err != nil {
 			return fmt.Errorf("failed to assign latest version: %w", err)
 		}
 
+		// Messages without remaining versions keep their last body
+		updates := map[string]any{"latest_version_id": nil}
 		if len(msgVersions) > 0 {
-			message.LatestVersionID = &msgVersions[0].ID
-		} else {
-			message.LatestVersionID = nil
+			updates["latest_version_id"] = msgVersions[0].ID
+			updates["proto_body"] = msgVersions[0].ProtoBody
 		}
 
 		// Save the message
-		err = db.Save(&message).Error
+		err = db.Model(&Message{}).Where("id = ?", message.ID).Updates(updates).Error
 		if err != nil {
 			return fmt.Errorf("failed to assign latest version: %w", err)
 		}
@@ -108,3 +167,18 @@ func AssignLatestVersion(db *gorm.DB, packageID uint) error {
 
 	return nil
 }
+
+// DeleteOrphanedMessages deletes the messages of a package that no longer appear in any of its package versions
+func DeleteOrphanedMessages(db *gorm.DB, packageID uint) error {
+	linked := db.Model(&PackageVersionMessage{}).
+		Select("1").
+		Joins("JOIN message_versions ON message_versions.id = package_version_messages.message_version_id").
+		Where("message_versions.message_id = messages.id")
+
+	err := db.Where("package_id = ? AND NOT EXISTS (?)", packageID, linked).Delete(&Message{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete orphaned messages: %w", err)
+	}
+
+	return nil
+}
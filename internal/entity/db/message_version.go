@@ -1,6 +1,7 @@
 package db
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -21,6 +22,17 @@ type MessageVersion struct {
 
 	ProtoBody        string `gorm:"not null"`
 	SerializedSchema string `gorm:"not null"`
+
+	// Canonical hash of the serialized schema, used to skip unchanged messages
+	ContentHash string `gorm:"not null"`
+}
+
+// CreateMessageVersion creates a message version and links it to the package version introducing it
+func CreateMessageVersion(db *gorm.DB, version *MessageVersion) error {
+	if err := db.Create(version).Error; err != nil {
+		return fmt.Errorf("failed to create message version: %w", err)
+	}
+	return LinkMessageVersion(db, version.PackageVersionID, version.ID)
 }
 
 // GetNextMessageVersion returns the next message version for a given message ID
@@ -39,3 +51,12 @@ func GetNextMessageVersion(db *gorm.DB, messageID uint) (int, error) {
 
 	return latestVersion.Version + 1, nil
 }
+
+// ListMessageVersions lists the versions of a message, oldest first
+func ListMessageVersions(db *gorm.DB, messageID uint) ([]MessageVersion, error) {
+	var messageVersions []MessageVersion
+	if err := db.Where("message_id = ?", messageID).Order("version ASC").Find(&messageVersions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list message versions: %w", err)
+	}
+	return messageVersions, nil
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MirrorCursor records how far a mirror has replicated the changes of its upstream registry
+type MirrorCursor struct {
+	// Endpoint of the upstream registry
+	Upstream  string    `gorm:"primaryKey"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	// ID of the last upstream change applied to the mirror
+	ChangeID uint `gorm:"not null"`
+}
+
+// GetMirrorCursor fetches the cursor of an upstream. Returns nil if nothing was replicated from the upstream yet.
+func GetMirrorCursor(db *gorm.DB, upstream string) (*MirrorCursor, error) {
+	var cursors []MirrorCursor
+	if err := db.Where("upstream = ?", upstream).Limit(1).Find(&cursors).Error; err != nil {
+		return nil, fmt.Errorf("failed to get mirror cursor: %w", err)
+	}
+
+	if len(cursors) == 0 {
+		return nil, nil
+	}
+
+	return &cursors[0], nil
+}
+
+// SetMirrorCursor creates or moves the cursor of an upstream
+func SetMirrorCursor(db *gorm.DB, upstream string, changeID uint) error {
+	cursor := MirrorCursor{Upstream: upstream, ChangeID: changeID}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "upstream"}},
+		DoUpdates: clause.AssignmentColumns([]string{"change_id", "updated_at"}),
+	}).Create(&cursor).Error
+	if err != nil {
+		return fmt.Errorf("failed to set mirror cursor: %w", err)
+	}
+	return nil
+}
@@ -54,12 +54,102 @@ func CountPackages(db *gorm.DB, searchTerm string) (int64, error) {
 	return count, nil
 }
 
-func GetPackage(db *gorm.DB, id uint64) (*Package, error) {
-	var pkg Package
-	err := db.Model(&Package{}).Preload("LatestVersion").Where("id = ?", id).First(&pkg).Error
+// FindPackageByName fetches a package and its latest version by name.
+// Returns nil if no package exists with the given name.
+func FindPackageByName(db *gorm.DB, packageName string) (*Package, error) {
+	var packages []Package
+	err := db.Model(&Package{}).Preload("LatestVersion").Where("package_name = ?", packageName).Limit(1).Find(&packages).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find package: %w", err)
+	}
+
+	if len(packages) == 0 {
+		return nil, nil
+	}
+
+	return &packages[0], nil
+}
+
+// GetPackage fetches a package and its latest version by ID. Returns nil if no package exists with the given ID.
+func GetPackage(db *gorm.DB, id uint) (*Package, error) {
+	var packages []Package
+	err := db.Model(&Package{}).Preload("LatestVersion").Where("id = ?", id).Limit(1).Find(&packages).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get package: %w", err)
 	}
 
+	if len(packages) == 0 {
+		return nil, nil
+	}
+
+	return &packages[0], nil
+}
+
+// FindOrCreatePackage fetches a package by name, creating it when it does not exist
+func FindOrCreatePackage(db *gorm.DB, packageName string) (*Package, error) {
+	pkg := Package{PackageName: packageName}
+	if err := db.Where(Package{PackageName: packageName}).FirstOrCreate(&pkg).Error; err != nil {
+		return nil, fmt.Errorf("failed to create package: %w", err)
+	}
 	return &pkg, nil
 }
+
+// SetPackageLatestVersion points a package at one of its versions, or at nothing when packageVersionID is nil
+func SetPackageLatestVersion(db *gorm.DB, packageID uint, packageVersionID *uint) error {
+	if err := db.Model(&Package{}).Where("id = ?", packageID).Update("latest_version_id", packageVersionID).Error; err != nil {
+		return fmt.Errorf("failed to update package latest version: %w", err)
+	}
+	return nil
+}
+
+// RenamePackage changes the name of a package
+func RenamePackage(db *gorm.DB, packageID uint, packageName string) error {
+	if err := db.Model(&Package{}).Where("id = ?", packageID).Update("package_name", packageName).Error; err != nil {
+		return fmt.Errorf("failed to rename package: %w", err)
+	}
+	return nil
+}
+
+// DeletePackage permanently deletes a package with its versions, files, messages and aliases, along with blobs no other
+// file references
+func DeletePackage(db *gorm.DB, packageID uint) error {
+	versionIDs := db.Model(&PackageVersion{}).Select("id").Where("package_id = ?", packageID)
+
+	if err := db.Where("package_version_id IN (?)", versionIDs).Delete(&PackageVersionFile{}).Error; err != nil {
+		return fmt.Errorf("failed to delete package version files: %w", err)
+	}
+
+	if err := db.Where("package_version_id IN (?)", versionIDs).Delete(&PackageVersionMessage{}).Error; err != nil {
+		return fmt.Errorf("failed to delete message version links: %w", err)
+	}
+
+	if err := db.Where("package_version_id IN (?)", versionIDs).Delete(&MessageVersion{}).Error; err != nil {
+		return fmt.Errorf("failed to delete message versions: %w", err)
+	}
+
+	if err := db.Where("package_id = ?", packageID).Delete(&Message{}).Error; err != nil {
+		return fmt.Errorf("failed to delete messages: %w", err)
+	}
+
+	if err := db.Model(&Package{}).Where("id = ?", packageID).Update("latest_version_id", nil).Error; err != nil {
+		return fmt.Errorf("failed to update package latest version: %w", err)
+	}
+
+	if err := db.Where("package_id = ?", packageID).Delete(&PackageVersion{}).Error; err != nil {
+		return fmt.Errorf("failed to delete package versions: %w", err)
+	}
+
+	if err := db.Where("package_id = ?", packageID).Delete(&PackageAlias{}).Error; err != nil {
+		return fmt.Errorf("failed to delete package aliases: %w", err)
+	}
+
+	if err := db.Delete(&Package{}, packageID).Error; err != nil {
+		return fmt.Errorf("failed to delete package: %w", err)
+	}
+
+	if err := DeleteOrphanedBlobs(db); err != nil {
+		return err
+	}
+
+	return nil
+}
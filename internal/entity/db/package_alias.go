@@ -0,0 +1,60 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PackageAlias is a former name of a renamed package, which still resolves to the package
+type PackageAlias struct {
+	ID        uint      `gorm:"primaryKey,autoIncrement"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	AliasName string  `gorm:"not null,unique"`
+	PackageID uint    `gorm:"not null,index"`
+	Package   Package `gorm:"constraint:OnDelete:CASCADE,foreignKey:PackageID,references:ID"`
+}
+
+// FindPackageAlias fetches an alias and the package it points at by name. Returns nil if no such alias exists.
+func FindPackageAlias(db *gorm.DB, aliasName string) (*PackageAlias, error) {
+	var aliases []PackageAlias
+	err := db.Model(&PackageAlias{}).Preload("Package").Preload("Package.LatestVersion").Where("alias_name = ?", aliasName).Limit(1).Find(&aliases).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find package alias: %w", err)
+	}
+
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+
+	return &aliases[0], nil
+}
+
+// ListPackageAliases lists the former names of a package, oldest first
+func ListPackageAliases(db *gorm.DB, packageID uint) ([]PackageAlias, error) {
+	var aliases []PackageAlias
+	err := db.Model(&PackageAlias{}).Where("package_id = ?", packageID).Order("id").Find(&aliases).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list package aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// CreatePackageAlias points a former name at a package
+func CreatePackageAlias(db *gorm.DB, aliasName string, packageID uint) error {
+	if err := db.Create(&PackageAlias{AliasName: aliasName, PackageID: packageID}).Error; err != nil {
+		return fmt.Errorf("failed to create package alias: %w", err)
+	}
+	return nil
+}
+
+// DeletePackageAlias removes an alias by name
+func DeletePackageAlias(db *gorm.DB, aliasName string) error {
+	if err := db.Where("alias_name = ?", aliasName).Delete(&PackageAlias{}).Error; err != nil {
+		return fmt.Errorf("failed to delete package alias: %w", err)
+	}
+	return nil
+}
@@ -16,6 +16,14 @@ type PackageVersion struct {
 	PackageID uint `gorm:"not null,index,uniqueIndex:package_version_number_unique"`
 	Version   int  `gorm:"not null,uniqueIndex:package_version_number_unique"`
 
+	// Canonical hash of the package version's files, used to skip no-op uploads
+	ContentHash string `gorm:"not null"`
+
+	// Set when the version is soft deleted. Deleted versions are purged after the retention period.
+	DeletedAt *time.Time `gorm:"index"`
+
+	Deprecation `gorm:"embedded"`
+
 	Package Package              `gorm:"constraint:OnDelete:CASCADE,foreignKey:PackageID,references:ID"`
 	Files   []PackageVersionFile `gorm:"constraint:OnDelete:CASCADE,foreignKey:PackageVersionID,references:ID"`
 
@@ -39,9 +47,28 @@ func GetNextPackageVersion(db *gorm.DB, packageID uint) (int, error) {
 	return latestVersion.Version + 1, nil
 }
 
-func ListPackageVersions(db *gorm.DB, packageID uint) ([]PackageVersion, error) {
+// CountPackageVersions counts the package versions of every package that are not deleted
+func CountPackageVersions(db *gorm.DB) (int64, error) {
+	var count int64
+
+	err := db.Model(&PackageVersion{}).Where("deleted_at IS NULL").Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count package versions: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListPackageVersions lists the versions of a package, newest first. Deleted versions are only included when includeDeleted is set.
+func ListPackageVersions(db *gorm.DB, packageID uint, includeDeleted bool) ([]PackageVersion, error) {
 	var pkgVersions []PackageVersion
-	result := db.Where("package_id = ?", packageID).Order("version DESC").Find(&pkgVersions)
+
+	query := db.Where("package_id = ?", packageID)
+	if !includeDeleted {
+		query = query.Where("deleted_at IS NULL")
+	}
+
+	result := query.Order("version DESC").Find(&pkgVersions)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -49,45 +76,125 @@ func ListPackageVersions(db *gorm.DB, packageID uint) ([]PackageVersion, error)
 	return pkgVersions, nil
 }
 
-func DeletePackageVersion(db *gorm.DB, packageVersionID uint) error {
-	// Get the package version
-	var packageVersion PackageVersion
-	result := db.First(&packageVersion, packageVersionID)
-	if result.Error != nil {
-		return fmt.Errorf("failed to get package version: %w", result.Error)
+func CreatePackageVersion(db *gorm.DB, pkgVersion *PackageVersion) error {
+	if err := db.Create(pkgVersion).Error; err != nil {
+		return fmt.Errorf("failed to create package version: %w", err)
 	}
+	return nil
+}
 
-	// Get related package
-	var pkg Package
-	result = db.First(&pkg, packageVersion.PackageID)
-	if result.Error != nil {
-		return fmt.Errorf("failed to get package: %w", result.Error)
+// GetPackageVersion fetches a package version and its package by ID. Returns nil if no version exists with the given ID.
+func GetPackageVersion(db *gorm.DB, id uint) (*PackageVersion, error) {
+	var pkgVersions []PackageVersion
+	err := db.Model(&PackageVersion{}).Preload("Package").Where("id = ?", id).Limit(1).Find(&pkgVersions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get package version: %w", err)
+	}
+
+	if len(pkgVersions) == 0 {
+		return nil, nil
 	}
 
-	result = db.Delete(&packageVersion, packageVersionID)
+	return &pkgVersions[0], nil
+}
+
+// FindPackageVersion fetches a version of a package by number. Returns nil if no such version exists.
+func FindPackageVersion(db *gorm.DB, packageID uint, version int) (*PackageVersion, error) {
+	var pkgVersions []PackageVersion
+	err := db.Model(&PackageVersion{}).Where("package_id = ? AND version = ?", packageID, version).Limit(1).Find(&pkgVersions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find package version: %w", err)
+	}
+
+	if len(pkgVersions) == 0 {
+		return nil, nil
+	}
+
+	return &pkgVersions[0], nil
+}
+
+// SoftDeletePackageVersion marks a package version as deleted
+func SoftDeletePackageVersion(db *gorm.DB, packageVersionID uint, deletedAt time.Time) error {
+	result := db.Model(&PackageVersion{}).Where("id = ?", packageVersionID).Update("deleted_at", deletedAt)
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete package version: %w", result.Error)
 	}
+	return nil
+}
+
+// RestorePackageVersion clears the deletion mark of a package version
+func RestorePackageVersion(db *gorm.DB, packageVersionID uint) error {
+	result := db.Model(&PackageVersion{}).Where("id = ?", packageVersionID).Update("deleted_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to restore package version: %w", result.Error)
+	}
+	return nil
+}
 
-	// Update the package's latest version
+// ListPurgeablePackageVersions lists versions deleted before a given time, along with their package
+func ListPurgeablePackageVersions(db *gorm.DB, deletedBefore time.Time) ([]PackageVersion, error) {
 	var pkgVersions []PackageVersion
-	result = db.Where("package_id = ?", packageVersion.PackageID).Order("version DESC").Limit(1).Find(&pkgVersions)
-	if result.Error != nil {
-		return fmt.Errorf("failed to get latest package version: %w", result.Error)
+	err := db.Model(&PackageVersion{}).Preload("Package").Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).Find(&pkgVersions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purgeable package versions: %w", err)
 	}
 
-	var latestVersion *PackageVersion
-	if len(pkgVersions) > 0 {
-		latestVersion = &pkgVersions[0]
+	return pkgVersions, nil
+}
+
+// PurgePackageVersion permanently deletes a package version with its files and message versions, along with blobs
+// no other file references. Message versions that later package versions left unchanged are kept, and move to the
+// oldest package version still containing them.
+func PurgePackageVersion(db *gorm.DB, packageVersionID uint) error {
+	if err := db.Where("package_version_id = ?", packageVersionID).Delete(&PackageVersionFile{}).Error; err != nil {
+		return fmt.Errorf("failed to purge package version files: %w", err)
+	}
+
+	if err := db.Where("package_version_id = ?", packageVersionID).Delete(&PackageVersionMessage{}).Error; err != nil {
+		return fmt.Errorf("failed to purge message version links: %w", err)
+	}
+
+	oldestLink := db.Model(&PackageVersionMessage{}).
+		Select("package_version_messages.package_version_id").
+		Joins("JOIN package_versions ON package_versions.id = package_version_messages.package_version_id").
+		Where("package_version_messages.message_version_id = message_versions.id").
+		Order("package_versions.version ASC").
+		Limit(1)
+	err := db.Model(&MessageVersion{}).
+		Where("package_version_id = ? AND EXISTS (?)", packageVersionID, oldestLink).
+		Update("package_version_id", oldestLink).Error
+	if err != nil {
+		return fmt.Errorf("failed to keep shared message versions: %w", err)
+	}
+
+	if err := db.Where("package_version_id = ?", packageVersionID).Delete(&MessageVersion{}).Error; err != nil {
+		return fmt.Errorf("failed to purge message versions: %w", err)
+	}
+
+	if err := db.Delete(&PackageVersion{}, packageVersionID).Error; err != nil {
+		return fmt.Errorf("failed to purge package version: %w", err)
 	}
 
-	if latestVersion != nil {
-		pkg.LatestVersionID = &latestVersion.ID
-		result = db.Save(&pkg)
-		if result.Error != nil {
-			return fmt.Errorf("failed to update package latest version: %w", result.Error)
-		}
+	if err := DeleteOrphanedBlobs(db); err != nil {
+		return err
 	}
 
 	return nil
 }
+
+// AssignLatestPackageVersion points a package at its newest version that is not deleted, or at nothing when every
+// version is deleted
+func AssignLatestPackageVersion(db *gorm.DB, packageID uint) error {
+	var pkgVersions []PackageVersion
+	result := db.Where("package_id = ? AND deleted_at IS NULL", packageID).Order("version DESC").Limit(1).Find(&pkgVersions)
+	if result.Error != nil {
+		return fmt.Errorf("failed to get latest package version: %w", result.Error)
+	}
+
+	var latestVersionID *uint
+	if len(pkgVersions) > 0 {
+		latestVersionID = &pkgVersions[0].ID
+	}
+
+	return SetPackageLatestVersion(db, packageID, latestVersionID)
+}
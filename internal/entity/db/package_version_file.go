@@ -1,6 +1,7 @@
 package db
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -15,13 +16,66 @@ type PackageVersionFile struct {
 	PackageVersionID uint           `gorm:"not null,index"`
 	PackageVersion   PackageVersion `gorm:"constraint:OnDelete:CASCADE,foreignKey:PackageVersionID,references:ID"`
 
-	FileName     string `gorm:"not null"`
-	FileContents string `gorm:"not null"`
+	FileName string `gorm:"not null"`
+
+	// Digest of the blob holding the file's contents
+	Digest string `gorm:"not null,index"`
+
+	// Contents of the file's blob. Filled in when files are read, and stored as a blob when a file is created.
+	FileContents string `gorm:"->"`
+
+	// Path the file was compiled under, relative to its import root (e.g. "helloworld/v1/request.proto")
+	ImportPath string `gorm:"not null,index"`
+}
+
+// withContents selects the contents of each file from its blob
+func withContents(query *gorm.DB) *gorm.DB {
+	return query.
+		Select("package_version_files.*, blobs.contents AS file_contents").
+		Joins("JOIN blobs ON blobs.digest = package_version_files.digest")
+}
+
+// FindLatestFileByImportPath finds a file by import path among the latest versions of all packages.
+// Returns nil if no package's latest version contains the file.
+func FindLatestFileByImportPath(db *gorm.DB, importPath string) (*PackageVersionFile, error) {
+	var files []PackageVersionFile
+	err := withContents(db.Model(&PackageVersionFile{})).
+		Preload("PackageVersion.Package").
+		Joins("JOIN packages ON packages.latest_version_id = package_version_files.package_version_id").
+		Where("package_version_files.import_path = ?", importPath).
+		Order("package_version_files.id DESC").
+		Limit(1).
+		Find(&files).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find file by import path: %w", err)
+	}
+
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	return &files[0], nil
+}
+
+// CreatePackageVersionFile stores a file's contents as a blob under its digest, then creates the file
+func CreatePackageVersionFile(db *gorm.DB, file *PackageVersionFile) error {
+	if file.Digest == "" {
+		return fmt.Errorf("failed to create package version file: %s has no digest", file.FileName)
+	}
+
+	if err := PutBlob(db, file.Digest, file.FileContents); err != nil {
+		return err
+	}
+
+	if err := db.Create(file).Error; err != nil {
+		return fmt.Errorf("failed to create package version file: %w", err)
+	}
+	return nil
 }
 
 func ListPackageVersionFiles(db *gorm.DB, packageVersionID uint) ([]PackageVersionFile, error) {
 	var files []PackageVersionFile
-	if err := db.Where("package_version_id = ?", packageVersionID).Find(&files).Error; err != nil {
+	if err := withContents(db.Model(&PackageVersionFile{})).Where("package_version_id = ?", packageVersionID).Find(&files).Error; err != nil {
 		return nil, err
 	}
 	return files, nil
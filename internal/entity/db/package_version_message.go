@@ -0,0 +1,45 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PackageVersionMessage links a package version to a version of one of its messages. Every package version is linked
+// to the versions of all the messages it contains, including messages left unchanged since an earlier package version,
+// which share the earlier version instead of storing their schema again.
+type PackageVersionMessage struct {
+	ID        uint      `gorm:"primaryKey,autoIncrement"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	PackageVersionID uint           `gorm:"not null,uniqueIndex:package_version_message_unique"`
+	PackageVersion   PackageVersion `gorm:"constraint:OnDelete:CASCADE,foreignKey:PackageVersionID,references:ID"`
+
+	MessageVersionID uint           `gorm:"not null,index,uniqueIndex:package_version_message_unique"`
+	MessageVersion   MessageVersion `gorm:"constraint:OnDelete:CASCADE,foreignKey:MessageVersionID,references:ID"`
+}
+
+// LinkMessageVersion records that a package version contains a message version
+func LinkMessageVersion(db *gorm.DB, packageVersionID, messageVersionID uint) error {
+	link := PackageVersionMessage{PackageVersionID: packageVersionID, MessageVersionID: messageVersionID}
+	if err := db.Create(&link).Error; err != nil {
+		return fmt.Errorf("failed to link message version: %w", err)
+	}
+	return nil
+}
+
+// ListMessageVersionLinks lists the links of every version of a message to the package versions containing it
+func ListMessageVersionLinks(db *gorm.DB, messageID uint) ([]PackageVersionMessage, error) {
+	var links []PackageVersionMessage
+	err := db.Model(&PackageVersionMessage{}).
+		Joins("JOIN message_versions ON message_versions.id = package_version_messages.message_version_id").
+		Where("message_versions.message_id = ?", messageID).
+		Order("package_version_messages.id ASC").
+		Find(&links).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message version links: %w", err)
+	}
+	return links, nil
+}
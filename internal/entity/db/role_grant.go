@@ -0,0 +1,78 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoleGrant is the database model for a role granted to a subject on a package name pattern
+type RoleGrant struct {
+	ID        uint      `gorm:"primaryKey,autoIncrement"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	Subject        string `gorm:"not null,index,uniqueIndex:role_grant_unique"`
+	Role           string `gorm:"not null,uniqueIndex:role_grant_unique"`
+	PackagePattern string `gorm:"not null,uniqueIndex:role_grant_unique"`
+}
+
+func CreateRoleGrant(db *gorm.DB, grant *RoleGrant) error {
+	if err := db.Create(grant).Error; err != nil {
+		return fmt.Errorf("failed to create role grant: %w", err)
+	}
+	return nil
+}
+
+// GetRoleGrant fetches a grant by ID. Returns nil if no grant exists with the given ID.
+func GetRoleGrant(db *gorm.DB, id uint) (*RoleGrant, error) {
+	var grants []RoleGrant
+	if err := db.Model(&RoleGrant{}).Where("id = ?", id).Limit(1).Find(&grants).Error; err != nil {
+		return nil, fmt.Errorf("failed to get role grant: %w", err)
+	}
+
+	if len(grants) == 0 {
+		return nil, nil
+	}
+
+	return &grants[0], nil
+}
+
+// FindRoleGrant fetches a grant by its subject, role and pattern. Returns nil if no such grant exists.
+func FindRoleGrant(db *gorm.DB, subject, role, packagePattern string) (*RoleGrant, error) {
+	var grants []RoleGrant
+	err := db.Model(&RoleGrant{}).Where("subject = ? AND role = ? AND package_pattern = ?", subject, role, packagePattern).Limit(1).Find(&grants).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find role grant: %w", err)
+	}
+
+	if len(grants) == 0 {
+		return nil, nil
+	}
+
+	return &grants[0], nil
+}
+
+// ListRoleGrants lists grants ordered by subject. All grants are listed when subject is empty.
+func ListRoleGrants(db *gorm.DB, subject string) ([]RoleGrant, error) {
+	var grants []RoleGrant
+
+	query := db.Model(&RoleGrant{})
+	if subject != "" {
+		query = query.Where("subject = ?", subject)
+	}
+
+	if err := query.Order("subject, package_pattern, role").Find(&grants).Error; err != nil {
+		return nil, fmt.Errorf("failed to list role grants: %w", err)
+	}
+
+	return grants, nil
+}
+
+func DeleteRoleGrant(db *gorm.DB, id uint) error {
+	if err := db.Delete(&RoleGrant{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete role grant: %w", err)
+	}
+	return nil
+}
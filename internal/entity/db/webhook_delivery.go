@@ -0,0 +1,73 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookDelivery is the database model for an attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID        uint      `gorm:"primaryKey,autoIncrement"`
+	CreatedAt time.Time `gorm:"autoCreateTime,index"`
+
+	// Name of the webhook in the webhook configuration
+	Webhook string `gorm:"not null,index"`
+	URL     string `gorm:"not null"`
+
+	EventID     string `gorm:"not null,index"`
+	EventType   string `gorm:"not null"`
+	PackageName string
+	Version     uint
+
+	// Attempts are numbered from 1
+	Attempt int `gorm:"not null"`
+
+	// HTTP status returned by the receiver, 0 when no response was received
+	StatusCode int
+	DurationMS int64 `gorm:"column:duration_ms"`
+
+	Result string `gorm:"not null"`
+	Error  string
+}
+
+// WebhookDeliveryFilter narrows the deliveries returned by ListWebhookDeliveries. Zero values match everything.
+type WebhookDeliveryFilter struct {
+	Webhook string
+	EventID string
+	Result  string
+	Limit   int
+}
+
+func CreateWebhookDelivery(db *gorm.DB, delivery *WebhookDelivery) error {
+	if err := db.Create(delivery).Error; err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries lists deliveries matching a filter, newest first
+func ListWebhookDeliveries(db *gorm.DB, filter WebhookDeliveryFilter) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+
+	query := db.Model(&WebhookDelivery{})
+	if filter.Webhook != "" {
+		query = query.Where("webhook = ?", filter.Webhook)
+	}
+	if filter.EventID != "" {
+		query = query.Where("event_id = ?", filter.EventID)
+	}
+	if filter.Result != "" {
+		query = query.Where("result = ?", filter.Result)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	if err := query.Order("id DESC").Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
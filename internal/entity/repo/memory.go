@@ -0,0 +1,1142 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	entity "github.com/cgund98/voer/internal/entity/db"
+)
+
+// MemoryStore keeps entities in memory. It is meant for tests and mirrors the behaviour of SQLStore, including
+// rolling back failed transactions.
+type MemoryStore struct {
+	mu   *sync.Mutex
+	data *memoryData
+
+	// Set on stores handed to a transaction, which already holds the lock
+	inTx bool
+}
+
+// memoryData holds entities without their relations, which are filled in when entities are read
+type memoryData struct {
+	lastID uint
+
+	packages        map[uint]entity.Package
+	aliases         map[uint]entity.PackageAlias
+	pkgVersions     map[uint]entity.PackageVersion
+	files           map[uint]entity.PackageVersionFile
+	blobs           map[string]string
+	messages        map[uint]entity.Message
+	messageVersions map[uint]entity.MessageVersion
+	messageLinks    map[uint]entity.PackageVersionMessage
+	auditEvents     []entity.AuditEvent
+	roleGrants      map[uint]entity.RoleGrant
+	apiTokens       map[uint]entity.APIToken
+	mirrorCursors   map[string]entity.MirrorCursor
+	deliveries      []entity.WebhookDelivery
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mu: &sync.Mutex{},
+		data: &memoryData{
+			packages:        map[uint]entity.Package{},
+			aliases:         map[uint]entity.PackageAlias{},
+			pkgVersions:     map[uint]entity.PackageVersion{},
+			files:           map[uint]entity.PackageVersionFile{},
+			blobs:           map[string]string{},
+			messages:        map[uint]entity.Message{},
+			messageVersions: map[uint]entity.MessageVersion{},
+			messageLinks:    map[uint]entity.PackageVersionMessage{},
+			roleGrants:      map[uint]entity.RoleGrant{},
+			apiTokens:       map[uint]entity.APIToken{},
+			mirrorCursors:   map[string]entity.MirrorCursor{},
+		},
+	}
+}
+
+func (s *MemoryStore) Packages() PackageRepository               { return memoryPackages{s} }
+func (s *MemoryStore) PackageVersions() PackageVersionRepository { return memoryPackageVersions{s} }
+func (s *MemoryStore) Files() FileRepository                     { return memoryFiles{s} }
+func (s *MemoryStore) Messages() MessageRepository               { return memoryMessages{s} }
+func (s *MemoryStore) AuditEvents() AuditEventRepository         { return memoryAuditEvents{s} }
+func (s *MemoryStore) RoleGrants() RoleGrantRepository           { return memoryRoleGrants{s} }
+func (s *MemoryStore) APITokens() APITokenRepository             { return memoryAPITokens{s} }
+func (s *MemoryStore) MirrorCursors() MirrorCursorRepository     { return memoryMirrorCursors{s} }
+func (s *MemoryStore) WebhookDeliveries() WebhookDeliveryRepository {
+	return memoryWebhookDeliveries{s}
+}
+
+// Transaction runs fn against a copy of the data, which replaces the data only when fn succeeds.
+// Transactions are serialized.
+func (s *MemoryStore) Transaction(fn func(tx Store) error) error {
+	defer s.lock()()
+
+	data := s.data.clone()
+	if err := fn(&MemoryStore{mu: s.mu, data: data, inTx: true}); err != nil {
+		return err
+	}
+
+	*s.data = *data
+	return nil
+}
+
+// WithContext returns the store itself, as in-memory queries are not traced
+func (s *MemoryStore) WithContext(ctx context.Context) Store {
+	return s
+}
+
+// lock acquires the store's lock and returns the function releasing it
+func (s *MemoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		lastID:          d.lastID,
+		packages:        maps.Clone(d.packages),
+		aliases:         maps.Clone(d.aliases),
+		pkgVersions:     maps.Clone(d.pkgVersions),
+		files:           maps.Clone(d.files),
+		blobs:           maps.Clone(d.blobs),
+		messages:        maps.Clone(d.messages),
+		messageVersions: maps.Clone(d.messageVersions),
+		messageLinks:    maps.Clone(d.messageLinks),
+		auditEvents:     slices.Clone(d.auditEvents),
+		roleGrants:      maps.Clone(d.roleGrants),
+		apiTokens:       maps.Clone(d.apiTokens),
+		mirrorCursors:   maps.Clone(d.mirrorCursors),
+		deliveries:      slices.Clone(d.deliveries),
+	}
+}
+
+func (d *memoryData) nextID() uint {
+	d.lastID++
+	return d.lastID
+}
+
+// byID returns the values of a map ordered by ID, matching insertion order
+func byID[T any](m map[uint]T) []T {
+	values := make([]T, 0, len(m))
+	for _, id := range slices.Sorted(maps.Keys(m)) {
+		values = append(values, m[id])
+	}
+	return values
+}
+
+// paginate applies an offset and a limit to a list. The list is not limited when limit is not positive.
+func paginate[T any](values []T, limit, offset int) []T {
+	if offset >= len(values) {
+		return nil
+	}
+	values = values[offset:]
+
+	if limit > 0 && limit < len(values) {
+		values = values[:limit]
+	}
+	return values
+}
+
+// matchesSearch mirrors a case-insensitive LIKE '%term%'
+func matchesSearch(value, searchTerm string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(searchTerm))
+}
+
+// copyID returns a pointer to a copy of an optional ID, so stored entities never share memory with callers
+func copyID(id *uint) *uint {
+	if id == nil {
+		return nil
+	}
+	value := *id
+	return &value
+}
+
+// withLatestVersion fills in the latest version of a package
+func (d *memoryData) withLatestVersion(pkg entity.Package) entity.Package {
+	if pkg.LatestVersionID != nil {
+		if pkgVer, ok := d.pkgVersions[*pkg.LatestVersionID]; ok {
+			pkg.LatestVersion = &pkgVer
+		}
+	}
+	return pkg
+}
+
+// withLatestMessageVersion fills in the latest version of a message
+func (d *memoryData) withLatestMessageVersion(message entity.Message) entity.Message {
+	if message.LatestVersionID != nil {
+		if version, ok := d.messageVersions[*message.LatestVersionID]; ok {
+			message.LatestVersion = &version
+		}
+	}
+	return message
+}
+
+func (d *memoryData) findPackageByName(packageName string) (entity.Package, bool) {
+	for _, pkg := range d.packages {
+		if pkg.PackageName == packageName {
+			return pkg, true
+		}
+	}
+	return entity.Package{}, false
+}
+
+func (d *memoryData) findAlias(aliasName string) (entity.PackageAlias, bool) {
+	for _, alias := range d.aliases {
+		if alias.AliasName == aliasName {
+			return alias, true
+		}
+	}
+	return entity.PackageAlias{}, false
+}
+
+func (d *memoryData) findMessage(packageID uint, name string) (entity.Message, bool) {
+	for _, message := range d.messages {
+		if message.PackageID == packageID && message.Name == name {
+			return message, true
+		}
+	}
+	return entity.Message{}, false
+}
+
+// purgeVersion deletes a package version with its files and message versions. Message versions that later package
+// versions left unchanged are kept, and move to the oldest package version still containing them.
+func (d *memoryData) purgeVersion(packageVersionID uint) {
+	for id, file := range d.files {
+		if file.PackageVersionID == packageVersionID {
+			delete(d.files, id)
+		}
+	}
+	for id, link := range d.messageLinks {
+		if link.PackageVersionID == packageVersionID {
+			delete(d.messageLinks, id)
+		}
+	}
+	for id, version := range d.messageVersions {
+		if version.PackageVersionID != packageVersionID {
+			continue
+		}
+
+		if oldest, ok := d.oldestLinkedVersion(id); ok {
+			version.PackageVersionID = oldest
+			d.messageVersions[id] = version
+		} else {
+			delete(d.messageVersions, id)
+		}
+	}
+	delete(d.pkgVersions, packageVersionID)
+}
+
+// oldestLinkedVersion finds the oldest package version containing a message version
+func (d *memoryData) oldestLinkedVersion(messageVersionID uint) (uint, bool) {
+	var oldest *entity.PackageVersion
+	for _, link := range d.messageLinks {
+		if link.MessageVersionID != messageVersionID {
+			continue
+		}
+		if pkgVer := d.pkgVersions[link.PackageVersionID]; oldest == nil || pkgVer.Version < oldest.Version {
+			oldest = &pkgVer
+		}
+	}
+
+	if oldest == nil {
+		return 0, false
+	}
+	return oldest.ID, true
+}
+
+// containedVersions returns the IDs of the message versions contained in any package version. Deleted package versions
+// only count when includeDeleted is set.
+func (d *memoryData) containedVersions(includeDeleted bool) map[uint]bool {
+	contained := make(map[uint]bool)
+	for _, link := range d.messageLinks {
+		if includeDeleted || d.pkgVersions[link.PackageVersionID].DeletedAt == nil {
+			contained[link.MessageVersionID] = true
+		}
+	}
+	return contained
+}
+
+// deleteOrphanedBlobs deletes blobs no longer referenced by any file
+func (d *memoryData) deleteOrphanedBlobs() {
+	referenced := make(map[string]bool)
+	for _, file := range d.files {
+		referenced[file.Digest] = true
+	}
+
+	for digest := range d.blobs {
+		if !referenced[digest] {
+			delete(d.blobs, digest)
+		}
+	}
+}
+
+// withContents fills in the contents of a file from its blob
+func (d *memoryData) withContents(file entity.PackageVersionFile) entity.PackageVersionFile {
+	file.FileContents = d.blobs[file.Digest]
+	return file
+}
+
+// applyDeprecation sets or clears the deprecation of an entity
+func applyDeprecation(target *entity.Deprecation, deprecation *entity.Deprecation) {
+	if deprecation == nil {
+		*target = entity.Deprecation{}
+		return
+	}
+	*target = *deprecation
+}
+
+type memoryPackages struct{ s *MemoryStore }
+
+func (r memoryPackages) list(searchTerm string) []entity.Package {
+	var packages []entity.Package
+	for _, pkg := range byID(r.s.data.packages) {
+		if matchesSearch(pkg.PackageName, searchTerm) {
+			packages = append(packages, r.s.data.withLatestVersion(pkg))
+		}
+	}
+	return packages
+}
+
+func (r memoryPackages) List(limit, offset int, searchTerm string) ([]entity.Package, error) {
+	defer r.s.lock()()
+	return paginate(r.list(searchTerm), limit, offset), nil
+}
+
+func (r memoryPackages) Count(searchTerm string) (int64, error) {
+	defer r.s.lock()()
+	return int64(len(r.list(searchTerm))), nil
+}
+
+func (r memoryPackages) Get(id uint) (*entity.Package, error) {
+	defer r.s.lock()()
+
+	pkg, ok := r.s.data.packages[id]
+	if !ok {
+		return nil, nil
+	}
+	pkg = r.s.data.withLatestVersion(pkg)
+	return &pkg, nil
+}
+
+func (r memoryPackages) FindByName(packageName string) (*entity.Package, error) {
+	defer r.s.lock()()
+
+	pkg, ok := r.s.data.findPackageByName(packageName)
+	if !ok {
+		return nil, nil
+	}
+	pkg = r.s.data.withLatestVersion(pkg)
+	return &pkg, nil
+}
+
+func (r memoryPackages) FindOrCreate(packageName string) (*entity.Package, error) {
+	defer r.s.lock()()
+
+	if pkg, ok := r.s.data.findPackageByName(packageName); ok {
+		return &pkg, nil
+	}
+
+	now := time.Now()
+	pkg := entity.Package{ID: r.s.data.nextID(), CreatedAt: now, UpdatedAt: now, PackageName: packageName}
+	r.s.data.packages[pkg.ID] = pkg
+	return &pkg, nil
+}
+
+func (r memoryPackages) SetLatestVersion(packageID uint, packageVersionID *uint) error {
+	defer r.s.lock()()
+
+	pkg, ok := r.s.data.packages[packageID]
+	if !ok {
+		return nil
+	}
+	pkg.LatestVersionID = copyID(packageVersionID)
+	pkg.UpdatedAt = time.Now()
+	r.s.data.packages[packageID] = pkg
+	return nil
+}
+
+func (r memoryPackages) AssignLatestVersion(packageID uint) error {
+	defer r.s.lock()()
+
+	pkg, ok := r.s.data.packages[packageID]
+	if !ok {
+		return nil
+	}
+
+	var latest *entity.PackageVersion
+	for _, pkgVer := range r.s.data.pkgVersions {
+		if pkgVer.PackageID == packageID && pkgVer.DeletedAt == nil && (latest == nil || pkgVer.Version > latest.Version) {
+			latest = &pkgVer
+		}
+	}
+
+	pkg.LatestVersionID = nil
+	if latest != nil {
+		pkg.LatestVersionID = copyID(&latest.ID)
+	}
+	pkg.UpdatedAt = time.Now()
+	r.s.data.packages[packageID] = pkg
+	return nil
+}
+
+func (r memoryPackages) Rename(packageID uint, packageName string) error {
+	defer r.s.lock()()
+
+	if existing, ok := r.s.data.findPackageByName(packageName); ok && existing.ID != packageID {
+		return fmt.Errorf("failed to rename package: package %s already exists", packageName)
+	}
+
+	pkg, ok := r.s.data.packages[packageID]
+	if !ok {
+		return nil
+	}
+	pkg.PackageName = packageName
+	pkg.UpdatedAt = time.Now()
+	r.s.data.packages[packageID] = pkg
+	return nil
+}
+
+func (r memoryPackages) Delete(packageID uint) error {
+	defer r.s.lock()()
+
+	for id, pkgVer := range r.s.data.pkgVersions {
+		if pkgVer.PackageID == packageID {
+			r.s.data.purgeVersion(id)
+		}
+	}
+	for id, message := range r.s.data.messages {
+		if message.PackageID == packageID {
+			delete(r.s.data.messages, id)
+		}
+	}
+	for id, alias := range r.s.data.aliases {
+		if alias.PackageID == packageID {
+			delete(r.s.data.aliases, id)
+		}
+	}
+	delete(r.s.data.packages, packageID)
+	r.s.data.deleteOrphanedBlobs()
+	return nil
+}
+
+func (r memoryPackages) FindAlias(aliasName string) (*entity.PackageAlias, error) {
+	defer r.s.lock()()
+
+	alias, ok := r.s.data.findAlias(aliasName)
+	if !ok {
+		return nil, nil
+	}
+	alias.Package = r.s.data.withLatestVersion(r.s.data.packages[alias.PackageID])
+	return &alias, nil
+}
+
+func (r memoryPackages) ListAliases(packageID uint) ([]entity.PackageAlias, error) {
+	defer r.s.lock()()
+
+	var aliases []entity.PackageAlias
+	for _, alias := range byID(r.s.data.aliases) {
+		if alias.PackageID == packageID {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases, nil
+}
+
+func (r memoryPackages) CreateAlias(aliasName string, packageID uint) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.data.findAlias(aliasName); ok {
+		return fmt.Errorf("failed to create package alias: alias %s already exists", aliasName)
+	}
+
+	alias := entity.PackageAlias{ID: r.s.data.nextID(), CreatedAt: time.Now(), AliasName: aliasName, PackageID: packageID}
+	r.s.data.aliases[alias.ID] = alias
+	return nil
+}
+
+func (r memoryPackages) DeleteAlias(aliasName string) error {
+	defer r.s.lock()()
+
+	if alias, ok := r.s.data.findAlias(aliasName); ok {
+		delete(r.s.data.aliases, alias.ID)
+	}
+	return nil
+}
+
+type memoryPackageVersions struct{ s *MemoryStore }
+
+func (r memoryPackageVersions) Create(pkgVersion *entity.PackageVersion) error {
+	defer r.s.lock()()
+
+	for _, existing := range r.s.data.pkgVersions {
+		if existing.PackageID == pkgVersion.PackageID && existing.Version == pkgVersion.Version {
+			return fmt.Errorf("failed to create package version: version %d already exists", pkgVersion.Version)
+		}
+	}
+
+	now := time.Now()
+	pkgVersion.ID = r.s.data.nextID()
+	if pkgVersion.CreatedAt.IsZero() {
+		pkgVersion.CreatedAt = now
+	}
+	pkgVersion.UpdatedAt = now
+
+	stored := *pkgVersion
+	stored.Package = entity.Package{}
+	stored.Files = nil
+	stored.MessageVersions = nil
+	r.s.data.pkgVersions[stored.ID] = stored
+	return nil
+}
+
+func (r memoryPackageVersions) Count() (int64, error) {
+	defer r.s.lock()()
+
+	var count int64
+	for _, pkgVer := range r.s.data.pkgVersions {
+		if pkgVer.DeletedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r memoryPackageVersions) NextVersion(packageID uint) (int, error) {
+	defer r.s.lock()()
+
+	next := 1
+	for _, pkgVer := range r.s.data.pkgVersions {
+		if pkgVer.PackageID == packageID && pkgVer.Version >= next {
+			next = pkgVer.Version + 1
+		}
+	}
+	return next, nil
+}
+
+func (r memoryPackageVersions) Get(id uint) (*entity.PackageVersion, error) {
+	defer r.s.lock()()
+
+	pkgVer, ok := r.s.data.pkgVersions[id]
+	if !ok {
+		return nil, nil
+	}
+	pkgVer.Package = r.s.data.packages[pkgVer.PackageID]
+	return &pkgVer, nil
+}
+
+func (r memoryPackageVersions) Find(packageID uint, version int) (*entity.PackageVersion, error) {
+	defer r.s.lock()()
+
+	for _, pkgVer := range r.s.data.pkgVersions {
+		if pkgVer.PackageID == packageID && pkgVer.Version == version {
+			return &pkgVer, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r memoryPackageVersions) List(packageID uint, includeDeleted bool) ([]entity.PackageVersion, error) {
+	defer r.s.lock()()
+
+	var pkgVersions []entity.PackageVersion
+	for _, pkgVer := range r.s.data.pkgVersions {
+		if pkgVer.PackageID == packageID && (includeDeleted || pkgVer.DeletedAt == nil) {
+			pkgVersions = append(pkgVersions, pkgVer)
+		}
+	}
+	sort.Slice(pkgVersions, func(i, j int) bool { return pkgVersions[i].Version > pkgVersions[j].Version })
+	return pkgVersions, nil
+}
+
+// update applies a change to a stored package version
+func (r memoryPackageVersions) update(packageVersionID uint, change func(pkgVer *entity.PackageVersion)) error {
+	defer r.s.lock()()
+
+	pkgVer, ok := r.s.data.pkgVersions[packageVersionID]
+	if !ok {
+		return nil
+	}
+	change(&pkgVer)
+	pkgVer.UpdatedAt = time.Now()
+	r.s.data.pkgVersions[packageVersionID] = pkgVer
+	return nil
+}
+
+func (r memoryPackageVersions) SoftDelete(packageVersionID uint, deletedAt time.Time) error {
+	return r.update(packageVersionID, func(pkgVer *entity.PackageVersion) {
+		pkgVer.DeletedAt = &deletedAt
+	})
+}
+
+func (r memoryPackageVersions) Restore(packageVersionID uint) error {
+	return r.update(packageVersionID, func(pkgVer *entity.PackageVersion) {
+		pkgVer.DeletedAt = nil
+	})
+}
+
+func (r memoryPackageVersions) ListPurgeable(deletedBefore time.Time) ([]entity.PackageVersion, error) {
+	defer r.s.lock()()
+
+	var pkgVersions []entity.PackageVersion
+	for _, pkgVer := range byID(r.s.data.pkgVersions) {
+		if pkgVer.DeletedAt != nil && pkgVer.DeletedAt.Before(deletedBefore) {
+			pkgVer.Package = r.s.data.packages[pkgVer.PackageID]
+			pkgVersions = append(pkgVersions, pkgVer)
+		}
+	}
+	return pkgVersions, nil
+}
+
+func (r memoryPackageVersions) Purge(packageVersionID uint) error {
+	defer r.s.lock()()
+
+	r.s.data.purgeVersion(packageVersionID)
+	r.s.data.deleteOrphanedBlobs()
+	return nil
+}
+
+func (r memoryPackageVersions) SetDeprecation(packageVersionID uint, deprecation *entity.Deprecation) error {
+	return r.update(packageVersionID, func(pkgVer *entity.PackageVersion) {
+		applyDeprecation(&pkgVer.Deprecation, deprecation)
+	})
+}
+
+type memoryFiles struct{ s *MemoryStore }
+
+func (r memoryFiles) Create(file *entity.PackageVersionFile) error {
+	defer r.s.lock()()
+
+	if file.Digest == "" {
+		return fmt.Errorf("failed to create package version file: %s has no digest", file.FileName)
+	}
+	if _, ok := r.s.data.blobs[file.Digest]; !ok {
+		r.s.data.blobs[file.Digest] = file.FileContents
+	}
+
+	now := time.Now()
+	file.ID = r.s.data.nextID()
+	file.CreatedAt = now
+	file.UpdatedAt = now
+
+	stored := *file
+	stored.PackageVersion = entity.PackageVersion{}
+	stored.FileContents = ""
+	r.s.data.files[stored.ID] = stored
+	return nil
+}
+
+func (r memoryFiles) List(packageVersionID uint) ([]entity.PackageVersionFile, error) {
+	defer r.s.lock()()
+
+	var files []entity.PackageVersionFile
+	for _, file := range byID(r.s.data.files) {
+		if file.PackageVersionID == packageVersionID {
+			files = append(files, r.s.data.withContents(file))
+		}
+	}
+	return files, nil
+}
+
+func (r memoryFiles) FindLatestByImportPath(importPath string) (*entity.PackageVersionFile, error) {
+	defer r.s.lock()()
+
+	latestVersionIDs := make(map[uint]bool)
+	for _, pkg := range r.s.data.packages {
+		if pkg.LatestVersionID != nil {
+			latestVersionIDs[*pkg.LatestVersionID] = true
+		}
+	}
+
+	files := byID(r.s.data.files)
+	for i := len(files) - 1; i >= 0; i-- {
+		file := files[i]
+		if file.ImportPath != importPath || !latestVersionIDs[file.PackageVersionID] {
+			continue
+		}
+
+		file = r.s.data.withContents(file)
+		file.PackageVersion = r.s.data.pkgVersions[file.PackageVersionID]
+		file.PackageVersion.Package = r.s.data.packages[file.PackageVersion.PackageID]
+		return &file, nil
+	}
+	return nil, nil
+}
+
+type memoryMessages struct{ s *MemoryStore }
+
+// visible lists messages with a latest version, optionally matching a search term, most recently updated first
+func (r memoryMessages) visible(searchTerm string) []entity.Message {
+	var messages []entity.Message
+	for _, message := range byID(r.s.data.messages) {
+		if message.LatestVersionID != nil && matchesSearch(message.Name, searchTerm) {
+			messages = append(messages, message)
+		}
+	}
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].UpdatedAt.After(messages[j].UpdatedAt) })
+	return messages
+}
+
+func (r memoryMessages) List(limit, offset int, searchTerm string) ([]entity.Message, error) {
+	defer r.s.lock()()
+
+	messages := paginate(r.visible(searchTerm), limit, offset)
+	for i := range messages {
+		messages[i] = r.s.data.withLatestMessageVersion(messages[i])
+		messages[i].Package = r.s.data.packages[messages[i].PackageID]
+	}
+	return messages, nil
+}
+
+func (r memoryMessages) Count(searchTerm string) (int64, error) {
+	defer r.s.lock()()
+	return int64(len(r.visible(searchTerm))), nil
+}
+
+func (r memoryMessages) CountByPackage(packageID uint) (int64, error) {
+	defer r.s.lock()()
+
+	var count int64
+	for _, message := range r.s.data.messages {
+		if message.PackageID == packageID && message.LatestVersionID != nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r memoryMessages) ListByPackage(packageID uint) ([]entity.Message, error) {
+	defer r.s.lock()()
+
+	var messages []entity.Message
+	for _, message := range byID(r.s.data.messages) {
+		if message.PackageID == packageID {
+			messages = append(messages, r.s.data.withLatestMessageVersion(message))
+		}
+	}
+	return messages, nil
+}
+
+func (r memoryMessages) FindByName(packageID uint, name string) (*entity.Message, error) {
+	defer r.s.lock()()
+
+	message, ok := r.s.data.findMessage(packageID, name)
+	if !ok {
+		return nil, nil
+	}
+	message = r.s.data.withLatestMessageVersion(message)
+	return &message, nil
+}
+
+func (r memoryMessages) FindOrCreate(packageID uint, name, protoBody string) (*entity.Message, error) {
+	defer r.s.lock()()
+
+	if message, ok := r.s.data.findMessage(packageID, name); ok {
+		return &message, nil
+	}
+
+	now := time.Now()
+	message := entity.Message{ID: r.s.data.nextID(), CreatedAt: now, UpdatedAt: now, PackageID: packageID, Name: name, ProtoBody: protoBody}
+	r.s.data.messages[message.ID] = message
+	return &message, nil
+}
+
+// setLatestVersion points a stored message at a version, or hides it when version is nil
+func (r memoryMessages) setLatestVersion(messageID uint, version *entity.MessageVersion) {
+	message, ok := r.s.data.messages[messageID]
+	if !ok {
+		return
+	}
+
+	// Messages without remaining versions keep their last body
+	message.LatestVersionID = nil
+	if version != nil {
+		message.LatestVersionID = copyID(&version.ID)
+		message.ProtoBody = version.ProtoBody
+	}
+	message.UpdatedAt = time.Now()
+	r.s.data.messages[messageID] = message
+}
+
+func (r memoryMessages) SetLatestVersion(messageID uint, version *entity.MessageVersion) error {
+	defer r.s.lock()()
+
+	r.setLatestVersion(messageID, version)
+	return nil
+}
+
+func (r memoryMessages) CreateVersion(version *entity.MessageVersion) error {
+	defer r.s.lock()()
+
+	now := time.Now()
+	version.ID = r.s.data.nextID()
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = now
+	}
+	version.UpdatedAt = now
+
+	stored := *version
+	stored.Message = entity.Message{}
+	stored.PackageVersion = entity.PackageVersion{}
+	r.s.data.messageVersions[stored.ID] = stored
+
+	r.s.data.linkVersion(version.PackageVersionID, version.ID)
+	return nil
+}
+
+// linkVersion records that a package version contains a message version
+func (d *memoryData) linkVersion(packageVersionID, messageVersionID uint) {
+	id := d.nextID()
+	d.messageLinks[id] = entity.PackageVersionMessage{
+		ID:               id,
+		CreatedAt:        time.Now(),
+		PackageVersionID: packageVersionID,
+		MessageVersionID: messageVersionID,
+	}
+}
+
+func (r memoryMessages) LinkVersion(packageVersionID uint, version *entity.MessageVersion) error {
+	defer r.s.lock()()
+
+	for _, link := range r.s.data.messageLinks {
+		if link.PackageVersionID == packageVersionID && link.MessageVersionID == version.ID {
+			return fmt.Errorf("failed to link message version: version %d is already linked", version.ID)
+		}
+	}
+
+	r.s.data.linkVersion(packageVersionID, version.ID)
+	return nil
+}
+
+func (r memoryMessages) ListVersionLinks(messageID uint) ([]entity.PackageVersionMessage, error) {
+	defer r.s.lock()()
+
+	var links []entity.PackageVersionMessage
+	for _, link := range byID(r.s.data.messageLinks) {
+		if r.s.data.messageVersions[link.MessageVersionID].MessageID == messageID {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+func (r memoryMessages) ListVersions(messageID uint) ([]entity.MessageVersion, error) {
+	defer r.s.lock()()
+
+	var versions []entity.MessageVersion
+	for _, version := range r.s.data.messageVersions {
+		if version.MessageID == messageID {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, nil
+}
+
+func (r memoryMessages) NextVersion(messageID uint) (int, error) {
+	defer r.s.lock()()
+
+	next := 1
+	for _, version := range r.s.data.messageVersions {
+		if version.MessageID == messageID && version.Version >= next {
+			next = version.Version + 1
+		}
+	}
+	return next, nil
+}
+
+func (r memoryMessages) AssignLatestVersions(packageID uint) error {
+	defer r.s.lock()()
+
+	contained := r.s.data.containedVersions(false)
+	for _, message := range r.s.data.messages {
+		if message.PackageID != packageID {
+			continue
+		}
+
+		var latest *entity.MessageVersion
+		for _, version := range r.s.data.messageVersions {
+			if version.MessageID != message.ID || !contained[version.ID] {
+				continue
+			}
+			if latest == nil || version.Version > latest.Version {
+				latest = &version
+			}
+		}
+
+		r.setLatestVersion(message.ID, latest)
+	}
+	return nil
+}
+
+func (r memoryMessages) DeleteOrphaned(packageID uint) error {
+	defer r.s.lock()()
+
+	contained := r.s.data.containedVersions(true)
+	hasVersions := make(map[uint]bool)
+	for id, version := range r.s.data.messageVersions {
+		if contained[id] {
+			hasVersions[version.MessageID] = true
+		}
+	}
+
+	for id, message := range r.s.data.messages {
+		if message.PackageID == packageID && !hasVersions[id] {
+			delete(r.s.data.messages, id)
+		}
+	}
+	return nil
+}
+
+func (r memoryMessages) SetDeprecation(messageID uint, deprecation *entity.Deprecation) error {
+	defer r.s.lock()()
+
+	message, ok := r.s.data.messages[messageID]
+	if !ok {
+		return nil
+	}
+	applyDeprecation(&message.Deprecation, deprecation)
+	message.UpdatedAt = time.Now()
+	r.s.data.messages[messageID] = message
+	return nil
+}
+
+func (r memoryMessages) ListDeprecated(packageID uint) ([]entity.Message, error) {
+	defer r.s.lock()()
+
+	var messages []entity.Message
+	for _, message := range r.s.data.messages {
+		if message.PackageID == packageID && message.IsDeprecated() && message.LatestVersionID != nil {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Name < messages[j].Name })
+	return messages, nil
+}
+
+type memoryAuditEvents struct{ s *MemoryStore }
+
+func (r memoryAuditEvents) Create(event *entity.AuditEvent) error {
+	defer r.s.lock()()
+
+	event.ID = r.s.data.nextID()
+	event.CreatedAt = time.Now()
+	r.s.data.auditEvents = append(r.s.data.auditEvents, *event)
+	return nil
+}
+
+func (r memoryAuditEvents) List(filter entity.AuditEventFilter) ([]entity.AuditEvent, error) {
+	defer r.s.lock()()
+
+	var events []entity.AuditEvent
+	for i := len(r.s.data.auditEvents) - 1; i >= 0; i-- {
+		event := r.s.data.auditEvents[i]
+		if filter.Actor != "" && event.Actor != filter.Actor ||
+			filter.Action != "" && event.Action != filter.Action ||
+			filter.PackageName != "" && event.PackageName != filter.PackageName ||
+			!filter.Since.IsZero() && event.CreatedAt.Before(filter.Since) ||
+			!filter.Until.IsZero() && !event.CreatedAt.Before(filter.Until) ||
+			filter.Result != "" && event.Result != filter.Result ||
+			event.ID <= filter.AfterID {
+			continue
+		}
+		events = append(events, event)
+	}
+	if filter.OldestFirst {
+		slices.Reverse(events)
+	}
+	return paginate(events, filter.Limit, 0), nil
+}
+
+type memoryRoleGrants struct{ s *MemoryStore }
+
+func (r memoryRoleGrants) find(subject, role, packagePattern string) (entity.RoleGrant, bool) {
+	for _, grant := range r.s.data.roleGrants {
+		if grant.Subject == subject && grant.Role == role && grant.PackagePattern == packagePattern {
+			return grant, true
+		}
+	}
+	return entity.RoleGrant{}, false
+}
+
+func (r memoryRoleGrants) Create(grant *entity.RoleGrant) error {
+	defer r.s.lock()()
+
+	if _, ok := r.find(grant.Subject, grant.Role, grant.PackagePattern); ok {
+		return fmt.Errorf("failed to create role grant: grant already exists")
+	}
+
+	now := time.Now()
+	grant.ID = r.s.data.nextID()
+	grant.CreatedAt = now
+	grant.UpdatedAt = now
+	r.s.data.roleGrants[grant.ID] = *grant
+	return nil
+}
+
+func (r memoryRoleGrants) Get(id uint) (*entity.RoleGrant, error) {
+	defer r.s.lock()()
+
+	grant, ok := r.s.data.roleGrants[id]
+	if !ok {
+		return nil, nil
+	}
+	return &grant, nil
+}
+
+func (r memoryRoleGrants) Find(subject, role, packagePattern string) (*entity.RoleGrant, error) {
+	defer r.s.lock()()
+
+	grant, ok := r.find(subject, role, packagePattern)
+	if !ok {
+		return nil, nil
+	}
+	return &grant, nil
+}
+
+func (r memoryRoleGrants) List(subject string) ([]entity.RoleGrant, error) {
+	defer r.s.lock()()
+
+	var grants []entity.RoleGrant
+	for _, grant := range r.s.data.roleGrants {
+		if subject == "" || grant.Subject == subject {
+			grants = append(grants, grant)
+		}
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		a, b := grants[i], grants[j]
+		if a.Subject != b.Subject {
+			return a.Subject < b.Subject
+		}
+		if a.PackagePattern != b.PackagePattern {
+			return a.PackagePattern < b.PackagePattern
+		}
+		return a.Role < b.Role
+	})
+	return grants, nil
+}
+
+func (r memoryRoleGrants) Delete(id uint) error {
+	defer r.s.lock()()
+
+	delete(r.s.data.roleGrants, id)
+	return nil
+}
+
+type memoryAPITokens struct{ s *MemoryStore }
+
+func (r memoryAPITokens) Create(token *entity.APIToken) error {
+	defer r.s.lock()()
+
+	for _, existing := range r.s.data.apiTokens {
+		if existing.Name == token.Name || existing.TokenHash == token.TokenHash {
+			return fmt.Errorf("failed to create api token: token %s already exists", token.Name)
+		}
+	}
+
+	now := time.Now()
+	token.ID = r.s.data.nextID()
+	token.CreatedAt = now
+	token.UpdatedAt = now
+	r.s.data.apiTokens[token.ID] = *token
+	return nil
+}
+
+func (r memoryAPITokens) FindByHash(tokenHash string) (*entity.APIToken, error) {
+	defer r.s.lock()()
+
+	for _, token := range r.s.data.apiTokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r memoryAPITokens) List() ([]entity.APIToken, error) {
+	defer r.s.lock()()
+
+	tokens := slices.Collect(maps.Values(r.s.data.apiTokens))
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Name < tokens[j].Name })
+	return tokens, nil
+}
+
+func (r memoryAPITokens) Delete(name string) (bool, error) {
+	defer r.s.lock()()
+
+	for id, token := range r.s.data.apiTokens {
+		if token.Name == name {
+			delete(r.s.data.apiTokens, id)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryAPITokens) Touch(id uint, usedAt time.Time) error {
+	defer r.s.lock()()
+
+	token, ok := r.s.data.apiTokens[id]
+	if !ok {
+		return nil
+	}
+	token.LastUsedAt = &usedAt
+	r.s.data.apiTokens[id] = token
+	return nil
+}
+
+type memoryMirrorCursors struct{ s *MemoryStore }
+
+func (r memoryMirrorCursors) Get(upstream string) (*entity.MirrorCursor, error) {
+	defer r.s.lock()()
+
+	cursor, ok := r.s.data.mirrorCursors[upstream]
+	if !ok {
+		return nil, nil
+	}
+	return &cursor, nil
+}
+
+func (r memoryMirrorCursors) Set(upstream string, changeID uint) error {
+	defer r.s.lock()()
+
+	r.s.data.mirrorCursors[upstream] = entity.MirrorCursor{Upstream: upstream, UpdatedAt: time.Now(), ChangeID: changeID}
+	return nil
+}
+
+type memoryWebhookDeliveries struct{ s *MemoryStore }
+
+func (r memoryWebhookDeliveries) Create(delivery *entity.WebhookDelivery) error {
+	defer r.s.lock()()
+
+	delivery.ID = r.s.data.nextID()
+	delivery.CreatedAt = time.Now()
+	r.s.data.deliveries = append(r.s.data.deliveries, *delivery)
+	return nil
+}
+
+func (r memoryWebhookDeliveries) List(filter entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, error) {
+	defer r.s.lock()()
+
+	var deliveries []entity.WebhookDelivery
+	for i := len(r.s.data.deliveries) - 1; i >= 0; i-- {
+		delivery := r.s.data.deliveries[i]
+		if filter.Webhook != "" && delivery.Webhook != filter.Webhook ||
+			filter.EventID != "" && delivery.EventID != filter.EventID ||
+			filter.Result != "" && delivery.Result != filter.Result {
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return paginate(deliveries, filter.Limit, 0), nil
+}
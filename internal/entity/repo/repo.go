@@ -0,0 +1,214 @@
+// Package repo defines the storage interfaces for registry entities. Controllers and services work against a Store,
+// so the same logic runs on a SQL database or in memory.
+//
+// Lookups of a single entity return nil, without an error, when the entity does not exist.
+package repo
+
+import (
+	"context"
+	"time"
+
+	entity "github.com/cgund98/voer/internal/entity/db"
+)
+
+// Store gives access to the repositories of every entity
+type Store interface {
+	Packages() PackageRepository
+	PackageVersions() PackageVersionRepository
+	Files() FileRepository
+	Messages() MessageRepository
+	AuditEvents() AuditEventRepository
+	RoleGrants() RoleGrantRepository
+	APITokens() APITokenRepository
+	MirrorCursors() MirrorCursorRepository
+	WebhookDeliveries() WebhookDeliveryRepository
+
+	// Transaction runs fn against a store whose changes are committed when fn returns nil and rolled back otherwise
+	Transaction(fn func(tx Store) error) error
+
+	// WithContext returns a store whose queries run under ctx, so they are traced as part of the request
+	WithContext(ctx context.Context) Store
+}
+
+// PackageRepository stores packages and their former names
+type PackageRepository interface {
+	// List lists packages with their latest versions, optionally filtered by a search term
+	List(limit, offset int, searchTerm string) ([]entity.Package, error)
+	Count(searchTerm string) (int64, error)
+
+	// Get fetches a package and its latest version by ID
+	Get(id uint) (*entity.Package, error)
+
+	// FindByName fetches a package and its latest version by name
+	FindByName(packageName string) (*entity.Package, error)
+
+	// FindOrCreate fetches a package by name, creating it when it does not exist
+	FindOrCreate(packageName string) (*entity.Package, error)
+
+	// SetLatestVersion points a package at one of its versions, or at nothing when packageVersionID is nil
+	SetLatestVersion(packageID uint, packageVersionID *uint) error
+
+	// AssignLatestVersion points a package at its newest version that is not deleted
+	AssignLatestVersion(packageID uint) error
+
+	Rename(packageID uint, packageName string) error
+
+	// Delete permanently deletes a package with its versions, files, messages and aliases, along with unreferenced blobs
+	Delete(packageID uint) error
+
+	// FindAlias fetches a former name and the package it points at
+	FindAlias(aliasName string) (*entity.PackageAlias, error)
+
+	// ListAliases lists the former names of a package, oldest first
+	ListAliases(packageID uint) ([]entity.PackageAlias, error)
+
+	CreateAlias(aliasName string, packageID uint) error
+	DeleteAlias(aliasName string) error
+}
+
+// PackageVersionRepository stores package versions
+type PackageVersionRepository interface {
+	Create(pkgVersion *entity.PackageVersion) error
+
+	// Count counts the package versions of every package that are not deleted
+	Count() (int64, error)
+
+	// NextVersion returns the next version number of a package, counting deleted versions
+	NextVersion(packageID uint) (int, error)
+
+	// Get fetches a package version and its package by ID
+	Get(id uint) (*entity.PackageVersion, error)
+
+	// Find fetches a version of a package by number
+	Find(packageID uint, version int) (*entity.PackageVersion, error)
+
+	// List lists the versions of a package, newest first. Deleted versions are only included when includeDeleted is set.
+	List(packageID uint, includeDeleted bool) ([]entity.PackageVersion, error)
+
+	SoftDelete(packageVersionID uint, deletedAt time.Time) error
+	Restore(packageVersionID uint) error
+
+	// ListPurgeable lists versions deleted before a given time, along with their package
+	ListPurgeable(deletedBefore time.Time) ([]entity.PackageVersion, error)
+
+	// Purge permanently deletes a package version with its files and message versions, along with unreferenced blobs.
+	// Message versions that later package versions left unchanged are kept.
+	Purge(packageVersionID uint) error
+
+	// SetDeprecation deprecates a package version, or clears its deprecation when deprecation is nil
+	SetDeprecation(packageVersionID uint, deprecation *entity.Deprecation) error
+}
+
+// FileRepository stores the proto files of package versions. File contents are kept in content-addressed blobs
+// shared by identical files, and filled in when files are read.
+type FileRepository interface {
+	// Create stores a file's contents as a blob under its digest, then creates the file
+	Create(file *entity.PackageVersionFile) error
+
+	List(packageVersionID uint) ([]entity.PackageVersionFile, error)
+
+	// FindLatestByImportPath finds a file by import path among the latest versions of all packages,
+	// along with its package version and package
+	FindLatestByImportPath(importPath string) (*entity.PackageVersionFile, error)
+}
+
+// MessageRepository stores messages and their versions
+type MessageRepository interface {
+	// List lists visible messages with their latest versions and packages, most recently updated first
+	List(limit, offset int, searchTerm string) ([]entity.Message, error)
+	Count(searchTerm string) (int64, error)
+	CountByPackage(packageID uint) (int64, error)
+
+	// ListByPackage lists the messages of a package with their latest versions, including hidden messages
+	ListByPackage(packageID uint) ([]entity.Message, error)
+
+	// FindByName fetches a message of a package and its latest version by name
+	FindByName(packageID uint, name string) (*entity.Message, error)
+
+	// FindOrCreate fetches a message of a package by name, creating it with the given body when it does not exist
+	FindOrCreate(packageID uint, name, protoBody string) (*entity.Message, error)
+
+	// SetLatestVersion points a message at one of its versions and copies the version's body
+	SetLatestVersion(messageID uint, version *entity.MessageVersion) error
+
+	// CreateVersion creates a message version and links it to the package version introducing it
+	CreateVersion(version *entity.MessageVersion) error
+
+	// LinkVersion links a package version to an existing message version, for messages it leaves unchanged
+	LinkVersion(packageVersionID uint, version *entity.MessageVersion) error
+
+	// ListVersionLinks lists the links of every version of a message to the package versions containing it
+	ListVersionLinks(messageID uint) ([]entity.PackageVersionMessage, error)
+
+	// ListVersions lists the versions of a message, oldest first
+	ListVersions(messageID uint) ([]entity.MessageVersion, error)
+
+	// NextVersion returns the next version number of a message
+	NextVersion(messageID uint) (int, error)
+
+	// AssignLatestVersions points each message of a package at its newest version contained in a package version that
+	// is not deleted, hiding messages without one
+	AssignLatestVersions(packageID uint) error
+
+	// DeleteOrphaned deletes the messages of a package that no longer appear in any of its package versions
+	DeleteOrphaned(packageID uint) error
+
+	// SetDeprecation deprecates a message, or clears its deprecation when deprecation is nil
+	SetDeprecation(messageID uint, deprecation *entity.Deprecation) error
+
+	// ListDeprecated lists the visible deprecated messages of a package by name
+	ListDeprecated(packageID uint) ([]entity.Message, error)
+}
+
+// AuditEventRepository stores the append-only audit log
+type AuditEventRepository interface {
+	Create(event *entity.AuditEvent) error
+
+	// List lists events matching a filter, newest first unless the filter asks for the oldest first
+	List(filter entity.AuditEventFilter) ([]entity.AuditEvent, error)
+}
+
+// RoleGrantRepository stores role grants
+type RoleGrantRepository interface {
+	Create(grant *entity.RoleGrant) error
+	Get(id uint) (*entity.RoleGrant, error)
+
+	// Find fetches a grant by its subject, role and pattern
+	Find(subject, role, packagePattern string) (*entity.RoleGrant, error)
+
+	// List lists grants ordered by subject. All grants are listed when subject is empty.
+	List(subject string) ([]entity.RoleGrant, error)
+
+	Delete(id uint) error
+}
+
+// APITokenRepository stores static API tokens, identified by the hash of their secret
+type APITokenRepository interface {
+	Create(token *entity.APIToken) error
+
+	// FindByHash fetches a token by the hash of its secret
+	FindByHash(tokenHash string) (*entity.APIToken, error)
+
+	// List lists tokens ordered by name
+	List() ([]entity.APIToken, error)
+
+	// Delete deletes a token by name. Returns false if no token exists with the given name.
+	Delete(name string) (bool, error)
+
+	// Touch records when a token was last used
+	Touch(id uint, usedAt time.Time) error
+}
+
+// MirrorCursorRepository stores how far a mirror has replicated each upstream registry
+type MirrorCursorRepository interface {
+	Get(upstream string) (*entity.MirrorCursor, error)
+	Set(upstream string, changeID uint) error
+}
+
+// WebhookDeliveryRepository stores the log of webhook delivery attempts
+type WebhookDeliveryRepository interface {
+	Create(delivery *entity.WebhookDelivery) error
+
+	// List lists deliveries matching a filter, newest first
+	List(filter entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, error)
+}
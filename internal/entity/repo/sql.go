@@ -0,0 +1,293 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	entity "github.com/cgund98/voer/internal/entity/db"
+)
+
+// SQLStore stores entities in a SQL database through GORM. It works with both SQLite and PostgreSQL.
+type SQLStore struct {
+	db *gorm.DB
+}
+
+func NewSQLStore(db *gorm.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+func (s *SQLStore) Packages() PackageRepository                  { return sqlPackages{s.db} }
+func (s *SQLStore) PackageVersions() PackageVersionRepository    { return sqlPackageVersions{s.db} }
+func (s *SQLStore) Files() FileRepository                        { return sqlFiles{s.db} }
+func (s *SQLStore) Messages() MessageRepository                  { return sqlMessages{s.db} }
+func (s *SQLStore) AuditEvents() AuditEventRepository            { return sqlAuditEvents{s.db} }
+func (s *SQLStore) RoleGrants() RoleGrantRepository              { return sqlRoleGrants{s.db} }
+func (s *SQLStore) APITokens() APITokenRepository                { return sqlAPITokens{s.db} }
+func (s *SQLStore) MirrorCursors() MirrorCursorRepository        { return sqlMirrorCursors{s.db} }
+func (s *SQLStore) WebhookDeliveries() WebhookDeliveryRepository { return sqlWebhookDeliveries{s.db} }
+
+func (s *SQLStore) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewSQLStore(tx))
+	})
+}
+
+func (s *SQLStore) WithContext(ctx context.Context) Store {
+	return NewSQLStore(s.db.WithContext(ctx))
+}
+
+type sqlPackages struct{ db *gorm.DB }
+
+func (r sqlPackages) List(limit, offset int, searchTerm string) ([]entity.Package, error) {
+	return entity.ListPackages(r.db, limit, offset, searchTerm)
+}
+
+func (r sqlPackages) Count(searchTerm string) (int64, error) {
+	return entity.CountPackages(r.db, searchTerm)
+}
+
+func (r sqlPackages) Get(id uint) (*entity.Package, error) {
+	return entity.GetPackage(r.db, id)
+}
+
+func (r sqlPackages) FindByName(packageName string) (*entity.Package, error) {
+	return entity.FindPackageByName(r.db, packageName)
+}
+
+func (r sqlPackages) FindOrCreate(packageName string) (*entity.Package, error) {
+	return entity.FindOrCreatePackage(r.db, packageName)
+}
+
+func (r sqlPackages) SetLatestVersion(packageID uint, packageVersionID *uint) error {
+	return entity.SetPackageLatestVersion(r.db, packageID, packageVersionID)
+}
+
+func (r sqlPackages) AssignLatestVersion(packageID uint) error {
+	return entity.AssignLatestPackageVersion(r.db, packageID)
+}
+
+func (r sqlPackages) Rename(packageID uint, packageName string) error {
+	return entity.RenamePackage(r.db, packageID, packageName)
+}
+
+func (r sqlPackages) Delete(packageID uint) error {
+	return entity.DeletePackage(r.db, packageID)
+}
+
+func (r sqlPackages) FindAlias(aliasName string) (*entity.PackageAlias, error) {
+	return entity.FindPackageAlias(r.db, aliasName)
+}
+
+func (r sqlPackages) ListAliases(packageID uint) ([]entity.PackageAlias, error) {
+	return entity.ListPackageAliases(r.db, packageID)
+}
+
+func (r sqlPackages) CreateAlias(aliasName string, packageID uint) error {
+	return entity.CreatePackageAlias(r.db, aliasName, packageID)
+}
+
+func (r sqlPackages) DeleteAlias(aliasName string) error {
+	return entity.DeletePackageAlias(r.db, aliasName)
+}
+
+type sqlPackageVersions struct{ db *gorm.DB }
+
+func (r sqlPackageVersions) Create(pkgVersion *entity.PackageVersion) error {
+	return entity.CreatePackageVersion(r.db, pkgVersion)
+}
+
+func (r sqlPackageVersions) Count() (int64, error) {
+	return entity.CountPackageVersions(r.db)
+}
+
+func (r sqlPackageVersions) NextVersion(packageID uint) (int, error) {
+	return entity.GetNextPackageVersion(r.db, packageID)
+}
+
+func (r sqlPackageVersions) Get(id uint) (*entity.PackageVersion, error) {
+	return entity.GetPackageVersion(r.db, id)
+}
+
+func (r sqlPackageVersions) Find(packageID uint, version int) (*entity.PackageVersion, error) {
+	return entity.FindPackageVersion(r.db, packageID, version)
+}
+
+func (r sqlPackageVersions) List(packageID uint, includeDeleted bool) ([]entity.PackageVersion, error) {
+	return entity.ListPackageVersions(r.db, packageID, includeDeleted)
+}
+
+func (r sqlPackageVersions) SoftDelete(packageVersionID uint, deletedAt time.Time) error {
+	return entity.SoftDeletePackageVersion(r.db, packageVersionID, deletedAt)
+}
+
+func (r sqlPackageVersions) Restore(packageVersionID uint) error {
+	return entity.RestorePackageVersion(r.db, packageVersionID)
+}
+
+func (r sqlPackageVersions) ListPurgeable(deletedBefore time.Time) ([]entity.PackageVersion, error) {
+	return entity.ListPurgeablePackageVersions(r.db, deletedBefore)
+}
+
+func (r sqlPackageVersions) Purge(packageVersionID uint) error {
+	return entity.PurgePackageVersion(r.db, packageVersionID)
+}
+
+func (r sqlPackageVersions) SetDeprecation(packageVersionID uint, deprecation *entity.Deprecation) error {
+	return entity.SetPackageVersionDeprecation(r.db, packageVersionID, deprecation)
+}
+
+type sqlFiles struct{ db *gorm.DB }
+
+func (r sqlFiles) Create(file *entity.PackageVersionFile) error {
+	return entity.CreatePackageVersionFile(r.db, file)
+}
+
+func (r sqlFiles) List(packageVersionID uint) ([]entity.PackageVersionFile, error) {
+	return entity.ListPackageVersionFiles(r.db, packageVersionID)
+}
+
+func (r sqlFiles) FindLatestByImportPath(importPath string) (*entity.PackageVersionFile, error) {
+	return entity.FindLatestFileByImportPath(r.db, importPath)
+}
+
+type sqlMessages struct{ db *gorm.DB }
+
+func (r sqlMessages) List(limit, offset int, searchTerm string) ([]entity.Message, error) {
+	return entity.ListMessages(r.db, limit, offset, searchTerm)
+}
+
+func (r sqlMessages) Count(searchTerm string) (int64, error) {
+	return entity.CountMessages(r.db, searchTerm)
+}
+
+func (r sqlMessages) CountByPackage(packageID uint) (int64, error) {
+	return entity.CountMessagesByPackage(r.db, packageID)
+}
+
+func (r sqlMessages) ListByPackage(packageID uint) ([]entity.Message, error) {
+	return entity.ListPackageMessages(r.db, packageID)
+}
+
+func (r sqlMessages) FindByName(packageID uint, name string) (*entity.Message, error) {
+	return entity.FindMessageByName(r.db, packageID, name)
+}
+
+func (r sqlMessages) FindOrCreate(packageID uint, name, protoBody string) (*entity.Message, error) {
+	return entity.FindOrCreateMessage(r.db, packageID, name, protoBody)
+}
+
+func (r sqlMessages) SetLatestVersion(messageID uint, version *entity.MessageVersion) error {
+	return entity.SetMessageLatestVersion(r.db, messageID, version)
+}
+
+func (r sqlMessages) CreateVersion(version *entity.MessageVersion) error {
+	return entity.CreateMessageVersion(r.db, version)
+}
+
+func (r sqlMessages) LinkVersion(packageVersionID uint, version *entity.MessageVersion) error {
+	return entity.LinkMessageVersion(r.db, packageVersionID, version.ID)
+}
+
+func (r sqlMessages) ListVersionLinks(messageID uint) ([]entity.PackageVersionMessage, error) {
+	return entity.ListMessageVersionLinks(r.db, messageID)
+}
+
+func (r sqlMessages) ListVersions(messageID uint) ([]entity.MessageVersion, error) {
+	return entity.ListMessageVersions(r.db, messageID)
+}
+
+func (r sqlMessages) NextVersion(messageID uint) (int, error) {
+	return entity.GetNextMessageVersion(r.db, messageID)
+}
+
+func (r sqlMessages) AssignLatestVersions(packageID uint) error {
+	return entity.AssignLatestVersion(r.db, packageID)
+}
+
+func (r sqlMessages) DeleteOrphaned(packageID uint) error {
+	return entity.DeleteOrphanedMessages(r.db, packageID)
+}
+
+func (r sqlMessages) SetDeprecation(messageID uint, deprecation *entity.Deprecation) error {
+	return entity.SetMessageDeprecation(r.db, messageID, deprecation)
+}
+
+func (r sqlMessages) ListDeprecated(packageID uint) ([]entity.Message, error) {
+	return entity.ListDeprecatedMessages(r.db, packageID)
+}
+
+type sqlAuditEvents struct{ db *gorm.DB }
+
+func (r sqlAuditEvents) Create(event *entity.AuditEvent) error {
+	return entity.CreateAuditEvent(r.db, event)
+}
+
+func (r sqlAuditEvents) List(filter entity.AuditEventFilter) ([]entity.AuditEvent, error) {
+	return entity.ListAuditEvents(r.db, filter)
+}
+
+type sqlRoleGrants struct{ db *gorm.DB }
+
+func (r sqlRoleGrants) Create(grant *entity.RoleGrant) error {
+	return entity.CreateRoleGrant(r.db, grant)
+}
+
+func (r sqlRoleGrants) Get(id uint) (*entity.RoleGrant, error) {
+	return entity.GetRoleGrant(r.db, id)
+}
+
+func (r sqlRoleGrants) Find(subject, role, packagePattern string) (*entity.RoleGrant, error) {
+	return entity.FindRoleGrant(r.db, subject, role, packagePattern)
+}
+
+func (r sqlRoleGrants) List(subject string) ([]entity.RoleGrant, error) {
+	return entity.ListRoleGrants(r.db, subject)
+}
+
+func (r sqlRoleGrants) Delete(id uint) error {
+	return entity.DeleteRoleGrant(r.db, id)
+}
+
+type sqlAPITokens struct{ db *gorm.DB }
+
+func (r sqlAPITokens) Create(token *entity.APIToken) error {
+	return entity.CreateAPIToken(r.db, token)
+}
+
+func (r sqlAPITokens) FindByHash(tokenHash string) (*entity.APIToken, error) {
+	return entity.FindAPITokenByHash(r.db, tokenHash)
+}
+
+func (r sqlAPITokens) List() ([]entity.APIToken, error) {
+	return entity.ListAPITokens(r.db)
+}
+
+func (r sqlAPITokens) Delete(name string) (bool, error) {
+	return entity.DeleteAPIToken(r.db, name)
+}
+
+func (r sqlAPITokens) Touch(id uint, usedAt time.Time) error {
+	return entity.TouchAPIToken(r.db, id, usedAt)
+}
+
+type sqlMirrorCursors struct{ db *gorm.DB }
+
+func (r sqlMirrorCursors) Get(upstream string) (*entity.MirrorCursor, error) {
+	return entity.GetMirrorCursor(r.db, upstream)
+}
+
+func (r sqlMirrorCursors) Set(upstream string, changeID uint) error {
+	return entity.SetMirrorCursor(r.db, upstream, changeID)
+}
+
+type sqlWebhookDeliveries struct{ db *gorm.DB }
+
+func (r sqlWebhookDeliveries) Create(delivery *entity.WebhookDelivery) error {
+	return entity.CreateWebhookDelivery(r.db, delivery)
+}
+
+func (r sqlWebhookDeliveries) List(filter entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, error) {
+	return entity.ListWebhookDeliveries(r.db, filter)
+}
@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"context"
+
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+const (
+	// Actions
+	ActionUpload         = "upload"
+	ActionDeleteVersion  = "delete_version"
+	ActionRestoreVersion = "restore_version"
+	ActionPurgeVersion   = "purge_version"
+	ActionDeprecate      = "deprecate"
+	ActionUndeprecate    = "undeprecate"
+	ActionDeletePackage  = "delete_package"
+	ActionRenamePackage  = "rename_package"
+	ActionImport         = "import"
+	ActionGrantRole      = "grant_role"
+	ActionRevokeRole     = "revoke_role"
+
+	// Results
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+
+	// Actor recorded when authentication is disabled
+	AnonymousActor = "anonymous"
+
+	// Actor recorded for background jobs
+	SystemActor = "system"
+)
+
+// RequestMetadata describes the request that caused an event
+type RequestMetadata struct {
+	// gRPC method or HTTP method and path
+	Method        string
+	ClientAddress string
+	UserAgent     string
+}
+
+type requestMetadataKey struct{}
+
+// WithRequestMetadata returns a context carrying the metadata of the current request
+func WithRequestMetadata(ctx context.Context, metadata RequestMetadata) context.Context {
+	return context.WithValue(ctx, requestMetadataKey{}, metadata)
+}
+
+// RequestMetadataFromContext returns the metadata of the current request, if any
+func RequestMetadataFromContext(ctx context.Context) RequestMetadata {
+	metadata, _ := ctx.Value(requestMetadataKey{}).(RequestMetadata)
+	return metadata
+}
+
+// Event is a registry mutation to record
+type Event struct {
+	// Overrides the caller in the context, e.g. for background jobs
+	Actor string
+
+	Action      string
+	PackageName string
+	Version     uint
+	Detail      string
+}
+
+// ActorFromContext returns the subject of the caller in the context, or the anonymous actor when authentication is
+// disabled
+func ActorFromContext(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.Subject
+	}
+	return AnonymousActor
+}
+
+// Record appends an event to the audit log, attributed to the caller in the context.
+// The event is recorded as a failure when err is non-nil. Failing to record an event is logged rather than
+// returned, so it never masks the outcome of the mutation itself. Successful mutations use RecordSuccess instead.
+func Record(ctx context.Context, events repo.AuditEventRepository, event Event, err error) {
+	auditEvent := newAuditEvent(ctx, event)
+	if err != nil {
+		auditEvent.Result = ResultFailure
+		auditEvent.Error = err.Error()
+	}
+
+	if createErr := events.Create(auditEvent); createErr != nil {
+		logging.Logger.ErrorContext(ctx, "Failed to record audit event", "action", event.Action, "package", event.PackageName, "error", createErr)
+	}
+}
+
+// RecordSuccess appends a successful event to the audit log of the transaction making the change, so the event is
+// committed if and only if the change is. Change feeds replicate from the audit log and would otherwise miss changes
+// whose event failed to record. Mutations record the event last, as it holds up other events until commit.
+func RecordSuccess(ctx context.Context, events repo.AuditEventRepository, event Event) error {
+	return events.Create(newAuditEvent(ctx, event))
+}
+
+// newAuditEvent builds a successful audit event attributed to the caller in the context
+func newAuditEvent(ctx context.Context, event Event) *entity.AuditEvent {
+	actor := ActorFromContext(ctx)
+	if event.Actor != "" {
+		actor = event.Actor
+	}
+
+	metadata := RequestMetadataFromContext(ctx)
+	return &entity.AuditEvent{
+		Actor:         actor,
+		Action:        event.Action,
+		PackageName:   event.PackageName,
+		Version:       event.Version,
+		Detail:        event.Detail,
+		Method:        metadata.Method,
+		ClientAddress: metadata.ClientAddress,
+		UserAgent:     metadata.UserAgent,
+		Result:        ResultSuccess,
+	}
+}
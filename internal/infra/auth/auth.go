@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnauthenticated is returned when credentials are missing or invalid
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+const (
+	// Authentication methods
+	MethodToken = "token"
+	MethodJWT   = "jwt"
+)
+
+// Principal is an authenticated caller
+type Principal struct {
+	// Identifies the caller, e.g. "token:ci" or the subject claim of a JWT
+	Subject string
+
+	// The method used to authenticate, one of MethodToken or MethodJWT
+	Method string
+}
+
+// Authenticator verifies a bearer credential and returns the caller it identifies
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*Principal, error)
+}
+
+// Chain tries each authenticator in order and returns the first principal found
+type Chain []Authenticator
+
+func (c Chain) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	errs := make([]error, 0, len(c))
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, credential)
+		if err == nil {
+			return principal, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, errors.Join(errs...))
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated caller
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated caller, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// CredentialFromHeader extracts the credential from an Authorization header.
+// Both bearer tokens and basic auth, with the token as the password, are accepted so browsers can prompt for a token.
+func CredentialFromHeader(header string) (string, error) {
+	scheme, value, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: missing credentials", ErrUnauthenticated)
+	}
+
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		return strings.TrimSpace(value), nil
+	case "basic":
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("%w: malformed basic credentials", ErrUnauthenticated)
+		}
+		_, password, _ := strings.Cut(string(decoded), ":")
+		if password == "" {
+			return "", fmt.Errorf("%w: missing credentials", ErrUnauthenticated)
+		}
+		return password, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported authorization scheme %s", ErrUnauthenticated, scheme)
+	}
+}
@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+)
+
+// newTestKeySet generates an RSA key and the JWKS document for its public key
+func newTestKeySet(t *testing.T) (*rsa.PrivateKey, KeySet) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	jwks := fmt.Sprintf(`{"keys": [{"kty": "RSA", "kid": "test", "use": "sig", "n": "%s", "e": "%s"}]}`,
+		base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+	)
+
+	keys, err := ParseKeySet([]byte(jwks))
+	if err != nil {
+		t.Fatalf("Failed to parse jwks: %v", err)
+	}
+
+	return privateKey, keys
+}
+
+func signTestToken(t *testing.T, privateKey *rsa.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+
+	signed, err := token.SignedString(privateKey)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	privateKey, keys := newTestKeySet(t)
+	authenticator := newJWTAuthenticator(keys, JWTConfig{Issuer: "https://issuer.example.com", Audience: "voer"})
+
+	valid := signTestToken(t, privateKey, jwt.MapClaims{
+		"sub": "alice",
+		"iss": "https://issuer.example.com",
+		"aud": "voer",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	principal, err := authenticator.Authenticate(context.Background(), valid)
+	if err != nil {
+		t.Fatalf("Expected token to be valid: %v", err)
+	}
+	if principal.Subject != "alice" || principal.Method != MethodJWT {
+		t.Fatalf("Unexpected principal: %+v", principal)
+	}
+
+	expired := signTestToken(t, privateKey, jwt.MapClaims{
+		"sub": "alice",
+		"iss": "https://issuer.example.com",
+		"aud": "voer",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+	if _, err := authenticator.Authenticate(context.Background(), expired); err == nil {
+		t.Fatalf("Expected expired token to be rejected")
+	}
+
+	wrongIssuer := signTestToken(t, privateKey, jwt.MapClaims{
+		"sub": "alice",
+		"iss": "https://other.example.com",
+		"aud": "voer",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if _, err := authenticator.Authenticate(context.Background(), wrongIssuer); err == nil {
+		t.Fatalf("Expected token from another issuer to be rejected")
+	}
+}
+
+func TestChainWrapsUnauthenticated(t *testing.T) {
+	_, keys := newTestKeySet(t)
+	chain := Chain{newJWTAuthenticator(keys, JWTConfig{})}
+
+	_, err := chain.Authenticate(context.Background(), "not-a-token")
+	if !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("Expected ErrUnauthenticated, got: %v", err)
+	}
+}
+
+func TestCredentialFromHeader(t *testing.T) {
+	credential, err := CredentialFromHeader("Bearer voer_abc")
+	if err != nil || credential != "voer_abc" {
+		t.Fatalf("Unexpected bearer credential %q: %v", credential, err)
+	}
+
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("anyone:voer_abc"))
+	credential, err = CredentialFromHeader(basic)
+	if err != nil || credential != "voer_abc" {
+		t.Fatalf("Unexpected basic credential %q: %v", credential, err)
+	}
+
+	if _, err := CredentialFromHeader(""); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("Expected ErrUnauthenticated for missing header, got: %v", err)
+	}
+}
+
+func TestPackagePatterns(t *testing.T) {
+	cases := []struct {
+		pattern     string
+		packageName string
+		expected    bool
+	}{
+		{"*", "payments.v1", true},
+		{"payments.*", "payments", true},
+		{"payments.*", "payments.v1", true},
+		{"payments.*", "paymentsv2", false},
+		{"payments.v1", "payments.v1", true},
+		{"payments.v1", "payments.v2", false},
+	}
+
+	for _, c := range cases {
+		if MatchPackagePattern(c.pattern, c.packageName) != c.expected {
+			t.Fatalf("Expected %q matching %q to be %v", c.pattern, c.packageName, c.expected)
+		}
+	}
+
+	if !PatternCovers("payments.*", "payments.billing.*") || PatternCovers("payments.billing.*", "payments.*") {
+		t.Fatalf("Unexpected pattern coverage")
+	}
+
+	if err := ValidatePackagePattern("pay*ments"); err == nil {
+		t.Fatalf("Expected error for invalid pattern")
+	}
+}
+
+func TestAuthorizerRoles(t *testing.T) {
+	store := repo.NewMemoryStore()
+	if err := store.RoleGrants().Create(&entity.RoleGrant{Subject: "alice", Role: string(RolePublisher), PackagePattern: "payments.*"}); err != nil {
+		t.Fatalf("Failed to create grant: %v", err)
+	}
+
+	authorizer := &Authorizer{Store: store, AdminSubjects: []string{"root"}}
+	alice := WithPrincipal(context.Background(), &Principal{Subject: "alice", Method: MethodJWT})
+
+	if err := authorizer.Authorize(alice, RoleReader, "payments.v1"); err != nil {
+		t.Fatalf("Expected publisher to include reader: %v", err)
+	}
+	if err := authorizer.Authorize(alice, RoleAdmin, "payments.v1"); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("Expected admin to be denied, got: %v", err)
+	}
+	if err := authorizer.Authorize(alice, RolePublisher, "orders.v1"); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("Expected other packages to be denied, got: %v", err)
+	}
+
+	if err := authorizer.AuthorizeAny(alice, RolePublisher); err != nil {
+		t.Fatalf("Expected publisher of some package to be allowed: %v", err)
+	}
+	if err := authorizer.AuthorizeAny(alice, RoleAdmin); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("Expected admin of no package to be denied, got: %v", err)
+	}
+
+	readable, err := authorizer.Filter(alice, RoleReader)
+	if err != nil || !readable("payments.v1") || readable("orders.v1") {
+		t.Fatalf("Expected the filter to only match payments packages (%v)", err)
+	}
+
+	root := WithPrincipal(context.Background(), &Principal{Subject: "root", Method: MethodToken})
+	if err := authorizer.AuthorizePattern(root, RoleAdmin, "*"); err != nil {
+		t.Fatalf("Expected admin subject to be allowed: %v", err)
+	}
+
+	var disabled *Authorizer
+	if err := disabled.Authorize(context.Background(), RoleAdmin, "payments.v1"); err != nil {
+		t.Fatalf("Expected nil authorizer to allow everything: %v", err)
+	}
+}
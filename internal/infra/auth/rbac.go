@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+)
+
+// ErrPermissionDenied is returned when the caller lacks the role required for an operation
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Role is a set of permissions granted on packages.
+// Each role includes the permissions of the roles before it.
+type Role string
+
+const (
+	// Download and validate packages
+	RoleReader Role = "reader"
+
+	// Upload new package versions
+	RolePublisher Role = "publisher"
+
+	// Delete package versions and manage grants
+	RoleAdmin Role = "admin"
+)
+
+// roleRanks orders roles from least to most privileged
+var roleRanks = map[Role]int{
+	RoleReader:    1,
+	RolePublisher: 2,
+	RoleAdmin:     3,
+}
+
+// ParseRole validates a role name
+func ParseRole(role string) (Role, error) {
+	if _, ok := roleRanks[Role(role)]; !ok {
+		return "", fmt.Errorf("invalid role %q, expected one of reader, publisher or admin", role)
+	}
+	return Role(role), nil
+}
+
+// Includes returns true if the role grants at least the permissions of another role
+func (r Role) Includes(other Role) bool {
+	return roleRanks[r] >= roleRanks[other]
+}
+
+// ValidatePackagePattern checks a package pattern is "*", an exact package name or a prefix ending in ".*"
+func ValidatePackagePattern(pattern string) error {
+	if pattern == "" {
+		return errors.New("package pattern is required")
+	}
+
+	base := strings.TrimSuffix(pattern, ".*")
+	if pattern != "*" && (base == "" || strings.Contains(base, "*")) {
+		return fmt.Errorf("invalid package pattern %q", pattern)
+	}
+
+	return nil
+}
+
+// MatchPackagePattern returns true if a package name matches a pattern.
+// "payments.*" matches "payments" and every package nested under it.
+func MatchPackagePattern(pattern, packageName string) bool {
+	if pattern == "*" || pattern == packageName {
+		return true
+	}
+
+	if prefix, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return packageName == prefix || strings.HasPrefix(packageName, prefix+".")
+	}
+
+	return false
+}
+
+// PatternCovers returns true if every package matching other also matches pattern
+func PatternCovers(pattern, other string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if other == "*" {
+		return false
+	}
+	return MatchPackagePattern(pattern, strings.TrimSuffix(other, ".*"))
+}
+
+// Authorizer checks the caller's role grants. A nil Authorizer allows everything, for when authentication is disabled.
+type Authorizer struct {
+	Store repo.Store
+
+	// Subjects granted the admin role on every package, used to bootstrap grants
+	AdminSubjects []string
+}
+
+// callerGrants lists the grants of the caller, and reports whether the caller is an admin of every package
+func (a *Authorizer) callerGrants(ctx context.Context) (*Principal, []entity.RoleGrant, bool, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, nil, false, ErrUnauthenticated
+	}
+
+	if slices.Contains(a.AdminSubjects, principal.Subject) {
+		return principal, nil, true, nil
+	}
+
+	grants, err := a.Store.WithContext(ctx).RoleGrants().List(principal.Subject)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return principal, grants, false, nil
+}
+
+// authorize checks whether the caller holds a role on packages selected by matches
+func (a *Authorizer) authorize(ctx context.Context, role Role, target string, matches func(pattern string) bool) error {
+	if a == nil {
+		return nil
+	}
+
+	principal, grants, admin, err := a.callerGrants(ctx)
+	if err != nil || admin {
+		return err
+	}
+
+	for _, grant := range grants {
+		if Role(grant.Role).Includes(role) && matches(grant.PackagePattern) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s requires the %s role on %s", ErrPermissionDenied, principal.Subject, role, target)
+}
+
+// Filter returns a function reporting whether the caller holds a role on a package. The caller's grants are read
+// once, so lists are filtered without a lookup for each package.
+func (a *Authorizer) Filter(ctx context.Context, role Role) (func(packageName string) bool, error) {
+	if a == nil {
+		return func(string) bool { return true }, nil
+	}
+
+	_, grants, admin, err := a.callerGrants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if admin {
+		return func(string) bool { return true }, nil
+	}
+
+	return func(packageName string) bool {
+		for _, grant := range grants {
+			if Role(grant.Role).Includes(role) && MatchPackagePattern(grant.PackagePattern, packageName) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// Authorize checks the caller holds a role on a package
+func (a *Authorizer) Authorize(ctx context.Context, role Role, packageName string) error {
+	return a.authorize(ctx, role, packageName, func(pattern string) bool {
+		return MatchPackagePattern(pattern, packageName)
+	})
+}
+
+// AuthorizePattern checks the caller holds a role on every package matching a pattern
+func (a *Authorizer) AuthorizePattern(ctx context.Context, role Role, packagePattern string) error {
+	return a.authorize(ctx, role, packagePattern, func(pattern string) bool {
+		return PatternCovers(pattern, packagePattern)
+	})
+}
+
+// AuthorizeAny checks the caller holds a role on at least one package
+func (a *Authorizer) AuthorizeAny(ctx context.Context, role Role) error {
+	return a.authorize(ctx, role, "any package", func(pattern string) bool {
+		return true
+	})
+}
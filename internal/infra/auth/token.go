@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+// tokenPrefix marks static API tokens so they are not mistaken for JWTs
+const tokenPrefix = "voer_"
+
+// HashToken returns the hash under which a token is stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueAPIToken generates a new API token and stores its hash.
+// The plaintext token is only returned once.
+func IssueAPIToken(store repo.Store, name string, expiresAt *time.Time) (string, *entity.APIToken, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := tokenPrefix + hex.EncodeToString(secret)
+
+	apiToken := &entity.APIToken{
+		Name:      name,
+		TokenHash: HashToken(token),
+		ExpiresAt: expiresAt,
+	}
+	if err := store.APITokens().Create(apiToken); err != nil {
+		return "", nil, err
+	}
+
+	return token, apiToken, nil
+}
+
+// TokenAuthenticator authenticates static API tokens stored in the database
+type TokenAuthenticator struct {
+	Store repo.Store
+}
+
+func (a *TokenAuthenticator) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if !strings.HasPrefix(credential, tokenPrefix) {
+		return nil, fmt.Errorf("not an api token")
+	}
+
+	tokens := a.Store.WithContext(ctx).APITokens()
+	apiToken, err := tokens.FindByHash(HashToken(credential))
+	if err != nil {
+		return nil, err
+	}
+
+	if apiToken == nil {
+		return nil, fmt.Errorf("unknown api token")
+	}
+
+	now := time.Now()
+	if apiToken.ExpiresAt != nil && now.After(*apiToken.ExpiresAt) {
+		return nil, fmt.Errorf("api token %s has expired", apiToken.Name)
+	}
+
+	if err := tokens.Touch(apiToken.ID, now); err != nil {
+		logging.Logger.Warn("Failed to record api token usage", "token", apiToken.Name, "error", err)
+	}
+
+	return &Principal{
+		Subject: "token:" + apiToken.Name,
+		Method:  MethodToken,
+	}, nil
+}
@@ -0,0 +1,202 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+// DefaultReloadInterval is the minimum time between checks for changed certificate files
+const DefaultReloadInterval = 10 * time.Second
+
+// ServerConfig locates the certificate files used by the server
+type ServerConfig struct {
+	CertPath string
+	KeyPath  string
+
+	// CA used to verify client certificates. Client certificates are required when set.
+	ClientCAPath string
+}
+
+// ClientConfig locates the certificate files used by clients
+type ClientConfig struct {
+	// CA used to verify the server. The system roots are used when unset.
+	CAPath string
+
+	// Client certificate presented for mTLS
+	CertPath string
+	KeyPath  string
+}
+
+// loadCertPool reads a PEM bundle of CA certificates
+func loadCertPool(path string) (*x509.CertPool, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(contents) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+
+	return pool, nil
+}
+
+// Reloader serves the server's certificate and client CAs, reloading them when their files change.
+// Files are checked at most once per interval, during TLS handshakes.
+type Reloader struct {
+	config   ServerConfig
+	interval time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+// NewReloader loads the server's certificate files
+func NewReloader(config ServerConfig, interval time.Duration) (*Reloader, error) {
+	if config.CertPath == "" || config.KeyPath == "" {
+		return nil, errors.New("both a certificate and a key are required")
+	}
+
+	r := &Reloader{
+		config:   config,
+		interval: interval,
+		modTimes: make(map[string]time.Time),
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// paths returns the files watched for changes
+func (r *Reloader) paths() []string {
+	paths := []string{r.config.CertPath, r.config.KeyPath}
+	if r.config.ClientCAPath != "" {
+		paths = append(paths, r.config.ClientCAPath)
+	}
+	return paths
+}
+
+// load reads every certificate file. Must be called with the lock held, or before the reloader is shared.
+func (r *Reloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.config.CertPath, r.config.KeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.config.ClientCAPath != "" {
+		clientCAs, err = loadCertPool(r.config.ClientCAPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	modTimes := make(map[string]time.Time)
+	for _, path := range r.paths() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat certificate file: %w", err)
+		}
+		modTimes[path] = info.ModTime()
+	}
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.lastCheck = time.Now()
+
+	return nil
+}
+
+// changed returns true if any watched file was modified since it was loaded
+func (r *Reloader) changed() bool {
+	for _, path := range r.paths() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false
+		}
+		if !info.ModTime().Equal(r.modTimes[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// current returns the loaded certificate and client CAs, reloading them first if their files changed.
+// The previous certificates are kept if reloading fails, e.g. while files are being replaced.
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) >= r.interval {
+		r.lastCheck = time.Now()
+
+		if r.changed() {
+			if err := r.load(); err != nil {
+				logging.Logger.Error("Failed to reload certificates", "error", err)
+			} else {
+				logging.Logger.Info("Reloaded certificates", "cert", r.config.CertPath)
+			}
+		}
+	}
+
+	return r.cert, r.clientCAs
+}
+
+// TLSConfig returns a server TLS configuration using the current certificates on every handshake
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, clientCAs := r.current()
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+			}
+			if clientCAs != nil {
+				config.ClientCAs = clientCAs
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+
+			return config, nil
+		},
+	}
+}
+
+// ClientTLSConfig builds a client TLS configuration
+func ClientTLSConfig(config ClientConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if config.CAPath != "" {
+		pool, err := loadCertPool(config.CAPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertPath != "" || config.KeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertPath, config.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,193 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse CA: %v", err)
+	}
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a certificate and key signed by the CA to dir, returning their paths
+func (ca *testCA) issue(t *testing.T, dir, name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	return certPath, keyPath
+}
+
+// serverSerial returns the serial number of the certificate the reloader currently serves
+func serverSerial(t *testing.T, reloader *Reloader) int64 {
+	config, err := reloader.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("Failed to get config: %v", err)
+	}
+
+	leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+func TestReloaderReloadsChangedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certPath, keyPath := ca.issue(t, dir, "server", 2, x509.ExtKeyUsageServerAuth)
+
+	reloader, err := NewReloader(ServerConfig{CertPath: certPath, KeyPath: keyPath}, 0)
+	if err != nil {
+		t.Fatalf("Failed to create reloader: %v", err)
+	}
+
+	if serial := serverSerial(t, reloader); serial != 2 {
+		t.Fatalf("Expected serial 2, got: %d", serial)
+	}
+
+	// Replace the certificate and make sure the modification time changes
+	ca.issue(t, dir, "server", 3, x509.ExtKeyUsageServerAuth)
+	future := time.Now().Add(time.Minute)
+	for _, path := range []string{certPath, keyPath} {
+		if err := os.Chtimes(path, future, future); err != nil {
+			t.Fatalf("Failed to update modification time: %v", err)
+		}
+	}
+
+	if serial := serverSerial(t, reloader); serial != 3 {
+		t.Fatalf("Expected reloaded serial 3, got: %d", serial)
+	}
+}
+
+func TestMutualTLSHandshake(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caPath := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caPath, ca.pem, 0600); err != nil {
+		t.Fatalf("Failed to write CA: %v", err)
+	}
+
+	serverCert, serverKey := ca.issue(t, dir, "server", 2, x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, dir, "client", 3, x509.ExtKeyUsageClientAuth)
+
+	reloader, err := NewReloader(ServerConfig{CertPath: serverCert, KeyPath: serverKey, ClientCAPath: caPath}, DefaultReloadInterval)
+	if err != nil {
+		t.Fatalf("Failed to create reloader: %v", err)
+	}
+
+	handshake := func(clientConfig ClientConfig) error {
+		tlsConfig, err := ClientTLSConfig(clientConfig)
+		if err != nil {
+			t.Fatalf("Failed to create client config: %v", err)
+		}
+		tlsConfig.ServerName = "localhost"
+
+		listener, err := tls.Listen("tcp", "127.0.0.1:0", reloader.TLSConfig())
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		defer listener.Close()
+
+		// With TLS 1.3 the client finishes its handshake before the server verifies it, so report the server's result
+		serverErr := make(chan error, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				serverErr <- err
+				return
+			}
+			defer conn.Close()
+			serverErr <- conn.(*tls.Conn).Handshake()
+		}()
+
+		clientConn, err := tls.Dial("tcp", listener.Addr().String(), tlsConfig)
+		if err != nil {
+			<-serverErr
+			return err
+		}
+		defer clientConn.Close()
+
+		return <-serverErr
+	}
+
+	if err := handshake(ClientConfig{CAPath: caPath, CertPath: clientCert, KeyPath: clientKey}); err != nil {
+		t.Fatalf("Expected handshake with client certificate to succeed: %v", err)
+	}
+
+	if err := handshake(ClientConfig{CAPath: caPath}); err == nil {
+		t.Fatalf("Expected handshake without client certificate to fail")
+	}
+}
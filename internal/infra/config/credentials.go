@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Credentials holds the tokens saved by `voer login`, keyed by registry endpoint
+type Credentials struct {
+	Tokens map[string]string `yaml:"tokens"`
+}
+
+// CredentialsPath returns the path of the user's credentials file
+func CredentialsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "voer", "credentials.yaml"), nil
+}
+
+// LoadCredentials reads the user's credentials file. Returns empty credentials if it does not exist.
+func LoadCredentials() (*Credentials, error) {
+	path, err := CredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := &Credentials{Tokens: make(map[string]string)}
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return credentials, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	if err := yaml.Unmarshal(contents, credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file %s: %w", path, err)
+	}
+	if credentials.Tokens == nil {
+		credentials.Tokens = make(map[string]string)
+	}
+
+	return credentials, nil
+}
+
+// SaveCredentials writes the user's credentials file, readable only by the user
+func SaveCredentials(credentials *Credentials) error {
+	path, err := CredentialsPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+
+	contents, err := yaml.Marshal(credentials)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	if err := os.WriteFile(path, contents, 0600); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+
+	return nil
+}
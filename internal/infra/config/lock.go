@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is the name of the lock file written next to the workspace file
+const LockFileName = "voer.lock"
+
+// lockFileHeader is written at the top of every lock file
+const lockFileHeader = "# Generated by `voer pull`. Do not edit.\n"
+
+// LockedPackage is an exact package version recorded in the lock file
+type LockedPackage struct {
+	Package     string `yaml:"package"`
+	Version     uint64 `yaml:"version"`
+	ContentHash string `yaml:"content_hash"`
+
+	// Import paths of the files written for the package
+	Files []string `yaml:"files"`
+}
+
+// LockFile records the exact versions resolved by `voer pull`
+type LockFile struct {
+	Packages []LockedPackage `yaml:"packages"`
+}
+
+// Find returns the locked entry for a package, if any
+func (l *LockFile) Find(packageName string) (*LockedPackage, bool) {
+	for i := range l.Packages {
+		if l.Packages[i].Package == packageName {
+			return &l.Packages[i], true
+		}
+	}
+	return nil, false
+}
+
+// LoadLockFile reads a lock file. Returns an empty lock file if it does not exist.
+func LoadLockFile(path string) (*LockFile, error) {
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &LockFile{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	var lock LockFile
+	if err := yaml.Unmarshal(contents, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file %s: %w", path, err)
+	}
+
+	return &lock, nil
+}
+
+// WriteLockFile writes a lock file with packages sorted by name so diffs stay stable
+func WriteLockFile(path string, lock *LockFile) error {
+	sorted := &LockFile{Packages: append([]LockedPackage{}, lock.Packages...)}
+	sort.Slice(sorted.Packages, func(i, j int) bool {
+		return sorted.Packages[i].Package < sorted.Packages[j].Package
+	})
+	for i := range sorted.Packages {
+		sort.Strings(sorted.Packages[i].Files)
+	}
+
+	contents, err := yaml.Marshal(sorted)
+	if err != nil {
+		return fmt.Errorf("failed to encode lock file: %w", err)
+	}
+
+	if err := os.WriteFile(path, append([]byte(lockFileHeader), contents...), 0644); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+
+	return nil
+}
+
+// versionBound is a single comparison within a version constraint
+type versionBound struct {
+	op      string
+	version uint64
+}
+
+// VersionConstraint restricts which versions of a package may be used
+type VersionConstraint struct {
+	bounds []versionBound
+}
+
+// ParseVersionConstraint parses a constraint such as "3", "=3", ">=2", "<5" or ">=2,<5".
+// An empty constraint or "latest" matches any version.
+func ParseVersionConstraint(constraint string) (VersionConstraint, error) {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" || constraint == "latest" {
+		return VersionConstraint{}, nil
+	}
+
+	var bounds []versionBound
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+
+		op := "="
+		for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(strings.TrimPrefix(part, candidate))
+				break
+			}
+		}
+
+		version, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return VersionConstraint{}, fmt.Errorf("invalid version constraint %q", constraint)
+		}
+
+		bounds = append(bounds, versionBound{op: op, version: version})
+	}
+
+	return VersionConstraint{bounds: bounds}, nil
+}
+
+// Matches returns true if a version satisfies every bound of the constraint
+func (c VersionConstraint) Matches(version uint64) bool {
+	for _, bound := range c.bounds {
+		var ok bool
+		switch bound.op {
+		case ">=":
+			ok = version >= bound.version
+		case "<=":
+			ok = version <= bound.version
+		case ">":
+			ok = version > bound.version
+		case "<":
+			ok = version < bound.version
+		default:
+			ok = version == bound.version
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
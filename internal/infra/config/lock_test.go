@@ -0,0 +1,66 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestVersionConstraintMatches(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    uint64
+		expected   bool
+	}{
+		{"", 7, true},
+		{"latest", 7, true},
+		{"3", 3, true},
+		{"3", 4, false},
+		{">=2,<5", 4, true},
+		{">=2,<5", 5, false},
+		{"> 2", 2, false},
+	}
+
+	for _, c := range cases {
+		constraint, err := ParseVersionConstraint(c.constraint)
+		if err != nil {
+			t.Fatalf("Failed to parse constraint %q: %v", c.constraint, err)
+		}
+
+		if constraint.Matches(c.version) != c.expected {
+			t.Fatalf("Expected %q matching version %d to be %v", c.constraint, c.version, c.expected)
+		}
+	}
+
+	if _, err := ParseVersionConstraint(">=two"); err == nil {
+		t.Fatalf("Expected error for invalid constraint")
+	}
+}
+
+func TestLockFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+
+	lock := &LockFile{
+		Packages: []LockedPackage{
+			{Package: "orders.v1", Version: 2, ContentHash: "def", Files: []string{"orders/v1/order.proto"}},
+			{Package: "common.v1", Version: 5, ContentHash: "abc", Files: []string{"common/v1/money.proto"}},
+		},
+	}
+
+	if err := WriteLockFile(path, lock); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+
+	loaded, err := LoadLockFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load lock file: %v", err)
+	}
+
+	if len(loaded.Packages) != 2 || loaded.Packages[0].Package != "common.v1" {
+		t.Fatalf("Expected packages to be sorted by name, got: %v", loaded.Packages)
+	}
+
+	locked, ok := loaded.Find("orders.v1")
+	if !ok || locked.Version != 2 || locked.ContentHash != "def" {
+		t.Fatalf("Unexpected locked package: %v", locked)
+	}
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cgund98/voer/internal/proto"
+)
+
+const (
+	// WorkspaceFileName is the name of the workspace file discovered by the CLI
+	WorkspaceFileName = "voer.yaml"
+
+	// DefaultDepsDir is the directory dependencies are pulled into when deps_dir is not set
+	DefaultDepsDir = "voer_deps"
+)
+
+// CompatConfig holds client-side compatibility settings
+type CompatConfig struct {
+	// Exit with an error from `voer validate` when the schema is not backwards compatible
+	FailOnIncompatible bool `yaml:"fail_on_incompatible"`
+}
+
+// Dependency is a registry package the workspace depends on
+type Dependency struct {
+	Package string `yaml:"package"`
+
+	// Version constraint, e.g. "3", ">=2", ">=2,<5" or "latest". Defaults to the latest version.
+	Version string `yaml:"version"`
+}
+
+// Workspace is a project configuration file shared by the CLI commands
+type Workspace struct {
+	// Directory containing the workspace file. Relative paths are resolved against it.
+	Dir string `yaml:"-"`
+
+	// The registry's gRPC endpoint
+	Endpoint string `yaml:"endpoint"`
+
+	// Directories scanned for .proto files when no --proto flag is given
+	Roots []string `yaml:"roots"`
+
+	// Additional directories searched when resolving imports
+	ImportPaths []string `yaml:"import_paths"`
+
+	// Directories excluded when scanning roots
+	Exclude []string `yaml:"exclude"`
+
+	// Mapping of package names to the directories holding their files
+	Packages map[string]string `yaml:"packages"`
+
+	// Registry packages pulled by `voer pull`
+	Dependencies []Dependency `yaml:"dependencies"`
+
+	// Directory dependencies are pulled into
+	DepsDir string `yaml:"deps_dir"`
+
+	Lint     *proto.LintConfig     `yaml:"lint"`
+	Compat   CompatConfig          `yaml:"compat"`
+	Generate *proto.GenerateConfig `yaml:"generate"`
+}
+
+// ResolvePath resolves a path relative to the workspace directory
+func (w *Workspace) ResolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(w.Dir, path)
+}
+
+// ResolvePaths resolves a list of paths relative to the workspace directory
+func (w *Workspace) ResolvePaths(paths []string) []string {
+	resolved := make([]string, 0, len(paths))
+	for _, path := range paths {
+		resolved = append(resolved, w.ResolvePath(path))
+	}
+	return resolved
+}
+
+// PackageDir returns the directory mapped to a package, if any
+func (w *Workspace) PackageDir(packageName string) (string, bool) {
+	dir, ok := w.Packages[packageName]
+	if !ok {
+		return "", false
+	}
+	return w.ResolvePath(dir), true
+}
+
+// ResolveDepsDir returns the directory dependencies are pulled into
+func (w *Workspace) ResolveDepsDir() string {
+	if w.DepsDir == "" {
+		return w.ResolvePath(DefaultDepsDir)
+	}
+	return w.ResolvePath(w.DepsDir)
+}
+
+// LockFilePath returns the path of the workspace's lock file
+func (w *Workspace) LockFilePath() string {
+	return filepath.Join(w.Dir, LockFileName)
+}
+
+// LoadWorkspace reads a workspace file from a given path
+func LoadWorkspace(path string) (*Workspace, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace file: %w", err)
+	}
+
+	var workspace Workspace
+	if err := yaml.Unmarshal(contents, &workspace); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace file %s: %w", path, err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	workspace.Dir = filepath.Dir(absPath)
+
+	if workspace.Lint != nil {
+		if err := workspace.Lint.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid lint configuration in %s: %w", path, err)
+		}
+	}
+
+	if workspace.Generate != nil {
+		if err := workspace.Generate.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid generate configuration in %s: %w", path, err)
+		}
+	}
+
+	for _, dep := range workspace.Dependencies {
+		if dep.Package == "" {
+			return nil, fmt.Errorf("invalid dependency in %s: package is required", path)
+		}
+		if _, err := ParseVersionConstraint(dep.Version); err != nil {
+			return nil, fmt.Errorf("invalid dependency %s in %s: %w", dep.Package, path, err)
+		}
+	}
+
+	return &workspace, nil
+}
+
+// FindWorkspace looks for a workspace file in startDir and each of its parents.
+// Returns nil if no workspace file is found.
+func FindWorkspace(startDir string) (*Workspace, error) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, WorkspaceFileName)
+		if _, err := os.Stat(path); err == nil {
+			return LoadWorkspace(path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to check for workspace file: %w", err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindWorkspaceInParent(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	contents := `
+endpoint: registry:8000
+roots:
+  - proto
+exclude:
+  - proto/vendor
+packages:
+  helloworld.v1: proto/helloworld/v1
+lint:
+  except:
+    - COMMENT_MESSAGE
+compat:
+  fail_on_incompatible: true
+`
+	if err := os.WriteFile(filepath.Join(root, WorkspaceFileName), []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write workspace file: %v", err)
+	}
+
+	workspace, err := FindWorkspace(nested)
+	if err != nil {
+		t.Fatalf("Failed to find workspace: %v", err)
+	}
+	if workspace == nil {
+		t.Fatalf("Expected workspace to be found")
+	}
+
+	if workspace.Endpoint != "registry:8000" {
+		t.Fatalf("Expected endpoint registry:8000, got: %s", workspace.Endpoint)
+	}
+
+	roots := workspace.ResolvePaths(workspace.Roots)
+	if len(roots) != 1 || roots[0] != filepath.Join(workspace.Dir, "proto") {
+		t.Fatalf("Expected roots to be resolved relative to workspace, got: %v", roots)
+	}
+
+	dir, ok := workspace.PackageDir("helloworld.v1")
+	if !ok || dir != filepath.Join(workspace.Dir, "proto", "helloworld", "v1") {
+		t.Fatalf("Expected package directory to be resolved, got: %s", dir)
+	}
+
+	if !workspace.Compat.FailOnIncompatible {
+		t.Fatalf("Expected compat settings to be parsed")
+	}
+}
+
+func TestFindWorkspaceMissing(t *testing.T) {
+	workspace, err := FindWorkspace(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to find workspace: %v", err)
+	}
+	if workspace != nil {
+		t.Fatalf("Expected no workspace, got: %v", workspace)
+	}
+}
+
+func TestLoadWorkspaceInvalidLintRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), WorkspaceFileName)
+	if err := os.WriteFile(path, []byte("lint:\n  use: [NOT_A_RULE]\n"), 0644); err != nil {
+		t.Fatalf("Failed to write workspace file: %v", err)
+	}
+
+	if _, err := LoadWorkspace(path); err == nil {
+		t.Fatalf("Expected error for unknown lint rule")
+	}
+}
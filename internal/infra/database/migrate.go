@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+// Migrate runs the goose migrations found in the "migrations" directory of a file system, along with Go migrations
+// for steps that SQL cannot express. Each backend embeds its own set of migrations, written in its SQL dialect.
+func Migrate(db *gorm.DB, migrations fs.FS, dialect goose.Dialect, goMigrations ...*goose.Migration) error {
+	sqlDb, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql db: %w", err)
+	}
+
+	migrationsDir, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	provider, err := goose.NewProvider(dialect, sqlDb, migrationsDir, goose.WithGoMigrations(goMigrations...))
+	if err != nil {
+		return fmt.Errorf("failed to initialize migrations: %w", err)
+	}
+
+	results, err := provider.Up(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	for _, result := range results {
+		logging.Logger.Info("Applied migration", "version", result.Source.Version, "duration", result.Duration.String(), "source", "goose")
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+
+	"github.com/cgund98/voer/internal/proto"
+)
+
+// RehashPackageVersionsMigration recomputes the content hash of every package version from its files. Hashes used to
+// cover the base name of each file rather than its import path, so files with the same name in different directories
+// could be swapped without changing the hash. Both backends run this step, which needs the hashing code.
+var RehashPackageVersionsMigration = goose.NewGoMigration(20250630000000, &goose.GoFunc{RunTx: rehashPackageVersions}, nil)
+
+func rehashPackageVersions(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT package_version_files.package_version_id, package_version_files.import_path, blobs.contents
+		FROM package_version_files JOIN blobs ON blobs.digest = package_version_files.digest`)
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	defer rows.Close()
+
+	inputs := make(map[int64][]proto.ParseStringInput)
+	for rows.Next() {
+		var packageVersionID int64
+		var input proto.ParseStringInput
+		if err := rows.Scan(&packageVersionID, &input.FileName, &input.FileContents); err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		inputs[packageVersionID] = append(inputs[packageVersionID], input)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	// Both SQLite and PostgreSQL bind numbered parameters
+	for packageVersionID, files := range inputs {
+		_, err := tx.ExecContext(ctx, "UPDATE package_versions SET content_hash = $1 WHERE id = $2", proto.HashFiles(files...), packageVersionID)
+		if err != nil {
+			return fmt.Errorf("failed to update package version: %w", err)
+		}
+	}
+
+	return nil
+}
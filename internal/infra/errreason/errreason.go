@@ -0,0 +1,17 @@
+// Package errreason names the ErrorInfo reasons attached to the registry's error statuses. The server sets them and
+// clients read them, so both share this package rather than depend on each other.
+package errreason
+
+// Domain is the domain of the ErrorInfo details attached to error statuses
+const Domain = "voer"
+
+// Reasons of the ErrorInfo details attached to error statuses, besides the ctrl.Reason constants of failed
+// preconditions
+const (
+	NotFound           = "NOT_FOUND"
+	AlreadyExists      = "ALREADY_EXISTS"
+	InvalidArgument    = "INVALID_ARGUMENT"
+	InvalidProto       = "INVALID_PROTO"
+	IncompatibleSchema = "INCOMPATIBLE_SCHEMA"
+	Internal           = "INTERNAL"
+)
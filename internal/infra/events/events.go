@@ -0,0 +1,107 @@
+// Package events publishes registry events to subscribers in the same process, such as change streams and webhooks.
+// Events are not persisted: subscribers only receive events published while they are subscribed.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+const (
+	// Event types
+	TypePackageVersionCreated  = "package_version.created"
+	TypePackageVersionDeleted  = "package_version.deleted"
+	TypePackageVersionRestored = "package_version.restored"
+)
+
+// Types lists every event type
+var Types = []string{TypePackageVersionCreated, TypePackageVersionDeleted, TypePackageVersionRestored}
+
+// Event is a change to the registry. Its JSON encoding is the body of webhook deliveries.
+type Event struct {
+	// Unique ID of the event, shared by every delivery of it
+	ID string `json:"id"`
+
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Subject of the caller that caused the event
+	Actor string `json:"actor"`
+
+	PackageName string `json:"packageName"`
+	Version     uint   `json:"version"`
+}
+
+// Subscription receives the events published to a bus until it is closed
+type Subscription struct {
+	// Events published since subscribing
+	C <-chan Event
+
+	events chan Event
+	bus    *Bus
+}
+
+// Close stops delivering events to the subscription
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	delete(s.bus.subscriptions, s)
+}
+
+// Bus fans events out to every subscription. Publishing to a nil bus does nothing, so events are optional.
+type Bus struct {
+	mu            sync.Mutex
+	subscriptions map[*Subscription]bool
+}
+
+func NewBus() *Bus {
+	return &Bus{subscriptions: make(map[*Subscription]bool)}
+}
+
+// Subscribe returns a subscription buffering up to size events. Events are dropped for subscriptions whose buffer is
+// full, so a slow subscriber never blocks the registry.
+func (b *Bus) Subscribe(size int) *Subscription {
+	events := make(chan Event, size)
+	subscription := &Subscription{C: events, events: events, bus: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions[subscription] = true
+	return subscription
+}
+
+// Publish sends an event to every subscription, attributed to the caller in the context unless it names an actor
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if b == nil {
+		return
+	}
+
+	event.ID = newEventID()
+	event.CreatedAt = time.Now().UTC()
+	if event.Actor == "" {
+		event.Actor = audit.ActorFromContext(ctx)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for subscription := range b.subscriptions {
+		select {
+		case subscription.events <- event:
+		default:
+			logging.Logger.WarnContext(ctx, "Dropped event for slow subscriber", "event", event.ID, "type", event.Type, "package", event.PackageName)
+		}
+	}
+}
+
+// newEventID returns a random event ID
+func newEventID() string {
+	id := make([]byte, 12)
+	_, _ = rand.Read(id)
+	return "evt_" + hex.EncodeToString(id)
+}
@@ -0,0 +1,51 @@
+// Package health reports whether the server is ready to receive requests, for load balancers and readiness probes.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrShuttingDown is returned by readiness checks once the server starts shutting down
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Check verifies a dependency of the server, e.g. by pinging its database
+type Check func(ctx context.Context) error
+
+// Readiness reports whether the server should receive new requests. The server stops being ready as soon as it starts
+// shutting down, so load balancers stop routing requests to it before its listeners close.
+type Readiness struct {
+	checks       []Check
+	shuttingDown atomic.Bool
+}
+
+func NewReadiness(checks ...Check) *Readiness {
+	return &Readiness{checks: checks}
+}
+
+// ShutDown marks the server as shutting down. It is not ready from then on.
+func (r *Readiness) ShutDown() {
+	if r == nil {
+		return
+	}
+	r.shuttingDown.Store(true)
+}
+
+// Ready returns nil when the server is ready to receive requests, and why it is not otherwise.
+// A nil Readiness is always ready.
+func (r *Readiness) Ready(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	if r.shuttingDown.Load() {
+		return ErrShuttingDown
+	}
+
+	for _, check := range r.checks {
+		if err := check(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package logging
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"slices"
 	"strings"
 )
 
@@ -18,11 +20,40 @@ func getLevel() slog.Level {
 	}
 }
 
-var Logger *slog.Logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+var Logger *slog.Logger = slog.New(contextHandler{slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 	Level: getLevel(),
-}))
+})})
 
 func Fatalf(msg string, args ...any) {
 	Logger.Error(msg, args...)
 	os.Exit(1)
 }
+
+type attrsKey struct{}
+
+// WithAttrs returns a context whose log records carry the given attributes, e.g. the ID of the request being handled.
+// Attributes are only added to records logged with a context, e.g. with Logger.InfoContext.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return context.WithValue(ctx, attrsKey{}, append(slices.Clip(existing), attrs...))
+}
+
+// contextHandler adds the attributes of a record's context to the record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
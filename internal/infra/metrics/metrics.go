@@ -0,0 +1,129 @@
+// Package metrics defines the Prometheus metrics served on the frontend's /metrics endpoint
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+const namespace = "voer"
+
+const (
+	// Upload and validation results
+	ResultCreated          = "created"
+	ResultUnchanged        = "unchanged"
+	ResultDryRun           = "dry_run"
+	ResultValid            = "valid"
+	ResultLintFailed       = "lint_failed"
+	ResultIncompatible     = "incompatible"
+	ResultDeprecatedImport = "deprecated_import"
+	ResultFailed           = "failed"
+)
+
+// Registry holds every metric exported by the server, along with Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	GRPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC requests handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	GRPCRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Time taken to handle gRPC requests, by method. Streams are measured until they end.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Frontend requests handled, by method, route and status code.",
+	}, []string{"method", "route", "code"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to handle frontend requests, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	Uploads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "uploads_total",
+		Help:      "Package version uploads, by result.",
+	}, []string{"result"})
+
+	Validations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "validations_total",
+		Help:      "Package version validations, by result.",
+	}, []string{"result"})
+
+	CompatibilityViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "compatibility_violations_total",
+		Help:      "Backwards compatibility violations found in uploads and validations, by rule.",
+	}, []string{"rule"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time taken by database queries, by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation", "table"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		GRPCRequests,
+		GRPCRequestDuration,
+		HTTPRequests,
+		HTTPRequestDuration,
+		Uploads,
+		Validations,
+		CompatibilityViolations,
+		DBQueryDuration,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterStoreGauges registers gauges of the registry's size, counted from the store on each scrape
+func RegisterStoreGauges(store repo.Store) {
+	gauge := func(name, help string, count func() (int64, error)) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Namespace: namespace, Name: name, Help: help}, func() float64 {
+			value, err := count()
+			if err != nil {
+				logging.Logger.Warn("Failed to count for metric", "metric", name, "error", err)
+				return 0
+			}
+			return float64(value)
+		})
+	}
+
+	Registry.MustRegister(
+		gauge("packages", "Packages in the registry.", func() (int64, error) {
+			return store.Packages().Count("")
+		}),
+		gauge("package_versions", "Package versions in the registry, excluding deleted versions.", func() (int64, error) {
+			return store.PackageVersions().Count()
+		}),
+		gauge("messages", "Visible messages in the registry.", func() (int64, error) {
+			return store.Messages().Count("")
+		}),
+	)
+}
@@ -0,0 +1,153 @@
+-- +goose Up
+-- Schema of the SQLite migrations up to 20250620000000_add_package_aliases
+CREATE TABLE packages (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    package_name text NOT NULL UNIQUE,
+    latest_version_id bigint
+);
+
+CREATE TABLE package_versions (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    package_id bigint NOT NULL REFERENCES packages (id) ON DELETE CASCADE,
+    version integer NOT NULL,
+    content_hash text NOT NULL DEFAULT '',
+    deleted_at timestamptz,
+    deprecated_at timestamptz,
+    deprecation_reason text NOT NULL DEFAULT '',
+    deprecation_replacement text NOT NULL DEFAULT '',
+    UNIQUE (package_id, version)
+);
+
+CREATE INDEX idx_package_versions_deleted_at ON package_versions (deleted_at);
+
+ALTER TABLE packages
+    ADD CONSTRAINT fk_packages_latest_version FOREIGN KEY (latest_version_id) REFERENCES package_versions (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_packages_latest_version_id ON packages (latest_version_id);
+
+CREATE TABLE package_version_files (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    package_version_id bigint NOT NULL REFERENCES package_versions (id) ON DELETE CASCADE,
+    file_name text NOT NULL,
+    file_contents text NOT NULL,
+    import_path text NOT NULL DEFAULT '',
+    UNIQUE (package_version_id, file_name)
+);
+
+CREATE INDEX idx_package_version_files_import_path ON package_version_files (import_path);
+
+CREATE TABLE messages (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    package_id bigint NOT NULL REFERENCES packages (id) ON DELETE CASCADE,
+    name text NOT NULL,
+    proto_body text NOT NULL,
+    latest_version_id bigint,
+    deprecated_at timestamptz,
+    deprecation_reason text NOT NULL DEFAULT '',
+    deprecation_replacement text NOT NULL DEFAULT '',
+    UNIQUE (package_id, name)
+);
+
+CREATE TABLE message_versions (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    message_id bigint NOT NULL REFERENCES messages (id) ON DELETE CASCADE,
+    package_version_id bigint NOT NULL REFERENCES package_versions (id) ON DELETE CASCADE,
+    version integer NOT NULL,
+    proto_body text NOT NULL,
+    serialized_schema text NOT NULL,
+    content_hash text NOT NULL DEFAULT '',
+    UNIQUE (message_id, version)
+);
+
+ALTER TABLE messages
+    ADD CONSTRAINT fk_messages_latest_version FOREIGN KEY (latest_version_id) REFERENCES message_versions (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_messages_latest_version_id ON messages (latest_version_id);
+
+CREATE TABLE package_aliases (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    alias_name text NOT NULL UNIQUE,
+    package_id bigint NOT NULL REFERENCES packages (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_package_aliases_package_id ON package_aliases (package_id);
+
+CREATE TABLE api_tokens (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    name text NOT NULL UNIQUE,
+    token_hash text NOT NULL UNIQUE,
+    expires_at timestamptz,
+    last_used_at timestamptz
+);
+
+CREATE TABLE role_grants (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    subject text NOT NULL,
+    role text NOT NULL,
+    package_pattern text NOT NULL,
+    UNIQUE (subject, role, package_pattern)
+);
+
+CREATE INDEX idx_role_grants_subject ON role_grants (subject);
+
+CREATE TABLE audit_events (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor text NOT NULL,
+    action text NOT NULL,
+    package_name text NOT NULL DEFAULT '',
+    version bigint NOT NULL DEFAULT 0,
+    detail text NOT NULL DEFAULT '',
+    method text NOT NULL DEFAULT '',
+    client_address text NOT NULL DEFAULT '',
+    user_agent text NOT NULL DEFAULT '',
+    result text NOT NULL,
+    error text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor);
+CREATE INDEX idx_audit_events_package_name ON audit_events (package_name);
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+-- +goose Down
+DROP TABLE audit_events;
+DROP FUNCTION audit_events_append_only;
+DROP TABLE role_grants;
+DROP TABLE api_tokens;
+DROP TABLE package_aliases;
+ALTER TABLE messages DROP CONSTRAINT fk_messages_latest_version;
+DROP TABLE message_versions;
+DROP TABLE messages;
+DROP TABLE package_version_files;
+ALTER TABLE packages DROP CONSTRAINT fk_packages_latest_version;
+DROP TABLE package_versions;
+DROP TABLE packages;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE blobs (
+    digest text PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    size bigint NOT NULL,
+    contents text NOT NULL
+);
+
+ALTER TABLE package_version_files ADD COLUMN digest text NOT NULL DEFAULT '';
+
+-- Move the contents of existing files into blobs
+UPDATE package_version_files SET digest = 'sha256:' || encode(sha256(convert_to(file_contents, 'UTF8')), 'hex');
+
+INSERT INTO blobs (digest, size, contents)
+SELECT DISTINCT ON (digest) digest, octet_length(file_contents), file_contents
+FROM package_version_files
+ON CONFLICT (digest) DO NOTHING;
+
+ALTER TABLE package_version_files DROP COLUMN file_contents;
+
+CREATE INDEX idx_package_version_files_digest ON package_version_files (digest);
+
+-- +goose Down
+ALTER TABLE package_version_files ADD COLUMN file_contents text NOT NULL DEFAULT '';
+
+UPDATE package_version_files SET file_contents = blobs.contents FROM blobs WHERE blobs.digest = package_version_files.digest;
+
+DROP INDEX idx_package_version_files_digest;
+ALTER TABLE package_version_files DROP COLUMN digest;
+DROP TABLE blobs;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE mirror_cursors (
+    upstream text PRIMARY KEY,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    change_id bigint NOT NULL
+);
+
+-- +goose Down
+DROP TABLE mirror_cursors;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE package_version_messages (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    package_version_id bigint NOT NULL REFERENCES package_versions (id) ON DELETE CASCADE,
+    message_version_id bigint NOT NULL REFERENCES message_versions (id) ON DELETE CASCADE,
+    UNIQUE (package_version_id, message_version_id)
+);
+
+CREATE INDEX idx_package_version_messages_message_version_id ON package_version_messages (message_version_id);
+
+-- Link each package version to the newest version of every message that existed when it was uploaded, which is the
+-- version it either created or left unchanged
+INSERT INTO package_version_messages (package_version_id, message_version_id)
+SELECT linked.package_version_id, linked.message_version_id
+FROM (
+    SELECT package_versions.id AS package_version_id, (
+        SELECT message_versions.id
+        FROM message_versions
+        JOIN package_versions AS introduced_in ON introduced_in.id = message_versions.package_version_id
+        WHERE message_versions.message_id = messages.id AND introduced_in.version <= package_versions.version
+        ORDER BY message_versions.version DESC
+        LIMIT 1
+    ) AS message_version_id
+    FROM package_versions
+    JOIN messages ON messages.package_id = package_versions.package_id
+) AS linked
+WHERE linked.message_version_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE package_version_messages;
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"embed"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/cgund98/voer/internal/infra/database"
+)
+
+//go:embed migrations/*.sql
+var embedMigrations embed.FS
+
+// NewDB creates a new PostgreSQL database connection from a connection URL and migrates the database schema
+func NewDB(databaseURL string) (*gorm.DB, error) {
+	// Open the database connection
+	db, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Run migrations
+	if err := database.Migrate(db, embedMigrations, goose.DialectPostgres, database.RehashPackageVersionsMigration); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
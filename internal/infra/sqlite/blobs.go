@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+// storeFileBlobsMigration moves the contents of existing files into the blobs table. SQLite has no SHA-256
+// function, so this step runs in Go between the SQL migrations adding blobs and dropping file contents.
+var storeFileBlobsMigration = goose.NewGoMigration(20250622000001, &goose.GoFunc{RunTx: storeFileBlobs}, nil)
+
+type legacyFile struct {
+	id       int64
+	contents string
+}
+
+func storeFileBlobs(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "SELECT id, file_contents FROM package_version_files WHERE digest = ''")
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []legacyFile
+	for rows.Next() {
+		var file legacyFile
+		if err := rows.Scan(&file.id, &file.contents); err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		files = append(files, file)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	for _, file := range files {
+		// Computed here rather than with proto.DigestFile so the migration never changes
+		sum := sha256.Sum256([]byte(file.contents))
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+
+		_, err := tx.ExecContext(ctx, "INSERT INTO blobs (digest, size, contents) VALUES (?, ?, ?) ON CONFLICT (digest) DO NOTHING",
+			digest, len(file.contents), file.contents)
+		if err != nil {
+			return fmt.Errorf("failed to store blob: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE package_version_files SET digest = ? WHERE id = ?", digest, file.id); err != nil {
+			return fmt.Errorf("failed to update file: %w", err)
+		}
+	}
+
+	return nil
+}
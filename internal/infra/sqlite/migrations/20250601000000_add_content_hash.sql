@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE `package_versions` ADD COLUMN `content_hash` text NOT NULL DEFAULT '';
+ALTER TABLE `message_versions` ADD COLUMN `content_hash` text NOT NULL DEFAULT '';
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+ALTER TABLE `message_versions` DROP COLUMN `content_hash`;
+ALTER TABLE `package_versions` DROP COLUMN `content_hash`;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE `package_version_files` ADD COLUMN `import_path` text NOT NULL DEFAULT '';
+
+-- Files uploaded before import paths were tracked were compiled by their file name
+UPDATE `package_version_files` SET `import_path` = `file_name`;
+
+CREATE INDEX `idx_package_version_files_import_path` ON `package_version_files`(`import_path`);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX `idx_package_version_files_import_path`;
+ALTER TABLE `package_version_files` DROP COLUMN `import_path`;
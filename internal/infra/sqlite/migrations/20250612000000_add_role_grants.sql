@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE `role_grants` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `subject` text NOT NULL,
+    `role` text NOT NULL,
+    `package_pattern` text NOT NULL,
+    UNIQUE (`subject`, `role`, `package_pattern`)
+);
+
+CREATE INDEX `idx_role_grants_subject` ON `role_grants`(`subject`);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TABLE `role_grants`;
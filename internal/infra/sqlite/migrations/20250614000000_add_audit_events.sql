@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE `audit_events` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `actor` text NOT NULL,
+    `action` text NOT NULL,
+    `package_name` text NOT NULL DEFAULT '',
+    `version` integer NOT NULL DEFAULT 0,
+    `detail` text NOT NULL DEFAULT '',
+    `method` text NOT NULL DEFAULT '',
+    `client_address` text NOT NULL DEFAULT '',
+    `user_agent` text NOT NULL DEFAULT '',
+    `result` text NOT NULL,
+    `error` text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX `idx_audit_events_created_at` ON `audit_events`(`created_at`);
+CREATE INDEX `idx_audit_events_actor` ON `audit_events`(`actor`);
+CREATE INDEX `idx_audit_events_package_name` ON `audit_events`(`package_name`);
+
+-- Audit events are append-only
+-- +goose StatementBegin
+CREATE TRIGGER `audit_events_no_update` BEFORE UPDATE ON `audit_events`
+BEGIN
+    SELECT RAISE(ABORT, 'audit events are append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER `audit_events_no_delete` BEFORE DELETE ON `audit_events`
+BEGIN
+    SELECT RAISE(ABORT, 'audit events are append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TRIGGER `audit_events_no_delete`;
+DROP TRIGGER `audit_events_no_update`;
+DROP TABLE `audit_events`;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE `package_versions` ADD COLUMN `deleted_at` datetime;
+
+CREATE INDEX `idx_package_versions_deleted_at` ON `package_versions`(`deleted_at`);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX `idx_package_versions_deleted_at`;
+ALTER TABLE `package_versions` DROP COLUMN `deleted_at`;
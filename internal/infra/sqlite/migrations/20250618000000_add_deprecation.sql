@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+ALTER TABLE `package_versions` ADD COLUMN `deprecated_at` datetime;
+ALTER TABLE `package_versions` ADD COLUMN `deprecation_reason` text NOT NULL DEFAULT '';
+ALTER TABLE `package_versions` ADD COLUMN `deprecation_replacement` text NOT NULL DEFAULT '';
+
+ALTER TABLE `messages` ADD COLUMN `deprecated_at` datetime;
+ALTER TABLE `messages` ADD COLUMN `deprecation_reason` text NOT NULL DEFAULT '';
+ALTER TABLE `messages` ADD COLUMN `deprecation_replacement` text NOT NULL DEFAULT '';
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+ALTER TABLE `messages` DROP COLUMN `deprecation_replacement`;
+ALTER TABLE `messages` DROP COLUMN `deprecation_reason`;
+ALTER TABLE `messages` DROP COLUMN `deprecated_at`;
+
+ALTER TABLE `package_versions` DROP COLUMN `deprecation_replacement`;
+ALTER TABLE `package_versions` DROP COLUMN `deprecation_reason`;
+ALTER TABLE `package_versions` DROP COLUMN `deprecated_at`;
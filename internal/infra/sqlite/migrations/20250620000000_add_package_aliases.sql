@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE `package_aliases` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `alias_name` text NOT NULL UNIQUE,
+    `package_id` integer NOT NULL REFERENCES packages (id) ON DELETE CASCADE
+);
+
+CREATE INDEX `idx_package_aliases_package_id` ON `package_aliases`(`package_id`);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TABLE `package_aliases`;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE `blobs` (
+    `digest` text PRIMARY KEY,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `size` integer NOT NULL,
+    `contents` text NOT NULL
+);
+
+ALTER TABLE `package_version_files` ADD COLUMN `digest` text NOT NULL DEFAULT '';
+
+CREATE INDEX `idx_package_version_files_digest` ON `package_version_files`(`digest`);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP INDEX `idx_package_version_files_digest`;
+ALTER TABLE `package_version_files` DROP COLUMN `digest`;
+DROP TABLE `blobs`;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+-- File contents were moved to blobs by migration 20250622000001
+ALTER TABLE `package_version_files` DROP COLUMN `file_contents`;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+ALTER TABLE `package_version_files` ADD COLUMN `file_contents` text NOT NULL DEFAULT '';
+UPDATE `package_version_files` SET `file_contents` = (SELECT `contents` FROM `blobs` WHERE `blobs`.`digest` = `package_version_files`.`digest`);
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE `mirror_cursors` (
+    `upstream` text PRIMARY KEY,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `change_id` integer NOT NULL
+);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TABLE `mirror_cursors`;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE `webhook_deliveries` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `webhook` text NOT NULL,
+    `url` text NOT NULL,
+    `event_id` text NOT NULL,
+    `event_type` text NOT NULL,
+    `package_name` text NOT NULL DEFAULT '',
+    `version` integer NOT NULL DEFAULT 0,
+    `attempt` integer NOT NULL,
+    `status_code` integer NOT NULL DEFAULT 0,
+    `duration_ms` integer NOT NULL DEFAULT 0,
+    `result` text NOT NULL,
+    `error` text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX `idx_webhook_deliveries_created_at` ON `webhook_deliveries`(`created_at`);
+CREATE INDEX `idx_webhook_deliveries_webhook` ON `webhook_deliveries`(`webhook`);
+CREATE INDEX `idx_webhook_deliveries_event_id` ON `webhook_deliveries`(`event_id`);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TABLE `webhook_deliveries`;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+CREATE TABLE `package_version_messages` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `package_version_id` integer NOT NULL,
+    `message_version_id` integer NOT NULL,
+    CONSTRAINT `fk_package_version_messages_package_version` FOREIGN KEY (`package_version_id`) REFERENCES `package_versions`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_package_version_messages_message_version` FOREIGN KEY (`message_version_id`) REFERENCES `message_versions`(`id`) ON DELETE CASCADE,
+    UNIQUE (`package_version_id`, `message_version_id`)
+);
+
+CREATE INDEX `idx_package_version_messages_message_version_id` ON `package_version_messages`(`message_version_id`);
+
+-- Link each package version to the newest version of every message that existed when it was uploaded, which is the
+-- version it either created or left unchanged
+INSERT INTO `package_version_messages` (`package_version_id`, `message_version_id`)
+SELECT `linked`.`package_version_id`, `linked`.`message_version_id`
+FROM (
+    SELECT `package_versions`.`id` AS `package_version_id`, (
+        SELECT `message_versions`.`id`
+        FROM `message_versions`
+        JOIN `package_versions` AS `introduced_in` ON `introduced_in`.`id` = `message_versions`.`package_version_id`
+        WHERE `message_versions`.`message_id` = `messages`.`id` AND `introduced_in`.`version` <= `package_versions`.`version`
+        ORDER BY `message_versions`.`version` DESC
+        LIMIT 1
+    ) AS `message_version_id`
+    FROM `package_versions`
+    JOIN `messages` ON `messages`.`package_id` = `package_versions`.`package_id`
+) AS `linked`
+WHERE `linked`.`message_version_id` IS NOT NULL;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+DROP TABLE `package_version_messages`;
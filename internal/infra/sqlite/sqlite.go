@@ -2,33 +2,19 @@ package sqlite
 
 import (
 	"embed"
-	"fmt"
-	"log/slog"
 	"os"
 	"path/filepath"
 
+	"github.com/pressly/goose/v3"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
-	"github.com/cgund98/voer/internal/infra/logging"
-	"github.com/pressly/goose/v3"
+	"github.com/cgund98/voer/internal/infra/database"
 )
 
 //go:embed migrations/*.sql
 var embedMigrations embed.FS
 
-type GooseLogger struct {
-	Logger *slog.Logger
-}
-
-func (l *GooseLogger) Fatalf(msg string, args ...any) {
-	l.Logger.Error(fmt.Sprintf(msg, args...), "source", "goose")
-}
-
-func (l *GooseLogger) Printf(msg string, args ...any) {
-	l.Logger.Info(fmt.Sprintf(msg, args...), "source", "goose")
-}
-
 // genUserSpecificDbPath generates a user-specific SQLite database path. Should work for both Linux and MacOS.
 func genUserSpecificDbPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -70,20 +56,8 @@ func NewDB(dbPath string) (*gorm.DB, error) {
 	}
 
 	// Run migrations
-	goose.SetLogger(&GooseLogger{Logger: logging.Logger})
-
-	goose.SetBaseFS(embedMigrations)
-	if err := goose.SetDialect("sqlite3"); err != nil {
-		return nil, fmt.Errorf("failed to set dialect: %w", err)
-	}
-
-	sqlDb, err := db.DB()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get sql db: %w", err)
-	}
-
-	if err := goose.Up(sqlDb, "migrations"); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	if err := database.Migrate(db, embedMigrations, goose.DialectSQLite3, storeFileBlobsMigration, database.RehashPackageVersionsMigration); err != nil {
+		return nil, err
 	}
 
 	return db, nil
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey is the key under which a query's span is kept on its statement
+const spanKey = "tracing:span"
+
+// GormPlugin traces every query run through a GORM connection as a child of the span in the query's context.
+// Queries only join a request's trace when the connection is bound to the request's context.
+type GormPlugin struct {
+	// Database system recorded on spans, e.g. "sqlite" or "postgresql"
+	System string
+}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("gorm:create").Register("tracing:before_create", p.start("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", end),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", p.start("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", end),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", p.start("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", end),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", p.start("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", end),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", p.start("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", end),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", p.start("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", end),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start starts the span of a query
+func (p GormPlugin) start(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			return
+		}
+
+		_, span := Start(ctx, "gorm."+operation,
+			attribute.String("db.system", p.System),
+			attribute.String("db.operation", operation),
+			attribute.String("db.sql.table", db.Statement.Table),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+// end ends the span of a query, recording its statement and outcome
+func end(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		RecordError(span, db.Error)
+	}
+}
@@ -0,0 +1,177 @@
+package proto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/protocompile/linker"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+	"gopkg.in/yaml.v3"
+)
+
+// GeneratePlugin configures a protoc plugin run by `voer generate`
+type GeneratePlugin struct {
+	// Plugin name. The binary protoc-gen-<name> is looked up on PATH.
+	Name string `yaml:"name" json:"name"`
+
+	// Explicit path to the plugin binary, overriding the PATH lookup
+	Path string `yaml:"path" json:"path"`
+
+	// Directory generated files are written to
+	Out string `yaml:"out" json:"out"`
+
+	// Options passed to the plugin as its parameter, e.g. paths=source_relative
+	Opt []string `yaml:"opt" json:"opt"`
+}
+
+// GenerateConfig holds the plugins run by `voer generate`
+type GenerateConfig struct {
+	Plugins []GeneratePlugin `yaml:"plugins" json:"plugins"`
+}
+
+// Validate checks that every plugin has a name and an output directory
+func (c *GenerateConfig) Validate() error {
+	if len(c.Plugins) == 0 {
+		return errors.New("no plugins configured")
+	}
+
+	for _, plugin := range c.Plugins {
+		if plugin.Name == "" {
+			return errors.New("plugin name is required")
+		}
+		if plugin.Out == "" {
+			return fmt.Errorf("output directory is required for plugin %s", plugin.Name)
+		}
+	}
+
+	return nil
+}
+
+// LoadGenerateConfig reads a generate configuration from a YAML (or JSON) file
+func LoadGenerateConfig(path string) (*GenerateConfig, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read generate config: %w", err)
+	}
+
+	var config GenerateConfig
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse generate config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// collectFileDescriptors adds a file and its imports to protos, with imports ahead of the files importing them
+func collectFileDescriptors(file protoreflect.FileDescriptor, seen map[string]bool, protos []*descriptorpb.FileDescriptorProto) []*descriptorpb.FileDescriptorProto {
+	if seen[file.Path()] {
+		return protos
+	}
+	seen[file.Path()] = true
+
+	imports := file.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		protos = collectFileDescriptors(imports.Get(i).FileDescriptor, seen, protos)
+	}
+
+	return append(protos, protodesc.ToFileDescriptorProto(file))
+}
+
+// BuildCodeGeneratorRequest builds a plugin request generating code for the given files.
+// Every transitive import is included in topological order, as protoc does.
+func BuildCodeGeneratorRequest(files linker.Files, parameter string) *pluginpb.CodeGeneratorRequest {
+	req := &pluginpb.CodeGeneratorRequest{}
+	if parameter != "" {
+		req.Parameter = proto.String(parameter)
+	}
+
+	seen := make(map[string]bool)
+	for _, file := range files {
+		req.FileToGenerate = append(req.FileToGenerate, file.Path())
+		req.ProtoFile = collectFileDescriptors(file, seen, req.ProtoFile)
+		req.SourceFileDescriptors = append(req.SourceFileDescriptors, protodesc.ToFileDescriptorProto(file))
+	}
+
+	return req
+}
+
+// RunPlugin invokes a protoc plugin with a code generator request over stdin and stdout
+func RunPlugin(ctx context.Context, plugin GeneratePlugin, req *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse, error) {
+	path := plugin.Path
+	if path == "" {
+		var err error
+		path, err = exec.LookPath("protoc-gen-" + plugin.Name)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s not found on PATH: %w", plugin.Name, err)
+		}
+	}
+
+	input, err := proto.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode code generator request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w: %s", plugin.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	res := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(stdout.Bytes(), res); err != nil {
+		return nil, fmt.Errorf("failed to decode response from plugin %s: %w", plugin.Name, err)
+	}
+
+	if res.Error != nil {
+		return nil, fmt.Errorf("plugin %s: %s", plugin.Name, res.GetError())
+	}
+
+	return res, nil
+}
+
+// WriteGeneratedFiles writes the files in a plugin response to an output directory.
+// Returns the paths of the written files.
+func WriteGeneratedFiles(outDir string, res *pluginpb.CodeGeneratorResponse) ([]string, error) {
+	written := make([]string, 0, len(res.File))
+
+	for _, file := range res.File {
+		if file.GetInsertionPoint() != "" {
+			return nil, fmt.Errorf("insertion points are not supported: %s", file.GetName())
+		}
+
+		name := filepath.FromSlash(file.GetName())
+		if !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("refusing to write generated file outside the output directory: %s", file.GetName())
+		}
+
+		filePath := filepath.Join(outDir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+
+		if err := os.WriteFile(filePath, []byte(file.GetContent()), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write generated file: %w", err)
+		}
+		written = append(written, filePath)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,55 @@
+package proto
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBuildCodeGeneratorRequestOrdersImports(t *testing.T) {
+	files, err := ParseStrings(context.Background(),
+		ParseStringInput{
+			FileName: "orders/v1/order.proto",
+			FileContents: `syntax = "proto3";
+package orders.v1;
+import "common/v1/money.proto";
+message Order { common.v1.Money total = 1; }
+`,
+		},
+		ParseStringInput{
+			FileName: "common/v1/money.proto",
+			FileContents: `syntax = "proto3";
+package common.v1;
+import "google/protobuf/timestamp.proto";
+message Money { int64 units = 1; google.protobuf.Timestamp at = 2; }
+`,
+		},
+	)
+	if err != nil {
+		t.Fatalf("Failed to parse files: %v", err)
+	}
+
+	req := BuildCodeGeneratorRequest(files[:1], "paths=source_relative")
+
+	if req.GetParameter() != "paths=source_relative" {
+		t.Fatalf("Unexpected parameter: %s", req.GetParameter())
+	}
+
+	if len(req.FileToGenerate) != 1 || req.FileToGenerate[0] != "orders/v1/order.proto" {
+		t.Fatalf("Unexpected files to generate: %v", req.FileToGenerate)
+	}
+
+	names := make([]string, 0, len(req.ProtoFile))
+	for _, file := range req.ProtoFile {
+		names = append(names, file.GetName())
+	}
+
+	expected := []string{"google/protobuf/timestamp.proto", "common/v1/money.proto", "orders/v1/order.proto"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected proto files %v, got: %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected proto files %v, got: %v", expected, names)
+		}
+	}
+}
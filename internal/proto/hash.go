@@ -0,0 +1,61 @@
+package proto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// canonicalizeContents normalizes line endings and trailing whitespace so that
+// cosmetic differences between platforms do not produce different hashes
+func canonicalizeContents(contents string) string {
+	contents = strings.ReplaceAll(contents, "\r\n", "\n")
+
+	lines := strings.Split(contents, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// HashFiles computes a canonical SHA-256 content hash for a set of proto files.
+// Files are sorted by their full name, e.g. an import path, so the hash does not depend on upload order but changes
+// when a file moves to another directory.
+func HashFiles(inputs ...ParseStringInput) string {
+	sorted := make([]ParseStringInput, len(inputs))
+	copy(sorted, inputs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].FileName < sorted[j].FileName
+	})
+
+	hash := sha256.New()
+	for _, input := range sorted {
+		hash.Write([]byte(input.FileName))
+		hash.Write([]byte{0})
+		hash.Write([]byte(canonicalizeContents(input.FileContents)))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// HashMessage computes a canonical SHA-256 content hash for a parsed message.
+// The hash is derived from the serialized schema, so formatting and comments do not affect it.
+func HashMessage(message ParsedMessage) (string, error) {
+	serialized, err := SerializeMessage(message)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(serialized))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DigestFile returns the content address of a file: "sha256:" followed by the hex SHA-256 of its exact contents.
+// Unlike HashFiles, the contents are not canonicalized, so the digest verifies the bytes as stored.
+func DigestFile(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,83 @@
+package proto
+
+import "testing"
+
+func TestHashFilesOrderIndependent(t *testing.T) {
+	first := ParseStringInput{FileName: "request.proto", FileContents: "syntax = \"proto3\";\n"}
+	second := ParseStringInput{FileName: "response.proto", FileContents: "syntax = \"proto3\";\n"}
+
+	if HashFiles(first, second) != HashFiles(second, first) {
+		t.Fatalf("expected hash to be independent of file order")
+	}
+}
+
+func TestHashFilesIgnoresLineEndings(t *testing.T) {
+	unix := ParseStringInput{FileName: "request.proto", FileContents: "syntax = \"proto3\";\npackage helloworld;\n"}
+	windows := ParseStringInput{FileName: "request.proto", FileContents: "syntax = \"proto3\";  \r\npackage helloworld;\r\n\r\n"}
+
+	if HashFiles(unix) != HashFiles(windows) {
+		t.Fatalf("expected hash to ignore line endings and trailing whitespace")
+	}
+}
+
+func TestHashFilesDetectsChanges(t *testing.T) {
+	prev := ParseStringInput{FileName: "request.proto", FileContents: "message A { string a = 1; }"}
+	latest := ParseStringInput{FileName: "request.proto", FileContents: "message A { string b = 1; }"}
+
+	if HashFiles(prev) == HashFiles(latest) {
+		t.Fatalf("expected hash to change when contents change")
+	}
+}
+
+func TestHashFilesCoversDirectories(t *testing.T) {
+	first := ParseStringInput{FileName: "helloworld/v1/types.proto", FileContents: "message A { string a = 1; }"}
+	second := ParseStringInput{FileName: "helloworld/v2/types.proto", FileContents: "message B { string b = 1; }"}
+	swapped := []ParseStringInput{
+		{FileName: first.FileName, FileContents: second.FileContents},
+		{FileName: second.FileName, FileContents: first.FileContents},
+	}
+
+	if HashFiles(first, second) == HashFiles(swapped...) {
+		t.Fatalf("expected hash to change when files with the same name swap directories")
+	}
+}
+
+func TestHashMessage(t *testing.T) {
+	prev := ParsedMessage{Name: "Greeting", FullName: "helloworld.Greeting", Fields: []ParsedField{{Name: "message", Number: 1, Kind: "string"}}}
+	latest := ParsedMessage{Name: "Greeting", FullName: "helloworld.Greeting", Fields: []ParsedField{{Name: "message", Number: 1, Kind: "int32"}}}
+
+	prevHash, err := HashMessage(prev)
+	if err != nil {
+		t.Fatalf("error hashing message: %v", err)
+	}
+	sameHash, err := HashMessage(prev)
+	if err != nil {
+		t.Fatalf("error hashing message: %v", err)
+	}
+	latestHash, err := HashMessage(latest)
+	if err != nil {
+		t.Fatalf("error hashing message: %v", err)
+	}
+
+	if prevHash != sameHash {
+		t.Fatalf("expected identical messages to have the same hash")
+	}
+	if prevHash == latestHash {
+		t.Fatalf("expected changed messages to have different hashes")
+	}
+}
+
+func TestDigestFileIsExact(t *testing.T) {
+	unix := "syntax = \"proto3\";\n"
+	windows := "syntax = \"proto3\";\r\n"
+
+	if DigestFile(unix) != DigestFile(unix) {
+		t.Fatalf("expected identical contents to have the same digest")
+	}
+	if DigestFile(unix) == DigestFile(windows) {
+		t.Fatalf("expected digests to cover the exact contents")
+	}
+	if digest := DigestFile(""); digest != "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Fatalf("unexpected digest of empty contents: %s", digest)
+	}
+}
@@ -0,0 +1,43 @@
+package proto
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protocompile/parser"
+	"github.com/bufbuild/protocompile/reporter"
+)
+
+// standardResolver only resolves the standard imports bundled with protocompile
+var standardResolver = protocompile.WithStandardImports(&protocompile.SourceResolver{
+	Accessor: func(path string) (io.ReadCloser, error) {
+		return nil, fs.ErrNotExist
+	},
+})
+
+// IsStandardImport returns true if an import path refers to a well-known file bundled with the compiler,
+// such as google/protobuf/timestamp.proto
+func IsStandardImport(importPath string) bool {
+	_, err := standardResolver.FindFileByPath(importPath)
+	return err == nil
+}
+
+// ParseImports returns the import paths declared by a proto file without resolving them
+func ParseImports(fileName string, contents string) ([]string, error) {
+	handler := reporter.NewHandler(nil)
+
+	fileNode, err := parser.Parse(fileName, strings.NewReader(contents), handler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", fileName, err)
+	}
+
+	result, err := parser.ResultFromAST(fileNode, false, handler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", fileName, err)
+	}
+
+	return result.FileDescriptorProto().GetDependency(), nil
+}
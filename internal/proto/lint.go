@@ -0,0 +1,198 @@
+package proto
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/protocompile/linker"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// LintViolation describes a single style rule violation
+type LintViolation struct {
+	Rule     string
+	FileName string
+	Line     int
+	Element  string
+	Message  string
+}
+
+func (v LintViolation) String() string {
+	return fmt.Sprintf("%s:%d: [%s] %s", v.FileName, v.Line, v.Rule, v.Message)
+}
+
+// LintRule is a single style check that can be applied to a proto file
+type LintRule interface {
+	// Name is the unique identifier used to enable or disable the rule
+	Name() string
+	Description() string
+	Check(file linker.File) []LintViolation
+}
+
+var lintRules = map[string]LintRule{}
+
+// RegisterLintRule adds a rule to the set of rules available to the linter.
+// Registering a rule with an existing name replaces it.
+func RegisterLintRule(rule LintRule) {
+	lintRules[rule.Name()] = rule
+}
+
+// LintRules returns all registered rules sorted by name
+func LintRules() []LintRule {
+	rules := make([]LintRule, 0, len(lintRules))
+	for _, rule := range lintRules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name() < rules[j].Name()
+	})
+	return rules
+}
+
+// LintPackageConfig overrides the enabled rules for packages matching a pattern
+type LintPackageConfig struct {
+	Use    []string `yaml:"use" json:"use"`
+	Except []string `yaml:"except" json:"except"`
+}
+
+// LintConfig selects which rules are applied to which packages.
+// An empty Use list enables all registered rules.
+// Package keys are either exact package names or prefixes ending in ".*" (e.g. "payments.*").
+type LintConfig struct {
+	Use      []string                     `yaml:"use" json:"use"`
+	Except   []string                     `yaml:"except" json:"except"`
+	Packages map[string]LintPackageConfig `yaml:"packages" json:"packages"`
+}
+
+// LoadLintConfig reads a lint configuration from a YAML or JSON file
+func LoadLintConfig(path string) (*LintConfig, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lint config: %w", err)
+	}
+
+	var config LintConfig
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse lint config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// Validate ensures all rules referenced by the config are registered
+func (c *LintConfig) Validate() error {
+	names := append(append([]string{}, c.Use...), c.Except...)
+	for _, pkgConfig := range c.Packages {
+		names = append(names, pkgConfig.Use...)
+		names = append(names, pkgConfig.Except...)
+	}
+
+	for _, name := range names {
+		if _, ok := lintRules[name]; !ok {
+			return fmt.Errorf("unknown lint rule: %s", name)
+		}
+	}
+
+	return nil
+}
+
+// matchesPackage checks if a package config key applies to a package name
+func matchesPackage(pattern, packageName string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return packageName == prefix || strings.HasPrefix(packageName, prefix+".")
+	}
+	return pattern == packageName
+}
+
+// RulesForPackage resolves the rules that apply to a given package.
+// Package overrides are applied from least to most specific pattern.
+func (c *LintConfig) RulesForPackage(packageName string) []LintRule {
+	enabled := make(map[string]bool)
+	if len(c.Use) == 0 {
+		for name := range lintRules {
+			enabled[name] = true
+		}
+	}
+	for _, name := range c.Use {
+		enabled[name] = true
+	}
+	for _, name := range c.Except {
+		delete(enabled, name)
+	}
+
+	// Apply package overrides, shortest pattern first
+	patterns := make([]string, 0)
+	for pattern := range c.Packages {
+		if matchesPackage(pattern, packageName) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		return len(patterns[i]) < len(patterns[j])
+	})
+
+	for _, pattern := range patterns {
+		for _, name := range c.Packages[pattern].Use {
+			enabled[name] = true
+		}
+		for _, name := range c.Packages[pattern].Except {
+			delete(enabled, name)
+		}
+	}
+
+	rules := make([]LintRule, 0)
+	for _, rule := range LintRules() {
+		if enabled[rule.Name()] {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Lint runs all applicable rules against a set of proto files
+func Lint(ctx context.Context, files linker.Files, config *LintConfig) []LintViolation {
+	if config == nil {
+		config = &LintConfig{}
+	}
+
+	violations := make([]LintViolation, 0)
+	for _, file := range files {
+		for _, rule := range config.RulesForPackage(string(file.Package())) {
+			violations = append(violations, rule.Check(file)...)
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].FileName != violations[j].FileName {
+			return violations[i].FileName < violations[j].FileName
+		}
+		return violations[i].Line < violations[j].Line
+	})
+
+	return violations
+}
+
+// newViolation builds a violation for a descriptor, resolving its line number from source info
+func newViolation(rule string, file linker.File, desc protoreflect.Descriptor, message string) LintViolation {
+	line := 0
+	if loc := file.SourceLocations().ByDescriptor(desc); loc.Path != nil {
+		line = loc.StartLine + 1
+	}
+
+	return LintViolation{
+		Rule:     rule,
+		FileName: filepath.Base(file.Path()),
+		Line:     line,
+		Element:  string(desc.FullName()),
+		Message:  message,
+	}
+}
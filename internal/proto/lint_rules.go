@@ -0,0 +1,178 @@
+package proto
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bufbuild/protocompile/linker"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Source paths of file-level elements within a FileDescriptorProto
+var (
+	packageSourcePath = protoreflect.SourcePath{2}
+	optionsSourcePath = protoreflect.SourcePath{8}
+)
+
+var (
+	lowerSnakeCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	pascalCaseRegex     = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	packageNameRegex    = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+	versionSuffixRegex  = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]*)?$`)
+)
+
+// lintRule is a LintRule backed by a check function
+type lintRule struct {
+	name        string
+	description string
+	check       func(name string, file linker.File) []LintViolation
+}
+
+func (r *lintRule) Name() string        { return r.name }
+func (r *lintRule) Description() string { return r.description }
+func (r *lintRule) Check(file linker.File) []LintViolation {
+	return r.check(r.name, file)
+}
+
+func init() {
+	RegisterLintRule(&lintRule{"PACKAGE_LOWER_SNAKE_CASE", "Package names are lower_snake_case, dot separated", checkPackageLowerSnakeCase})
+	RegisterLintRule(&lintRule{"PACKAGE_VERSION_SUFFIX", "Package names end with a version suffix such as .v1 or .v1beta1", checkPackageVersionSuffix})
+	RegisterLintRule(&lintRule{"FIELD_LOWER_SNAKE_CASE", "Field names are lower_snake_case", checkFieldLowerSnakeCase})
+	RegisterLintRule(&lintRule{"MESSAGE_PASCAL_CASE", "Message names are PascalCase", checkMessagePascalCase})
+	RegisterLintRule(&lintRule{"ENUM_ZERO_VALUE_SUFFIX", "Enum zero values are suffixed with _UNSPECIFIED", checkEnumZeroValueSuffix})
+	RegisterLintRule(&lintRule{"FILE_OPTION_GO_PACKAGE", "Files declare the go_package option", checkGoPackageOption})
+	RegisterLintRule(&lintRule{"FILE_OPTION_JAVA_PACKAGE", "Files declare the java_package option", checkJavaPackageOption})
+	RegisterLintRule(&lintRule{"COMMENT_MESSAGE", "Top-level messages have a leading comment", checkMessageComments})
+}
+
+// fileViolation builds a violation for a file-level element identified by its source path
+func fileViolation(rule string, file linker.File, path protoreflect.SourcePath, message string) LintViolation {
+	line := 0
+	if loc := file.SourceLocations().ByPath(path); loc.Path != nil {
+		line = loc.StartLine + 1
+	}
+
+	return LintViolation{
+		Rule:     rule,
+		FileName: filepath.Base(file.Path()),
+		Line:     line,
+		Element:  string(file.Package()),
+		Message:  message,
+	}
+}
+
+// walkMessages calls fn for every message in the file, including nested messages
+func walkMessages(messages protoreflect.MessageDescriptors, fn func(protoreflect.MessageDescriptor)) {
+	for i := 0; i < messages.Len(); i++ {
+		msg := messages.Get(i)
+
+		// Skip synthetic map entry messages
+		if msg.IsMapEntry() {
+			continue
+		}
+
+		fn(msg)
+		walkMessages(msg.Messages(), fn)
+	}
+}
+
+// walkEnums calls fn for every enum in the file, including enums nested in messages
+func walkEnums(file linker.File, fn func(protoreflect.EnumDescriptor)) {
+	for i := 0; i < file.Enums().Len(); i++ {
+		fn(file.Enums().Get(i))
+	}
+	walkMessages(file.Messages(), func(msg protoreflect.MessageDescriptor) {
+		for i := 0; i < msg.Enums().Len(); i++ {
+			fn(msg.Enums().Get(i))
+		}
+	})
+}
+
+func checkPackageLowerSnakeCase(name string, file linker.File) []LintViolation {
+	pkg := string(file.Package())
+	if pkg == "" || packageNameRegex.MatchString(pkg) {
+		return nil
+	}
+	return []LintViolation{fileViolation(name, file, packageSourcePath, fmt.Sprintf("package '%s' should be lower_snake_case", pkg))}
+}
+
+func checkPackageVersionSuffix(name string, file linker.File) []LintViolation {
+	pkg := string(file.Package())
+	parts := strings.Split(pkg, ".")
+	if len(parts) > 1 && versionSuffixRegex.MatchString(parts[len(parts)-1]) {
+		return nil
+	}
+	return []LintViolation{fileViolation(name, file, packageSourcePath, fmt.Sprintf("package '%s' should end with a version suffix such as '.v1'", pkg))}
+}
+
+func checkFieldLowerSnakeCase(name string, file linker.File) []LintViolation {
+	violations := make([]LintViolation, 0)
+	walkMessages(file.Messages(), func(msg protoreflect.MessageDescriptor) {
+		for i := 0; i < msg.Fields().Len(); i++ {
+			field := msg.Fields().Get(i)
+			if !lowerSnakeCaseRegex.MatchString(string(field.Name())) {
+				violations = append(violations, newViolation(name, file, field, fmt.Sprintf("field '%s' should be lower_snake_case", field.FullName())))
+			}
+		}
+	})
+	return violations
+}
+
+func checkMessagePascalCase(name string, file linker.File) []LintViolation {
+	violations := make([]LintViolation, 0)
+	walkMessages(file.Messages(), func(msg protoreflect.MessageDescriptor) {
+		if !pascalCaseRegex.MatchString(string(msg.Name())) {
+			violations = append(violations, newViolation(name, file, msg, fmt.Sprintf("message '%s' should be PascalCase", msg.FullName())))
+		}
+	})
+	return violations
+}
+
+func checkEnumZeroValueSuffix(name string, file linker.File) []LintViolation {
+	violations := make([]LintViolation, 0)
+	walkEnums(file, func(enum protoreflect.EnumDescriptor) {
+		zeroValue := enum.Values().ByNumber(0)
+		if zeroValue == nil {
+			violations = append(violations, newViolation(name, file, enum, fmt.Sprintf("enum '%s' should have a zero value suffixed with _UNSPECIFIED", enum.FullName())))
+			return
+		}
+		if !strings.HasSuffix(string(zeroValue.Name()), "_UNSPECIFIED") {
+			violations = append(violations, newViolation(name, file, zeroValue, fmt.Sprintf("enum zero value '%s' should be suffixed with _UNSPECIFIED", zeroValue.Name())))
+		}
+	})
+	return violations
+}
+
+func fileOptions(file linker.File) *descriptorpb.FileOptions {
+	opts, _ := file.Options().(*descriptorpb.FileOptions)
+	return opts
+}
+
+func checkGoPackageOption(name string, file linker.File) []LintViolation {
+	if fileOptions(file).GetGoPackage() != "" {
+		return nil
+	}
+	return []LintViolation{fileViolation(name, file, optionsSourcePath, "file should declare the go_package option")}
+}
+
+func checkJavaPackageOption(name string, file linker.File) []LintViolation {
+	if fileOptions(file).GetJavaPackage() != "" {
+		return nil
+	}
+	return []LintViolation{fileViolation(name, file, optionsSourcePath, "file should declare the java_package option")}
+}
+
+func checkMessageComments(name string, file linker.File) []LintViolation {
+	violations := make([]LintViolation, 0)
+	for i := 0; i < file.Messages().Len(); i++ {
+		msg := file.Messages().Get(i)
+		loc := file.SourceLocations().ByDescriptor(msg)
+		if strings.TrimSpace(loc.LeadingComments) == "" {
+			violations = append(violations, newViolation(name, file, msg, fmt.Sprintf("message '%s' should have a leading comment", msg.FullName())))
+		}
+	}
+	return violations
+}
@@ -0,0 +1,150 @@
+package proto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/protocompile/linker"
+)
+
+// lintRuleNames collects the rule names from a list of violations
+func lintRuleNames(violations []LintViolation) map[string]int {
+	names := make(map[string]int)
+	for _, violation := range violations {
+		names[violation.Rule]++
+	}
+	return names
+}
+
+func TestLintValidFile(t *testing.T) {
+	content := `
+	syntax = "proto3";
+
+	package payments.v1;
+
+	option go_package = "example.com/payments/v1";
+	option java_package = "com.example.payments.v1";
+
+	// Payment is a single payment
+	message Payment {
+		string payment_id = 1;
+		Status status = 2;
+	}
+
+	enum Status {
+		STATUS_UNSPECIFIED = 0;
+		STATUS_PAID = 1;
+	}
+	`
+
+	ctx := context.Background()
+	file := createTempProto(t, ctx, content)
+
+	violations := Lint(ctx, linker.Files{file}, nil)
+	if len(violations) != 0 {
+		t.Fatalf("expected no violations, got: %v", violations)
+	}
+}
+
+func TestLintInvalidFile(t *testing.T) {
+	content := `
+	syntax = "proto3";
+
+	package helloworld;
+
+	message greeting_request {
+		string fullName = 1;
+	}
+
+	enum Status {
+		PAID = 0;
+	}
+	`
+
+	ctx := context.Background()
+	file := createTempProto(t, ctx, content)
+
+	names := lintRuleNames(Lint(ctx, linker.Files{file}, nil))
+
+	expected := []string{
+		"PACKAGE_VERSION_SUFFIX",
+		"FIELD_LOWER_SNAKE_CASE",
+		"MESSAGE_PASCAL_CASE",
+		"ENUM_ZERO_VALUE_SUFFIX",
+		"FILE_OPTION_GO_PACKAGE",
+		"FILE_OPTION_JAVA_PACKAGE",
+		"COMMENT_MESSAGE",
+	}
+	for _, name := range expected {
+		if names[name] == 0 {
+			t.Fatalf("expected violation for rule %s, got: %v", name, names)
+		}
+	}
+}
+
+func TestLintViolationLineNumbers(t *testing.T) {
+	content := `syntax = "proto3";
+
+package helloworld.v1;
+
+// Greeting is a greeting
+message Greeting {
+	string fullName = 1;
+}
+`
+
+	ctx := context.Background()
+	file := createTempProto(t, ctx, content)
+
+	violations := Lint(ctx, linker.Files{file}, &LintConfig{Use: []string{"FIELD_LOWER_SNAKE_CASE"}})
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got: %v", violations)
+	}
+
+	if violations[0].Line != 7 {
+		t.Fatalf("expected violation on line 7, got: %d", violations[0].Line)
+	}
+}
+
+func TestLintConfigPackageOverrides(t *testing.T) {
+	config := &LintConfig{
+		Except: []string{"COMMENT_MESSAGE"},
+		Packages: map[string]LintPackageConfig{
+			"payments.*":         {Use: []string{"COMMENT_MESSAGE"}},
+			"payments.legacy.v1": {Except: []string{"FIELD_LOWER_SNAKE_CASE"}},
+		},
+	}
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected valid config, got: %v", err)
+	}
+
+	enabled := func(packageName, rule string) bool {
+		for _, r := range config.RulesForPackage(packageName) {
+			if r.Name() == rule {
+				return true
+			}
+		}
+		return false
+	}
+
+	if enabled("helloworld.v1", "COMMENT_MESSAGE") {
+		t.Fatalf("expected COMMENT_MESSAGE to be disabled globally")
+	}
+	if !enabled("payments.v1", "COMMENT_MESSAGE") {
+		t.Fatalf("expected COMMENT_MESSAGE to be enabled for payments.*")
+	}
+	if enabled("payments.legacy.v1", "FIELD_LOWER_SNAKE_CASE") {
+		t.Fatalf("expected FIELD_LOWER_SNAKE_CASE to be disabled for payments.legacy.v1")
+	}
+	if enabled("paymentsfoo.v1", "COMMENT_MESSAGE") {
+		t.Fatalf("expected payments.* to not match paymentsfoo.v1")
+	}
+}
+
+func TestLintConfigUnknownRule(t *testing.T) {
+	config := &LintConfig{Use: []string{"NOT_A_RULE"}}
+	if err := config.Validate(); err == nil {
+		t.Fatalf("expected error for unknown rule")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/bufbuild/protocompile"
 	"github.com/bufbuild/protocompile/linker"
@@ -26,16 +27,91 @@ type ParsedField struct {
 	Cardinality string
 }
 
+// ImportResolver returns the contents of an imported file that could not be found locally.
+// Implementations should return an error wrapping fs.ErrNotExist if the file does not exist.
+type ImportResolver func(importPath string) (string, error)
+
+// ParseOptions configures how imports are resolved when parsing proto files
+type ParseOptions struct {
+	// Directories searched for imports. File paths are compiled relative to the first import path containing them.
+	ImportPaths []string
+
+	// Fallback used for imports not found in the import paths or standard imports
+	Resolver ImportResolver
+}
+
+// withFallback adds a fallback import resolver to a resolver
+func withFallback(resolver protocompile.Resolver, fallback ImportResolver) protocompile.Resolver {
+	if fallback == nil {
+		return resolver
+	}
+
+	return protocompile.CompositeResolver{
+		resolver,
+		protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+			contents, err := fallback(path)
+			if err != nil {
+				return protocompile.SearchResult{}, err
+			}
+			return protocompile.SearchResult{Source: strings.NewReader(contents)}, nil
+		}),
+	}
+}
+
+// RelativeImportPath returns the path of a file relative to the first import path that contains it
+func RelativeImportPath(importPaths []string, filePath string) (string, error) {
+	absFile, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	for _, importPath := range importPaths {
+		absImport, err := filepath.Abs(importPath)
+		if err != nil {
+			return "", err
+		}
+
+		rel, err := filepath.Rel(absImport, absFile)
+		if err == nil && filepath.IsLocal(rel) {
+			return filepath.ToSlash(rel), nil
+		}
+	}
+
+	return "", fmt.Errorf("proto file %s is not within any import path", filePath)
+}
+
 // ParsePath will look for proto files in under a specific path
 func ParsePath(ctx context.Context, filePaths ...string) (linker.Files, error) {
+	return ParsePathWithOptions(ctx, ParseOptions{}, filePaths...)
+}
+
+// ParsePathWithOptions will compile proto files on disk, resolving imports from the given import paths.
+// When import paths are given, the resulting files are named by their path relative to an import path.
+func ParsePathWithOptions(ctx context.Context, opts ParseOptions, filePaths ...string) (linker.Files, error) {
+
+	names := filePaths
+	if len(opts.ImportPaths) > 0 {
+		names = make([]string, 0, len(filePaths))
+		for _, filePath := range filePaths {
+			name, err := RelativeImportPath(opts.ImportPaths, filePath)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+		}
+	}
 
 	parser := &protocompile.Compiler{
-		// You can add ImportPaths if your .proto imports others
-		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{}),
+		Resolver: withFallback(protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: opts.ImportPaths,
+		}), opts.Resolver),
+
+		// Source info is needed to report line numbers and comments when linting
+		SourceInfoMode: protocompile.SourceInfoStandard,
 	}
 
 	// Compile one or more .proto files
-	files, err := parser.Compile(ctx, filePaths...)
+	files, err := parser.Compile(ctx, names...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile proto file: %w", err)
 	}
@@ -154,53 +230,40 @@ type ParseStringInput struct {
 // ParseStrings will parse a list of strings into a list of proto files
 // This is helpful for when you have a list of proto files in memory but not on disk
 func ParseStrings(ctx context.Context, inputs ...ParseStringInput) (linker.Files, error) {
+	return ParseStringsWithOptions(ctx, ParseOptions{}, inputs...)
+}
+
+// ParseStringsWithOptions parses in-memory proto files, named by their import path.
+// Imports of files outside the inputs are resolved with the fallback resolver in opts.
+func ParseStringsWithOptions(ctx context.Context, opts ParseOptions, inputs ...ParseStringInput) (linker.Files, error) {
 
 	// Build map of file names to file contents
-	accessor := make(map[string]string)
+	sources := make(map[string]string)
+	names := make([]string, 0, len(inputs))
 	for _, input := range inputs {
-		accessor[input.FileName] = input.FileContents
-	}
-
-	// Write to temp files
-	tempFiles := make([]string, 0)
-	fileNames := make(map[string]bool)
-
-	// Create temp directory to write files to
-	tempDir, err := os.MkdirTemp(os.TempDir(), "proto")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
-	}
-	defer func() {
-		if err := os.RemoveAll(tempDir); err != nil {
-			fmt.Printf("failed to remove temporary file: %v\n", err)
+		name := filepath.ToSlash(filepath.Clean(input.FileName))
+		if !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("invalid file name: %s", input.FileName)
 		}
-	}()
 
-	for fileName, fileContents := range accessor {
 		// Check for duplicate file names
-		if _, ok := fileNames[fileName]; ok {
-			return nil, fmt.Errorf("duplicate file name: %s", fileName)
-		}
-		fileNames[fileName] = true
-
-		// Create temp file
-		tempFile, err := os.Create(filepath.Join(tempDir, filepath.Base(filepath.Clean(fileName))))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create temporary file: %w", err)
+		if _, ok := sources[name]; ok {
+			return nil, fmt.Errorf("duplicate file name: %s", input.FileName)
 		}
+		sources[name] = input.FileContents
+		names = append(names, name)
+	}
 
-		// Write to temp file
-		_, err = tempFile.WriteString(fileContents)
-		if err != nil {
-			return nil, fmt.Errorf("failed to write to temporary file: %w", err)
-		}
+	parser := &protocompile.Compiler{
+		Resolver: withFallback(protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(sources),
+		}), opts.Resolver),
 
-		// Add to list of temp files
-		tempFiles = append(tempFiles, tempFile.Name())
+		// Source info is needed to report line numbers and comments when linting
+		SourceInfoMode: protocompile.SourceInfoStandard,
 	}
 
-	// List tmpFiles in temp dir
-	files, err := ParsePath(ctx, tempFiles...)
+	files, err := parser.Compile(ctx, names...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile proto file: %w", err)
 	}
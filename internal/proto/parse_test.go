@@ -1,6 +1,12 @@
 package proto
 
-import "testing"
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestExtractMessageDefinitionByName(t *testing.T) {
 	content := `
@@ -43,3 +49,136 @@ func TestExtractMessageDefinitionByNameNotFound(t *testing.T) {
 		t.Fatalf("expected error for not found message")
 	}
 }
+
+func TestParsePathWithImportPaths(t *testing.T) {
+	root := t.TempDir()
+	pkgDir := filepath.Join(root, "helloworld", "v1")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	request := `
+	syntax = "proto3";
+
+	package helloworld.v1;
+
+	import "helloworld/v1/common.proto";
+
+	message GreetingRequest {
+		Metadata metadata = 1;
+	}
+	`
+
+	common := `
+	syntax = "proto3";
+
+	package helloworld.v1;
+
+	message Metadata {
+		string id = 1;
+	}
+	`
+
+	requestPath := filepath.Join(pkgDir, "request.proto")
+	if err := os.WriteFile(requestPath, []byte(request), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(pkgDir, "common.proto"), []byte(common), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	files, err := ParsePathWithOptions(context.Background(), ParseOptions{ImportPaths: []string{root}}, requestPath)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	if files[0].Path() != "helloworld/v1/request.proto" {
+		t.Fatalf("Expected file to be named relative to import path, got: %s", files[0].Path())
+	}
+}
+
+func TestParseStringsWithResolver(t *testing.T) {
+	request := `
+	syntax = "proto3";
+
+	package helloworld.v1;
+
+	import "payments/v1/payment.proto";
+
+	message GreetingRequest {
+		payments.v1.Payment payment = 1;
+	}
+	`
+
+	payment := `
+	syntax = "proto3";
+
+	package payments.v1;
+
+	message Payment {
+		string id = 1;
+	}
+	`
+
+	resolver := func(importPath string) (string, error) {
+		if importPath == "payments/v1/payment.proto" {
+			return payment, nil
+		}
+		return "", fs.ErrNotExist
+	}
+
+	ctx := context.Background()
+	files, err := ParseStringsWithOptions(ctx, ParseOptions{Resolver: resolver}, ParseStringInput{
+		FileName:     "helloworld/v1/request.proto",
+		FileContents: request,
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	messages := ParseMessagesFromFile(files[0])
+	if messages[0].Fields[0].Kind != "payments.v1.Payment" {
+		t.Fatalf("Expected imported message type, got: %s", messages[0].Fields[0].Kind)
+	}
+
+	// Without the resolver the import cannot be found
+	_, err = ParseStrings(ctx, ParseStringInput{FileName: "request.proto", FileContents: request})
+	if err == nil {
+		t.Fatalf("Expected error for unresolved import")
+	}
+}
+
+func TestRelativeImportPathOutsideImportPaths(t *testing.T) {
+	_, err := RelativeImportPath([]string{t.TempDir()}, filepath.Join(t.TempDir(), "request.proto"))
+	if err == nil {
+		t.Fatalf("Expected error for file outside import paths")
+	}
+}
+
+func TestParseImports(t *testing.T) {
+	contents := `syntax = "proto3";
+
+package orders.v1;
+
+import "google/protobuf/timestamp.proto";
+import "common/v1/money.proto";
+
+message Order {}
+`
+
+	imports, err := ParseImports("orders/v1/order.proto", contents)
+	if err != nil {
+		t.Fatalf("Failed to parse imports: %v", err)
+	}
+
+	if len(imports) != 2 || imports[0] != "google/protobuf/timestamp.proto" || imports[1] != "common/v1/money.proto" {
+		t.Fatalf("Unexpected imports: %v", imports)
+	}
+
+	if !IsStandardImport(imports[0]) {
+		t.Fatalf("Expected %s to be a standard import", imports[0])
+	}
+	if IsStandardImport(imports[1]) {
+		t.Fatalf("Expected %s not to be a standard import", imports[1])
+	}
+}
@@ -8,6 +8,30 @@ import (
 	"github.com/bufbuild/protocompile/linker"
 )
 
+// Backwards compatibility rules
+const (
+	RuleFieldRemoved            = "field_removed"
+	RuleFieldRenamed            = "field_renamed"
+	RuleFieldTypeChanged        = "field_type_changed"
+	RuleFieldCardinalityChanged = "field_cardinality_changed"
+	RuleMessageRemoved          = "message_removed"
+)
+
+// CompatibilityError is returned when a message breaks one of the backwards compatibility rules
+type CompatibilityError struct {
+	Rule    string
+	Message string
+}
+
+func (e *CompatibilityError) Error() string {
+	return e.Message
+}
+
+// incompatible returns a CompatibilityError for a rule
+func incompatible(rule, format string, args ...any) error {
+	return &CompatibilityError{Rule: rule, Message: fmt.Sprintf(format, args...)}
+}
+
 // ValidateBackwardsCompatibleMessage checks if a message descriptor is backwards compatible with another
 func ValidateBackwardsCompatibleMessage(ctx context.Context, previous, latest ParsedMessage) error {
 
@@ -21,24 +45,24 @@ func ValidateBackwardsCompatibleMessage(ctx context.Context, previous, latest Pa
 
 		// Field was removed in latest version
 		if latestField == nil {
-			return fmt.Errorf("field '%s' was removed which breaks backwards compatibility", prevField.Name)
+			return incompatible(RuleFieldRemoved, "field '%s' was removed which breaks backwards compatibility", prevField.Name)
 		}
 
 		// Check name changes
 		if prevField.FullName != latestField.FullName {
-			return fmt.Errorf("field '%s' changed name to '%s' which breaks backwards compatibility",
+			return incompatible(RuleFieldRenamed, "field '%s' changed name to '%s' which breaks backwards compatibility",
 				prevField.Name, latestField.Name)
 		}
 
 		// Check field type changes
 		if prevField.Kind != latestField.Kind {
-			return fmt.Errorf("field '%s' changed type from %v to %v which breaks backwards compatibility",
+			return incompatible(RuleFieldTypeChanged, "field '%s' changed type from %v to %v which breaks backwards compatibility",
 				prevField.Name, prevField.Kind, latestField.Kind)
 		}
 
 		// Check cardinality changes (required/optional/repeated)
 		if prevField.Cardinality != latestField.Cardinality {
-			return fmt.Errorf("field '%s' changed cardinality from %v to %v which breaks backwards compatibility",
+			return incompatible(RuleFieldCardinalityChanged, "field '%s' changed cardinality from %v to %v which breaks backwards compatibility",
 				prevField.Name, prevField.Cardinality, latestField.Cardinality)
 		}
 	}
@@ -54,7 +78,7 @@ func ValidateBackwardsCompatibleMessages(ctx context.Context, prevMessages, late
 		latestMessage := GetMessageByName(latestMessages, prevMessage.FullName)
 
 		if latestMessage == nil {
-			return fmt.Errorf("message %s was removed which breaks backwards compatibility", prevMessage.FullName)
+			return incompatible(RuleMessageRemoved, "message %s was removed which breaks backwards compatibility", prevMessage.FullName)
 		}
 
 		if err := ValidateBackwardsCompatibleMessage(ctx, prevMessage, *latestMessage); err != nil {
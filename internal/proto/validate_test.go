@@ -2,6 +2,7 @@ package proto
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -173,6 +174,11 @@ func TestValidateBackwardsCompatibleMessagesRemovedField(t *testing.T) {
 	if err.Error() != expectedError {
 		t.Fatalf("Expected error: %s, got: %s", expectedError, err.Error())
 	}
+
+	var compatErr *CompatibilityError
+	if !errors.As(err, &compatErr) || compatErr.Rule != RuleFieldRemoved {
+		t.Fatalf("Expected a %s compatibility error, got: %v", RuleFieldRemoved, err)
+	}
 }
 
 func TestValidateBackwardsCompatibleMessagesRemovedNestedField(t *testing.T) {
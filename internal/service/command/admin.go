@@ -0,0 +1,244 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/config"
+	"github.com/cgund98/voer/internal/infra/sqlite"
+	"github.com/cgund98/voer/internal/service/webhook"
+)
+
+const (
+	// Flag names
+	inputFlag   = "input"
+	webhookFlag = "webhook"
+	eventFlag   = "event"
+	failedFlag  = "failed"
+)
+
+// createArchiveFile creates a file for an archive or snapshot, refusing to overwrite an existing one
+func createArchiveFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("file %s already exists", path)
+	} else if err != nil {
+		return nil, fmt.Errorf("error creating %s: %v", path, err)
+	}
+	return file, nil
+}
+
+// printArchiveStats prints what an export or import covered
+func printArchiveStats(stats *ctrl.ArchiveStats) {
+	fmt.Printf("  packages:         %d\n", stats.Packages)
+	fmt.Printf("  versions:         %d\n", stats.Versions)
+	if stats.SkippedVersions > 0 {
+		fmt.Printf("  skipped versions: %d (already present)\n", stats.SkippedVersions)
+	}
+	fmt.Printf("  files:            %d (%d blobs)\n", stats.Files, stats.Blobs)
+	fmt.Printf("  messages:         %d (%d versions)\n", stats.Messages, stats.MessageVersions)
+	fmt.Printf("  role grants:      %d\n", stats.RoleGrants)
+}
+
+// adminExportAction is the action for the admin export command
+func adminExportAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
+	output := cmd.String(outputFlag)
+
+	db, err := openDB(config)
+	if err != nil {
+		return fmt.Errorf("error initializing DB connection: %v", err)
+	}
+
+	file, err := createArchiveFile(output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	stats, err := ctrl.ExportRegistry(repo.NewSQLStore(db), w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		os.Remove(output)
+		return fmt.Errorf("error exporting registry: %v", err)
+	}
+
+	fmt.Printf("Exported registry to %s\n", output)
+	printArchiveStats(stats)
+	return nil
+}
+
+// adminImportAction is the action for the admin import command
+func adminImportAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
+	input := cmd.String(inputFlag)
+
+	db, err := openDB(config)
+	if err != nil {
+		return fmt.Errorf("error initializing DB connection: %v", err)
+	}
+
+	file, err := os.Open(input)
+	if err != nil {
+		return fmt.Errorf("error opening archive: %v", err)
+	}
+	defer file.Close()
+
+	stats, err := ctrl.ImportRegistry(ctx, repo.NewSQLStore(db), bufio.NewReader(file))
+	if err != nil {
+		return fmt.Errorf("error importing registry, nothing was changed: %v", err)
+	}
+
+	fmt.Printf("Imported registry from %s\n", input)
+	printArchiveStats(stats)
+	return nil
+}
+
+// adminSnapshotAction is the action for the admin snapshot command
+func adminSnapshotAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
+	output := cmd.String(outputFlag)
+
+	if config.DatabaseURL != "" {
+		return errors.New("snapshots are only supported for SQLite databases, use pg_dump or `voer admin export` for PostgreSQL")
+	}
+
+	db, err := openDB(config)
+	if err != nil {
+		return fmt.Errorf("error initializing DB connection: %v", err)
+	}
+
+	if err := sqlite.Snapshot(ctx, db, output); err != nil {
+		return fmt.Errorf("error taking snapshot: %v", err)
+	}
+
+	fmt.Printf("Wrote snapshot to %s\n", output)
+	return nil
+}
+
+// adminDeliveriesAction is the action for the admin deliveries command
+func adminDeliveriesAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
+	db, err := openDB(config)
+	if err != nil {
+		return fmt.Errorf("error initializing DB connection: %v", err)
+	}
+
+	filter := entity.WebhookDeliveryFilter{
+		Webhook: cmd.String(webhookFlag),
+		EventID: cmd.String(eventFlag),
+		Limit:   int(cmd.Uint(limitFlag)),
+	}
+	if cmd.Bool(failedFlag) {
+		filter.Result = webhook.ResultFailure
+	}
+
+	deliveries, err := repo.NewSQLStore(db).WebhookDeliveries().List(filter)
+	if err != nil {
+		return fmt.Errorf("error listing webhook deliveries: %v", err)
+	}
+
+	for _, delivery := range deliveries {
+		status := "-"
+		if delivery.StatusCode > 0 {
+			status = fmt.Sprintf("%d", delivery.StatusCode)
+		}
+
+		fmt.Printf("%s  %-20s %-28s %-24s %-5s %-28s #%-2d %-4s %-8s %dms\n",
+			delivery.CreatedAt.Local().Format(time.DateTime), delivery.Webhook, delivery.EventID, delivery.EventType,
+			fmt.Sprintf("v%d", delivery.Version), delivery.PackageName, delivery.Attempt, status, delivery.Result, delivery.DurationMS)
+
+		if delivery.Error != "" {
+			fmt.Printf("    error: %s\n", delivery.Error)
+		}
+	}
+	return nil
+}
+
+func makeAdminAction(config *config.Config, action func(context.Context, *config.Config, *cli.Command) error) func(ctx context.Context, cmd *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		return action(ctx, config, cmd)
+	}
+}
+
+// AdminCommand backs up, restores and migrates the registry's database
+func AdminCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:  "admin",
+		Usage: "Back up and restore the registry's database, and inspect webhook deliveries",
+		Commands: []*cli.Command{
+			{
+				Name:   "export",
+				Usage:  "Export every package, version and role grant to a portable archive",
+				Action: makeAdminAction(config, adminExportAction),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     outputFlag,
+						Usage:    "The archive file to create",
+						Required: true,
+					},
+				},
+			},
+			{
+				Name:   "import",
+				Usage:  "Import an archive into the registry, keeping version numbers. Versions that already exist are skipped",
+				Action: makeAdminAction(config, adminImportAction),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     inputFlag,
+						Usage:    "The archive file to import",
+						Required: true,
+					},
+				},
+			},
+			{
+				Name:   "snapshot",
+				Usage:  "Copy the SQLite database to a new file while the server is running",
+				Action: makeAdminAction(config, adminSnapshotAction),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     outputFlag,
+						Usage:    "The database file to create",
+						Required: true,
+					},
+				},
+			},
+			{
+				Name:   "deliveries",
+				Usage:  "List attempts to deliver events to webhooks, newest first",
+				Action: makeAdminAction(config, adminDeliveriesAction),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     webhookFlag,
+						Usage:    "Only list deliveries to the webhook with this name",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     eventFlag,
+						Usage:    "Only list deliveries of the event with this ID",
+						Required: false,
+					},
+					&cli.BoolFlag{
+						Name:     failedFlag,
+						Usage:    "Only list failed attempts",
+						Required: false,
+					},
+					&cli.UintFlag{
+						Name:     limitFlag,
+						Usage:    "Maximum number of deliveries to list",
+						Required: false,
+						Value:    100,
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/config"
+)
+
+const (
+	// Flag names
+	actorFlag  = "actor"
+	actionFlag = "action"
+	sinceFlag  = "since"
+	limitFlag  = "limit"
+)
+
+// printAuditEvent prints a single audit event
+func printAuditEvent(event *v1.AuditEvent) {
+	version := ""
+	if event.Version > 0 {
+		version = fmt.Sprintf("v%d", event.Version)
+	}
+
+	fmt.Printf("%s  %-24s %-14s %-24s %-5s %-8s %s\n",
+		event.CreatedAt.AsTime().Local().Format(time.DateTime), event.Actor, event.Action, event.PackageName, version, event.Result, event.Detail)
+
+	if event.Error != "" {
+		fmt.Printf("    error: %s\n", event.Error)
+	}
+}
+
+// auditAction is the action for the audit command
+func auditAction(ctx context.Context, cmd *cli.Command) error {
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	req := &v1.ListAuditEventsRequest{
+		Actor:       cmd.String(actorFlag),
+		Action:      cmd.String(actionFlag),
+		PackageName: cmd.String(packageFlag),
+		Limit:       uint32(cmd.Uint(limitFlag)),
+	}
+	if since := cmd.Duration(sinceFlag); since > 0 {
+		req.Since = timestamppb.New(time.Now().Add(-since))
+	}
+
+	res, err := client.ListAuditEvents(ctx, req)
+	if err != nil {
+		return fmt.Errorf("error listing audit events: %v", err)
+	}
+
+	for _, event := range res.Events {
+		printAuditEvent(event)
+	}
+	return nil
+}
+
+// AuditCommand lists the registry's audit log
+func AuditCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:   "audit",
+		Usage:  "List uploads, deletions and role changes recorded in the registry's audit log",
+		Action: auditAction,
+		Flags: registryFlags(config,
+			&cli.StringFlag{
+				Name:     actorFlag,
+				Usage:    "Only list events by this subject",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     actionFlag,
+				Usage:    "Only list events with this action: upload, delete_version, grant_role or revoke_role",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     packageFlag,
+				Usage:    "Only list events for this package",
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:     sinceFlag,
+				Usage:    "Only list events within this duration, e.g. 24h",
+				Required: false,
+			},
+			&cli.UintFlag{
+				Name:     limitFlag,
+				Usage:    "Maximum number of events to list",
+				Required: false,
+				Value:    100,
+			},
+		),
+	}
+}
@@ -0,0 +1,299 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/certs"
+	"github.com/cgund98/voer/internal/infra/config"
+	"github.com/cgund98/voer/internal/infra/errreason"
+)
+
+const (
+	// Flag names
+	tokenFlag   = "token"
+	tlsFlag     = "tls"
+	tlsCAFlag   = "tls-ca"
+	tlsCertFlag = "tls-cert"
+	tlsKeyFlag  = "tls-key"
+)
+
+// tokenFlagDef defines the flag for the credential sent to the registry, shared by commands that call the registry
+func tokenFlagDef(config *config.Config) cli.Flag {
+	return &cli.StringFlag{
+		Name:     tokenFlag,
+		Usage:    "API token or JWT sent to the registry. Defaults to the token saved by `voer login`",
+		Required: false,
+		Value:    config.Token,
+	}
+}
+
+// tlsFlagDefs defines the flags for connecting to the registry over TLS
+func tlsFlagDefs(config *config.Config) []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:     tlsFlag,
+			Usage:    "Connect to the registry over TLS. Implied by the other TLS flags",
+			Required: false,
+			Value:    config.TLSEnabled,
+		},
+		&cli.StringFlag{
+			Name:     tlsCAFlag,
+			Usage:    "CA certificate used to verify the registry. Defaults to the system roots",
+			Required: false,
+			Value:    config.TLSCAPath,
+		},
+		&cli.StringFlag{
+			Name:     tlsCertFlag,
+			Usage:    "Client certificate presented to the registry for mTLS",
+			Required: false,
+			Value:    config.TLSClientCertPath,
+		},
+		&cli.StringFlag{
+			Name:     tlsKeyFlag,
+			Usage:    "Key of the client certificate",
+			Required: false,
+			Value:    config.TLSClientKeyPath,
+		},
+	}
+}
+
+// connectionFlags returns the token and TLS flags used by every command that calls the registry
+func connectionFlags(config *config.Config) []cli.Flag {
+	return append([]cli.Flag{tokenFlagDef(config)}, tlsFlagDefs(config)...)
+}
+
+// registryFlags returns the flags shared by commands that only call the registry
+func registryFlags(config *config.Config, flags ...cli.Flag) []cli.Flag {
+	return append(append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     endpointFlag,
+			Usage:    "The registry endpoint",
+			Required: false,
+			Value:    config.GrpcEndpoint,
+		},
+	}, connectionFlags(config)...), flags...)
+}
+
+// transportCredentials returns TLS credentials when any TLS flag is set, and insecure credentials otherwise
+func transportCredentials(cmd *cli.Command) (credentials.TransportCredentials, error) {
+	return newTransportCredentials(cmd.Bool(tlsFlag), certs.ClientConfig{
+		CAPath:   cmd.String(tlsCAFlag),
+		CertPath: cmd.String(tlsCertFlag),
+		KeyPath:  cmd.String(tlsKeyFlag),
+	})
+}
+
+// newTransportCredentials returns TLS credentials when enabled or when any path is set, and insecure credentials otherwise
+func newTransportCredentials(enabled bool, clientConfig certs.ClientConfig) (credentials.TransportCredentials, error) {
+	if !enabled && clientConfig == (certs.ClientConfig{}) {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := certs.ClientTLSConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// tokenCredentials attaches a bearer token to every RPC
+type tokenCredentials struct {
+	token string
+
+	// Allows sending the token without TLS, which is only safe when it does not leave the host
+	allowInsecure bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return !c.allowInsecure
+}
+
+// isLoopbackEndpoint reports whether an endpoint is on the local host, e.g. localhost:8000 or a unix socket
+func isLoopbackEndpoint(endpoint string) bool {
+	if strings.HasPrefix(endpoint, "unix:") {
+		return true
+	}
+
+	// Strip the scheme of targets such as dns:///registry:8000
+	if i := strings.Index(endpoint, ":///"); i >= 0 {
+		endpoint = endpoint[i+len(":///"):]
+	}
+
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		host = endpoint
+	}
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// resolveToken returns the token given by flag or environment, falling back to the token saved for the endpoint
+func resolveToken(cmd *cli.Command, endpoint string) (string, error) {
+	if token := cmd.String(tokenFlag); token != "" {
+		return token, nil
+	}
+
+	credentials, err := config.LoadCredentials()
+	if err != nil {
+		return "", err
+	}
+
+	return credentials.Tokens[endpoint], nil
+}
+
+// dialRegistry creates a client for the registry at the endpoint given by the command's flags
+func dialRegistry(cmd *cli.Command) (v1.PackageSvcClient, error) {
+	endpoint := cmd.String(endpointFlag)
+
+	creds, err := transportCredentials(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring TLS: %v", err)
+	}
+
+	token, err := resolveToken(cmd, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRegistryClient(endpoint, creds, token)
+}
+
+// newRegistryClient creates a client for a registry, sending the token with every RPC when set.
+// Tokens are only sent without TLS to registries on the local host, where they cannot be intercepted.
+func newRegistryClient(endpoint string, creds credentials.TransportCredentials, token string) (v1.PackageSvcClient, error) {
+	opts := []grpc.DialOption{}
+	opts = append(opts, grpc.WithTransportCredentials(creds))
+	opts = append(opts, grpc.WithUnaryInterceptor(registryErrorInterceptor))
+
+	if token != "" {
+		secure := creds.Info().SecurityProtocol != "insecure"
+		if !secure && !isLoopbackEndpoint(endpoint) {
+			return nil, fmt.Errorf("refusing to send a token to %s without TLS, connect with --tls", endpoint)
+		}
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{token: token, allowInsecure: !secure}))
+	}
+
+	conn, err := grpc.NewClient(endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating client: %v", err)
+	}
+
+	return v1.NewPackageSvcClient(conn), nil
+}
+
+// Descriptions prefixed to errors whose message alone does not say the registry failed the request
+var statusCodeDescriptions = map[codes.Code]string{
+	codes.Unavailable:       "registry unavailable",
+	codes.DeadlineExceeded:  "registry timed out",
+	codes.Internal:          "registry error",
+	codes.Unknown:           "registry error",
+	codes.Unimplemented:     "not supported by the registry",
+	codes.ResourceExhausted: "request rejected by the registry",
+}
+
+// registryError is an error status returned by the registry, rendered for the CLI's output.
+// It keeps the status, so status.Code still reports the registry's code.
+type registryError struct {
+	status *status.Status
+}
+
+func (e *registryError) GRPCStatus() *status.Status {
+	return e.status
+}
+
+func (e *registryError) Error() string {
+	var b strings.Builder
+	if description, ok := statusCodeDescriptions[e.status.Code()]; ok {
+		b.WriteString(description + ": ")
+	}
+
+	// Violations are listed from the error details, below the first line of the message that summarizes them
+	violations := statusViolations(e.status)
+	if len(violations) == 0 {
+		b.WriteString(e.status.Message())
+	} else {
+		summary, _, _ := strings.Cut(e.status.Message(), "\n")
+		b.WriteString(summary)
+		for _, violation := range violations {
+			b.WriteString("\n  - " + violation)
+		}
+	}
+
+	if e.status.Code() == codes.Unauthenticated {
+		b.WriteString("\nrun `voer login` to save a token for the registry, or pass one with --" + tokenFlag)
+	}
+	if requestID := statusRequestID(e.status); e.status.Code() == codes.Internal && requestID != "" {
+		b.WriteString("\nthe registry logged the cause under request ID " + requestID)
+	}
+
+	return b.String()
+}
+
+// statusViolations lists the violations of an invalid or incompatible upload from the details of its status
+func statusViolations(st *status.Status) []string {
+	var reason string
+	var violations []string
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			reason = detail.Reason
+		case *errdetails.BadRequest:
+			for _, violation := range detail.FieldViolations {
+				if violation.Reason != "" {
+					violations = append(violations, fmt.Sprintf("%s: [%s] %s", violation.Field, violation.Reason, violation.Description))
+				} else {
+					violations = append(violations, fmt.Sprintf("%s: %s", violation.Field, violation.Description))
+				}
+			}
+		case *errdetails.PreconditionFailure:
+			for _, violation := range detail.Violations {
+				violations = append(violations, fmt.Sprintf("%s: [%s] %s", violation.Subject, violation.Type, violation.Description))
+			}
+		}
+	}
+
+	if reason != errreason.InvalidProto && reason != errreason.IncompatibleSchema {
+		return nil
+	}
+	return violations
+}
+
+// statusRequestID returns the request ID in the details of a status, if any
+func statusRequestID(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RequestInfo); ok {
+			return info.RequestId
+		}
+	}
+	return ""
+}
+
+// registryErrorInterceptor renders the error statuses returned by the registry as registryErrors
+func registryErrorInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if st, ok := status.FromError(err); ok && err != nil {
+		return &registryError{status: st}
+	}
+	return err
+}
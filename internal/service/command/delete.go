@@ -0,0 +1,66 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/config"
+)
+
+// deleteAction is the action for the delete command
+func deleteAction(ctx context.Context, cmd *cli.Command) error {
+	packageName := cmd.String(packageFlag)
+	version := cmd.Uint64(versionFlag)
+	restore := cmd.Bool(undoFlag)
+
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.DeletePackageVersion(ctx, &v1.DeletePackageVersionRequest{
+		PackageName: packageName,
+		Version:     version,
+		Restore:     restore,
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting package version: %v", err)
+	}
+
+	if restore {
+		fmt.Printf("Restored %s version %d\n", packageName, version)
+		return nil
+	}
+
+	fmt.Printf("Deleted %s version %d, it can be restored with --%s until it is purged\n", packageName, res.PackageVersion.Version, undoFlag)
+	return nil
+}
+
+// DeleteCommand soft deletes and restores package versions
+func DeleteCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:   "delete",
+		Usage:  "Delete a package version",
+		Action: deleteAction,
+		Flags: registryFlags(config,
+			&cli.StringFlag{
+				Name:     packageFlag,
+				Usage:    "The package name",
+				Required: true,
+			},
+			&cli.Uint64Flag{
+				Name:     versionFlag,
+				Usage:    "The version of the package to delete",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:     undoFlag,
+				Usage:    "Restore the deleted version instead",
+				Required: false,
+			},
+		),
+	}
+}
@@ -0,0 +1,137 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/config"
+)
+
+const (
+	// Flag names
+	messageFlag     = "message"
+	reasonFlag      = "reason"
+	replacementFlag = "replacement"
+	undoFlag        = "undo"
+)
+
+// formatDeprecation describes a deprecation for a warning
+func formatDeprecation(deprecation *v1.Deprecation) string {
+	msg := deprecation.Reason
+	if deprecation.Replacement != "" {
+		msg += fmt.Sprintf(" (use %s instead)", deprecation.Replacement)
+	}
+	return msg
+}
+
+// warnDeprecated prints a warning to stderr for a deprecated package version and each deprecated message in it
+func warnDeprecated(packageName string, res *v1.GetPackageVersionResponse) {
+	if deprecation := res.PackageVersion.GetDeprecation(); deprecation != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s version %d is deprecated: %s\n", packageName, res.PackageVersion.Version, formatDeprecation(deprecation))
+	}
+
+	for _, message := range res.DeprecatedMessages {
+		fmt.Fprintf(os.Stderr, "Warning: message %s.%s is deprecated: %s\n", packageName, message.Name, formatDeprecation(message.Deprecation))
+	}
+}
+
+// deprecateAction is the action for the deprecate command
+func deprecateAction(ctx context.Context, cmd *cli.Command) error {
+	packageName := cmd.String(packageFlag)
+	version := cmd.Uint64(versionFlag)
+	messageName := cmd.String(messageFlag)
+	undeprecate := cmd.Bool(undoFlag)
+
+	if (version == 0) == (messageName == "") {
+		return fmt.Errorf("exactly one of --%s or --%s is required", versionFlag, messageFlag)
+	}
+	if !undeprecate && cmd.String(reasonFlag) == "" {
+		return errors.New("a reason is required")
+	}
+
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	verb := "Deprecated"
+	if undeprecate {
+		verb = "Undeprecated"
+	}
+
+	if messageName != "" {
+		_, err = client.DeprecateMessage(ctx, &v1.DeprecateMessageRequest{
+			PackageName: packageName,
+			MessageName: messageName,
+			Reason:      cmd.String(reasonFlag),
+			Replacement: cmd.String(replacementFlag),
+			Undeprecate: undeprecate,
+		})
+		if err != nil {
+			return fmt.Errorf("error deprecating message: %v", err)
+		}
+
+		fmt.Printf("%s message %s.%s\n", verb, packageName, messageName)
+		return nil
+	}
+
+	_, err = client.DeprecatePackageVersion(ctx, &v1.DeprecatePackageVersionRequest{
+		PackageName: packageName,
+		Version:     version,
+		Reason:      cmd.String(reasonFlag),
+		Replacement: cmd.String(replacementFlag),
+		Undeprecate: undeprecate,
+	})
+	if err != nil {
+		return fmt.Errorf("error deprecating package version: %v", err)
+	}
+
+	fmt.Printf("%s %s version %d\n", verb, packageName, version)
+	return nil
+}
+
+// DeprecateCommand marks package versions and messages as deprecated
+func DeprecateCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:   "deprecate",
+		Usage:  "Mark a package version or message as deprecated",
+		Action: deprecateAction,
+		Flags: registryFlags(config,
+			&cli.StringFlag{
+				Name:     packageFlag,
+				Usage:    "The package name",
+				Required: true,
+			},
+			&cli.Uint64Flag{
+				Name:     versionFlag,
+				Usage:    "The version of the package to deprecate",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     messageFlag,
+				Usage:    "The message to deprecate, across all versions of the package",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     reasonFlag,
+				Usage:    "Why the version or message is deprecated",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     replacementFlag,
+				Usage:    "What to use instead, e.g. a newer version or message",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     undoFlag,
+				Usage:    "Clear the deprecation instead",
+				Required: false,
+			},
+		),
+	}
+}
@@ -10,8 +10,6 @@ import (
 	v1 "github.com/cgund98/voer/api/v1"
 	"github.com/cgund98/voer/internal/infra/config"
 	"github.com/urfave/cli/v3"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 const (
@@ -21,13 +19,26 @@ const (
 )
 
 // downloadAction is the action for the download command
-func downloadAction(ctx context.Context, cmd *cli.Command) error {
+func downloadAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
 
-	endpoint := cmd.String(endpointFlag)
 	outputDir := cmd.String(outputFlag)
 	packageName := cmd.String(packageFlag)
 	version := cmd.Uint64(versionFlag)
 
+	if packageName == "" {
+		return errors.New("package name is required")
+	}
+
+	// Fall back to the package's directory in the workspace
+	if outputDir == "" && config.Workspace != nil {
+		if packageDir, ok := config.Workspace.PackageDir(packageName); ok {
+			if err := os.MkdirAll(packageDir, 0755); err != nil {
+				return fmt.Errorf("error creating package directory: %v", err)
+			}
+			outputDir = packageDir
+		}
+	}
+
 	if outputDir == "" {
 		return errors.New("output directory is required")
 	}
@@ -37,19 +48,11 @@ func downloadAction(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("output directory does not exist: %v", err)
 	}
 
-	if packageName == "" {
-		return errors.New("package name is required")
-	}
-
 	// Init client
-	opts := []grpc.DialOption{}
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-
-	conn, err := grpc.NewClient(endpoint, opts...)
+	client, err := dialRegistry(cmd)
 	if err != nil {
-		return fmt.Errorf("error creating client: %v", err)
+		return err
 	}
-	client := v1.NewPackageSvcClient(conn)
 
 	// Upload the proto files
 	getReq := &v1.GetPackageVersionRequest{
@@ -62,6 +65,12 @@ func downloadAction(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return fmt.Errorf("error validating proto files: %v", err)
 	}
+	warnRenamed(packageName, downloadRes)
+	warnDeprecated(packageName, downloadRes)
+
+	if err := verifyFileDigests(packageName, downloadRes.PackageVersion.GetVersion(), downloadRes.Files); err != nil {
+		return err
+	}
 
 	// Write the proto files to the output directory
 	for _, file := range downloadRes.Files {
@@ -79,13 +88,19 @@ func downloadAction(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+func makeDownloadAction(config *config.Config) func(ctx context.Context, cmd *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		return downloadAction(ctx, config, cmd)
+	}
+}
+
 // Download will download that a proto file is backwards compatible with another
 func DownloadCommand(config *config.Config) *cli.Command {
 	return &cli.Command{
 		Name:   "download",
 		Usage:  "Download that a proto file is backwards compatible with any existing packages",
-		Action: downloadAction,
-		Flags: []cli.Flag{
+		Action: makeDownloadAction(config),
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:     endpointFlag,
 				Usage:    "The endpoint to upload the proto file to",
@@ -94,8 +109,8 @@ func DownloadCommand(config *config.Config) *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:     outputFlag,
-				Usage:    "The output directory",
-				Required: true,
+				Usage:    "The output directory. Defaults to the package's directory in the workspace",
+				Required: false,
 			},
 			&cli.StringFlag{
 				Name:     packageFlag,
@@ -107,6 +122,6 @@ func DownloadCommand(config *config.Config) *cli.Command {
 				Usage:    "The version of the package",
 				Required: true,
 			},
-		},
+		}, connectionFlags(config)...),
 	}
 }
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/config"
+)
+
+const (
+	// Flag names
+	typeFlag = "type"
+)
+
+// eventsAction is the action for the events command
+func eventsAction(ctx context.Context, cmd *cli.Command) error {
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	stream, err := client.WatchEvents(ctx, &v1.WatchEventsRequest{
+		Types:          cmd.StringSlice(typeFlag),
+		PackagePattern: cmd.String(packageFlag),
+	})
+	if err != nil {
+		return fmt.Errorf("error watching events: %v", err)
+	}
+
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error watching events: %v", err)
+		}
+
+		fmt.Printf("%s  %-26s %-24s v%-4d %-24s %s\n",
+			event.CreatedAt.AsTime().Local().Format(time.DateTime), event.Type, event.PackageName, event.Version, event.Actor, event.Id)
+	}
+}
+
+// EventsCommand streams the registry's package events
+func EventsCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:   "events",
+		Usage:  "Stream package version events from the registry as they happen",
+		Action: eventsAction,
+		Flags: registryFlags(config,
+			&cli.StringSliceFlag{
+				Name:     typeFlag,
+				Usage:    "Only stream events of this type: package_version.created, package_version.deleted or package_version.restored. May be repeated",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     packageFlag,
+				Usage:    "Only stream events of packages matching this pattern, e.g. payments.*",
+				Required: false,
+			},
+		),
+	}
+}
@@ -0,0 +1,218 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bufbuild/protocompile/linker"
+	"github.com/urfave/cli/v3"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/config"
+	"github.com/cgund98/voer/internal/proto"
+)
+
+const (
+	// Flag names
+	protoPathFlag = "proto-path"
+)
+
+// protoPathFlagDef defines the flag for additional import paths, shared by commands that parse proto files
+func protoPathFlagDef() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:     protoPathFlag,
+		Aliases:  []string{"I"},
+		Usage:    "Directory to search for imports. May be repeated",
+		Required: false,
+	}
+}
+
+var errNoProtoFiles = errors.New("no proto files found")
+
+// findProtoFiles will find all the proto files in a given path, skipping any excluded directories
+func findProtoFiles(rootPath string, exclude ...string) ([]string, error) {
+	// Look for all .proto files recursively under the given path
+	var protoFiles []string
+
+	// Build lookup of excluded directories
+	excluded := make(map[string]bool)
+	for _, dir := range exclude {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		excluded[absDir] = true
+	}
+
+	// Walk through all files under rootPath
+	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// Skip excluded directories
+		if info.IsDir() && len(excluded) > 0 {
+			absPath, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			if excluded[absPath] {
+				return filepath.SkipDir
+			}
+		}
+		// Check if file has .proto extension
+		if filepath.Ext(path) == ".proto" {
+			protoFiles = append(protoFiles, path)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("error walking path: %w", err)
+	}
+
+	if len(protoFiles) == 0 {
+		return nil, fmt.Errorf("%w in %s", errNoProtoFiles, rootPath)
+	}
+
+	return protoFiles, nil
+}
+
+// resolveProtoFiles finds the proto files to operate on.
+// Falls back to the workspace roots when no path is given.
+func resolveProtoFiles(config *config.Config, protoPath string) ([]string, error) {
+	workspace := config.Workspace
+
+	if protoPath != "" {
+		if workspace == nil {
+			return findProtoFiles(protoPath)
+		}
+		return findProtoFiles(protoPath, workspace.ResolvePaths(workspace.Exclude)...)
+	}
+
+	if workspace == nil || len(workspace.Roots) == 0 {
+		return nil, errors.New("proto path is required when no workspace roots are configured")
+	}
+
+	filePaths := make([]string, 0)
+	for _, root := range workspace.ResolvePaths(workspace.Roots) {
+		rootFiles, err := findProtoFiles(root, workspace.ResolvePaths(workspace.Exclude)...)
+		if err != nil && !errors.Is(err, errNoProtoFiles) {
+			return nil, err
+		}
+		filePaths = append(filePaths, rootFiles...)
+	}
+
+	if len(filePaths) == 0 {
+		return nil, fmt.Errorf("%w in workspace roots", errNoProtoFiles)
+	}
+
+	return filePaths, nil
+}
+
+// importPathsFor builds the list of import paths used to parse proto files.
+// Explicit --proto-path flags take precedence over the workspace import paths, followed by the proto roots.
+func importPathsFor(config *config.Config, cmd *cli.Command, protoPath string) []string {
+	importPaths := append([]string{}, cmd.StringSlice(protoPathFlag)...)
+
+	workspace := config.Workspace
+	if workspace != nil {
+		importPaths = append(importPaths, workspace.ResolvePaths(workspace.ImportPaths)...)
+
+		// Dependencies pulled with `voer pull`
+		if len(workspace.Dependencies) > 0 {
+			importPaths = append(importPaths, workspace.ResolveDepsDir())
+		}
+	}
+
+	if protoPath != "" {
+		root := protoPath
+		if info, err := os.Stat(protoPath); err == nil && !info.IsDir() {
+			root = filepath.Dir(protoPath)
+		}
+		importPaths = append(importPaths, root)
+	} else if workspace != nil {
+		importPaths = append(importPaths, workspace.ResolvePaths(workspace.Roots)...)
+	}
+
+	return importPaths
+}
+
+// registryImportResolver resolves imports that cannot be found locally from packages in the registry
+func registryImportResolver(ctx context.Context, client v1.PackageSvcClient) proto.ImportResolver {
+	var mu sync.Mutex
+	cache := make(map[string]string)
+
+	return func(importPath string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if contents, ok := cache[importPath]; ok {
+			return contents, nil
+		}
+
+		res, err := client.ResolveImport(ctx, &v1.ResolveImportRequest{ImportPath: importPath})
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s from registry: %v: %w", importPath, err, fs.ErrNotExist)
+		}
+
+		cache[importPath] = res.File.ProtoContents
+		return res.File.ProtoContents, nil
+	}
+}
+
+// parseProtoFiles parses each proto file on disk.
+// Returns the parsed files and a mapping of each file's import path to its location on disk.
+func parseProtoFiles(ctx context.Context, opts proto.ParseOptions, filePaths []string) (linker.Files, map[string]string, error) {
+	protoFiles := make(linker.Files, 0)
+	diskPaths := make(map[string]string)
+
+	for _, filePath := range filePaths {
+		curFiles, err := proto.ParsePathWithOptions(ctx, opts, filePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing proto files: %v", err)
+		}
+
+		for _, file := range curFiles {
+			diskPaths[file.Path()] = filePath
+		}
+		protoFiles = append(protoFiles, curFiles...)
+	}
+
+	return protoFiles, diskPaths, nil
+}
+
+// buildPackageFiles groups parsed files by package and reads their contents for sending to the registry
+func buildPackageFiles(ctx context.Context, protoFiles linker.Files, diskPaths map[string]string) ([]*v1.PackageFile, error) {
+	packages := make([]*v1.PackageFile, 0)
+
+	// Group based on package name
+	for packageName, files := range proto.GroupByPackage(protoFiles) {
+
+		// Get file contents
+		packageFiles := make([]*v1.ProtoFile, 0)
+		for _, file := range files {
+			fileContents, err := proto.ReadStrings(ctx, diskPaths[file.Path()])
+			if err != nil {
+				return nil, fmt.Errorf("error reading proto files: %v", err)
+			}
+
+			packageFiles = append(packageFiles, &v1.ProtoFile{
+				FileName:     filepath.Base(file.Path()),
+				FileContents: fileContents[0].FileContents,
+				ImportPath:   file.Path(),
+			})
+		}
+
+		packages = append(packages, &v1.PackageFile{
+			PackageName: packageName,
+			Files:       packageFiles,
+		})
+	}
+
+	return packages, nil
+}
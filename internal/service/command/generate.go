@@ -0,0 +1,180 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/cgund98/voer/internal/infra/config"
+	"github.com/cgund98/voer/internal/proto"
+)
+
+const (
+	// Flag names
+	generateConfigFlag = "generate-config"
+)
+
+// loadGenerateConfig loads the generate config from a flag, falling back to the workspace.
+// Output directories are resolved relative to the workspace when the workspace config is used.
+func loadGenerateConfig(cfg *config.Config, path string) (*proto.GenerateConfig, error) {
+	if path != "" {
+		return proto.LoadGenerateConfig(path)
+	}
+
+	workspace := cfg.Workspace
+	if workspace == nil || workspace.Generate == nil {
+		return nil, fmt.Errorf("no generate config given with --%s or in the workspace file", generateConfigFlag)
+	}
+
+	resolved := &proto.GenerateConfig{}
+	for _, plugin := range workspace.Generate.Plugins {
+		plugin.Out = workspace.ResolvePath(plugin.Out)
+		if plugin.Path != "" && strings.ContainsRune(plugin.Path, '/') {
+			plugin.Path = workspace.ResolvePath(plugin.Path)
+		}
+		resolved.Plugins = append(resolved.Plugins, plugin)
+	}
+
+	return resolved, nil
+}
+
+// generateAction is the action for the generate command
+func generateAction(ctx context.Context, cfg *config.Config, cmd *cli.Command) error {
+	packageName := cmd.String(packageFlag)
+	version := cmd.Uint64(versionFlag)
+
+	if packageName == "" {
+		return errors.New("package name is required")
+	}
+
+	generateConfig, err := loadGenerateConfig(cfg, cmd.String(generateConfigFlag))
+	if err != nil {
+		return err
+	}
+
+	// Pin the requested version, or use the latest when no version is given
+	constraint := config.VersionConstraint{}
+	if version != 0 {
+		constraint, err = config.ParseVersionConstraint(fmt.Sprint(version))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Init client
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Download the package and everything it imports
+	resolver := &dependencyResolver{
+		client:      client,
+		lock:        &config.LockFile{},
+		update:      true,
+		constraints: map[string]config.VersionConstraint{packageName: constraint},
+		pulled:      make(map[string]*pulledPackage),
+	}
+
+	if err := resolver.resolve(ctx, []config.Dependency{{Package: packageName}}); err != nil {
+		return err
+	}
+
+	target := resolver.pulled[packageName]
+	fmt.Printf("Generating code for %s version %d\n", packageName, target.locked.Version)
+
+	// Compile the package, resolving imports from the downloaded dependencies
+	dependencyFiles := make(map[string]string)
+	for _, pkg := range resolver.pulled {
+		for _, file := range pkg.files {
+			dependencyFiles[fileImportPath(file)] = file.ProtoContents
+		}
+	}
+
+	parseInputs := make([]proto.ParseStringInput, 0, len(target.files))
+	for _, file := range target.files {
+		parseInputs = append(parseInputs, proto.ParseStringInput{
+			FileName:     fileImportPath(file),
+			FileContents: file.ProtoContents,
+		})
+	}
+
+	parseOpts := proto.ParseOptions{
+		Resolver: func(importPath string) (string, error) {
+			contents, ok := dependencyFiles[importPath]
+			if !ok {
+				return "", fmt.Errorf("import %s not found: %w", importPath, fs.ErrNotExist)
+			}
+			return contents, nil
+		},
+	}
+
+	protoFiles, err := proto.ParseStringsWithOptions(ctx, parseOpts, parseInputs...)
+	if err != nil {
+		return fmt.Errorf("error parsing proto files: %v", err)
+	}
+
+	// Run each plugin
+	for _, plugin := range generateConfig.Plugins {
+		req := proto.BuildCodeGeneratorRequest(protoFiles, strings.Join(plugin.Opt, ","))
+
+		res, err := proto.RunPlugin(ctx, plugin, req)
+		if err != nil {
+			return err
+		}
+
+		written, err := proto.WriteGeneratedFiles(plugin.Out, res)
+		if err != nil {
+			return err
+		}
+
+		for _, filePath := range written {
+			fmt.Printf("Writing %s output to '%s'\n", plugin.Name, filePath)
+		}
+	}
+
+	fmt.Println("Generated code successfully")
+	return nil
+}
+
+func makeGenerateAction(config *config.Config) func(ctx context.Context, cmd *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		return generateAction(ctx, config, cmd)
+	}
+}
+
+// GenerateCommand runs protoc plugins against a package version in the registry
+func GenerateCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:   "generate",
+		Usage:  "Generate code for a package version in the registry using protoc plugins",
+		Action: makeGenerateAction(config),
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     endpointFlag,
+				Usage:    "The endpoint to download the package from",
+				Required: false,
+				Value:    config.GrpcEndpoint,
+			},
+			&cli.StringFlag{
+				Name:     packageFlag,
+				Usage:    "The package name",
+				Required: true,
+			},
+			&cli.Uint64Flag{
+				Name:     versionFlag,
+				Usage:    "The version of the package. Defaults to the latest version",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     generateConfigFlag,
+				Usage:    "Path to a generate configuration file. Defaults to the workspace's generate settings",
+				Required: false,
+			},
+		}, connectionFlags(config)...),
+	}
+}
@@ -0,0 +1,110 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/config"
+	"github.com/cgund98/voer/internal/proto"
+)
+
+const (
+	// Flag names
+	lintConfigFlag = "lint-config"
+	listRulesFlag  = "list-rules"
+)
+
+// printLintViolations prints lint violations returned by the vör service
+func printLintViolations(violations []*v1.LintViolation) {
+	for _, violation := range violations {
+		fmt.Printf("  %s %s:%d: [%s] %s\n", violation.PackageName, violation.FileName, violation.Line, violation.Rule, violation.Message)
+	}
+}
+
+// lintAction is the action for the lint command
+func lintAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
+	protoPath := cmd.String(protoFlag)
+	lintConfigPath := cmd.String(lintConfigFlag)
+
+	if cmd.Bool(listRulesFlag) {
+		for _, rule := range proto.LintRules() {
+			fmt.Printf("%-28s %s\n", rule.Name(), rule.Description())
+		}
+		return nil
+	}
+
+	// Load lint rules, falling back to the workspace and then to all registered rules
+	lintConfig := &proto.LintConfig{}
+	if lintConfigPath != "" {
+		var err error
+		lintConfig, err = proto.LoadLintConfig(lintConfigPath)
+		if err != nil {
+			return err
+		}
+	} else if config.Workspace != nil && config.Workspace.Lint != nil {
+		lintConfig = config.Workspace.Lint
+	}
+
+	// Scan for .proto files under the given path
+	filePaths, err := resolveProtoFiles(config, protoPath)
+	if err != nil {
+		return err
+	}
+
+	parseOpts := proto.ParseOptions{
+		ImportPaths: importPathsFor(config, cmd, protoPath),
+	}
+	protoFiles, _, err := parseProtoFiles(ctx, parseOpts, filePaths)
+	if err != nil {
+		return err
+	}
+
+	violations := proto.Lint(ctx, protoFiles, lintConfig)
+	if len(violations) == 0 {
+		fmt.Println("No lint violations found")
+		return nil
+	}
+
+	for _, violation := range violations {
+		fmt.Println(violation.String())
+	}
+
+	return fmt.Errorf("found %d lint violation(s)", len(violations))
+}
+
+func makeLintAction(config *config.Config) func(ctx context.Context, cmd *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		return lintAction(ctx, config, cmd)
+	}
+}
+
+// LintCommand will check a set of proto files against the registry's style rules
+func LintCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:   "lint",
+		Usage:  "Check proto files against style rules",
+		Action: makeLintAction(config),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     protoFlag,
+				Usage:    "Path to the proto files to lint. Defaults to the workspace roots",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     lintConfigFlag,
+				Usage:    "Path to a YAML or JSON lint configuration. Defaults to the workspace lint settings, or all rules",
+				Required: false,
+				Value:    config.LintConfigPath,
+			},
+			&cli.BoolFlag{
+				Name:     listRulesFlag,
+				Usage:    "List all available lint rules",
+				Required: false,
+			},
+			protoPathFlagDef(),
+		},
+	}
+}
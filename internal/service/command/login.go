@@ -0,0 +1,130 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/config"
+)
+
+// loginAction is the action for the login command
+func loginAction(ctx context.Context, cmd *cli.Command) error {
+	endpoint := cmd.String(endpointFlag)
+
+	// Prompt for the token when it is not given by flag or environment
+	token := cmd.String(tokenFlag)
+	if token == "" {
+		fmt.Printf("Token for %s: ", endpoint)
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("error reading token: %v", err)
+		}
+		token = strings.TrimSpace(line)
+	}
+
+	if token == "" {
+		return errors.New("token is required")
+	}
+
+	if err := cmd.Set(tokenFlag, token); err != nil {
+		return err
+	}
+
+	// Verify the token before saving it
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.WhoAmI(ctx, &v1.WhoAmIRequest{})
+	if err != nil {
+		return fmt.Errorf("error verifying token: %v", err)
+	}
+
+	credentials, err := config.LoadCredentials()
+	if err != nil {
+		return err
+	}
+	credentials.Tokens[endpoint] = token
+
+	if err := config.SaveCredentials(credentials); err != nil {
+		return err
+	}
+
+	if res.Subject == "" {
+		fmt.Printf("Saved token for %s. Authentication is disabled on this registry.\n", endpoint)
+	} else {
+		fmt.Printf("Logged in to %s as %s\n", endpoint, res.Subject)
+	}
+	return nil
+}
+
+// logoutAction is the action for the logout command
+func logoutAction(ctx context.Context, cmd *cli.Command) error {
+	endpoint := cmd.String(endpointFlag)
+
+	credentials, err := config.LoadCredentials()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := credentials.Tokens[endpoint]; !ok {
+		fmt.Printf("Not logged in to %s\n", endpoint)
+		return nil
+	}
+	delete(credentials.Tokens, endpoint)
+
+	if err := config.SaveCredentials(credentials); err != nil {
+		return err
+	}
+
+	fmt.Printf("Logged out of %s\n", endpoint)
+	return nil
+}
+
+// LoginCommand saves a token for a registry endpoint
+func LoginCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:   "login",
+		Usage:  "Verify and save an API token or JWT for a registry",
+		Action: loginAction,
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     endpointFlag,
+				Usage:    "The endpoint to log in to",
+				Required: false,
+				Value:    config.GrpcEndpoint,
+			},
+			&cli.StringFlag{
+				Name:     tokenFlag,
+				Usage:    "The token to save. Read from stdin when omitted",
+				Required: false,
+				Value:    config.Token,
+			},
+		}, tlsFlagDefs(config)...),
+	}
+}
+
+// LogoutCommand removes the saved token for a registry endpoint
+func LogoutCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:   "logout",
+		Usage:  "Remove the saved token for a registry",
+		Action: logoutAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     endpointFlag,
+				Usage:    "The endpoint to log out of",
+				Required: false,
+				Value:    config.GrpcEndpoint,
+			},
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/config"
+)
+
+const (
+	// Flag names
+	newNameFlag = "new-name"
+	yesFlag     = "yes"
+)
+
+// warnRenamed prints a warning to stderr when a package was fetched by a former name
+func warnRenamed(packageName string, res *v1.GetPackageVersionResponse) {
+	if res.PackageName != "" && res.PackageName != packageName {
+		fmt.Fprintf(os.Stderr, "Warning: package %s was renamed to %s\n", packageName, res.PackageName)
+	}
+}
+
+// packageDeleteAction is the action for the package delete command
+func packageDeleteAction(ctx context.Context, cmd *cli.Command) error {
+	packageName := cmd.String(packageFlag)
+
+	// Ask for the package name again unless confirmed by flag
+	if !cmd.Bool(yesFlag) {
+		fmt.Printf("This permanently deletes every version of %s. Type the package name to confirm: ", packageName)
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("error reading confirmation: %v", err)
+		}
+		if strings.TrimSpace(line) != packageName {
+			return fmt.Errorf("confirmation does not match %s, aborting", packageName)
+		}
+	}
+
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.DeletePackage(ctx, &v1.DeletePackageRequest{PackageName: packageName}); err != nil {
+		return fmt.Errorf("error deleting package: %v", err)
+	}
+
+	fmt.Printf("Deleted package %s\n", packageName)
+	return nil
+}
+
+// packageRenameAction is the action for the package rename command
+func packageRenameAction(ctx context.Context, cmd *cli.Command) error {
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.RenamePackage(ctx, &v1.RenamePackageRequest{
+		PackageName: cmd.String(packageFlag),
+		NewName:     cmd.String(newNameFlag),
+	})
+	if err != nil {
+		return fmt.Errorf("error renaming package: %v", err)
+	}
+
+	fmt.Printf("Renamed package %s to %s\n", cmd.String(packageFlag), res.Package.Name)
+	if len(res.Aliases) > 0 {
+		fmt.Printf("Former names still resolving to it: %s\n", strings.Join(res.Aliases, ", "))
+	}
+	return nil
+}
+
+// PackageCommand deletes and renames packages
+func PackageCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:  "package",
+		Usage: "Delete and rename packages",
+		Commands: []*cli.Command{
+			{
+				Name:   "delete",
+				Usage:  "Permanently delete a package and all of its versions",
+				Action: packageDeleteAction,
+				Flags: registryFlags(config,
+					&cli.StringFlag{
+						Name:     packageFlag,
+						Usage:    "The package name",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:     yesFlag,
+						Usage:    "Skip the confirmation prompt",
+						Required: false,
+					},
+				),
+			},
+			{
+				Name:   "rename",
+				Usage:  "Rename a package, keeping the old name as an alias",
+				Action: packageRenameAction,
+				Flags: registryFlags(config,
+					&cli.StringFlag{
+						Name:     packageFlag,
+						Usage:    "The current package name",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     newNameFlag,
+						Usage:    "The new package name",
+						Required: true,
+					},
+				),
+			},
+		},
+	}
+}
@@ -0,0 +1,341 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/config"
+	"github.com/cgund98/voer/internal/proto"
+)
+
+const (
+	// Flag names
+	updateFlag = "update"
+)
+
+// pulledPackage is a package version downloaded from the registry
+type pulledPackage struct {
+	locked config.LockedPackage
+	files  []*v1.PackageVersionFile
+}
+
+// dependencyResolver resolves workspace dependencies and their transitive imports against the registry
+type dependencyResolver struct {
+	client v1.PackageSvcClient
+	lock   *config.LockFile
+	update bool
+
+	// Constraints of the dependencies declared in the workspace
+	constraints map[string]config.VersionConstraint
+
+	pulled map[string]*pulledPackage
+	order  []string
+}
+
+// fileImportPath returns the import path of a downloaded file
+func fileImportPath(file *v1.PackageVersionFile) string {
+	if file.ImportPath != "" {
+		return file.ImportPath
+	}
+	return file.FileName
+}
+
+// verifyFileDigests checks each downloaded file against the digest reported by the registry.
+// Files from registries that do not report digests are accepted.
+func verifyFileDigests(packageName string, version uint64, files []*v1.PackageVersionFile) error {
+	for _, file := range files {
+		if file.Digest == "" {
+			continue
+		}
+		if digest := proto.DigestFile(file.ProtoContents); digest != file.Digest {
+			return fmt.Errorf("integrity check failed for %s version %d: registry reported digest %s for %s, downloaded file has digest %s",
+				packageName, version, file.Digest, file.FileName, digest)
+		}
+	}
+	return nil
+}
+
+// selectVersion picks the version of a package to pull.
+// The locked version is kept as long as it still satisfies the declared constraint.
+func (r *dependencyResolver) selectVersion(ctx context.Context, packageName string) (uint64, *config.LockedPackage, error) {
+	constraint := r.constraints[packageName]
+
+	if !r.update {
+		if locked, ok := r.lock.Find(packageName); ok && constraint.Matches(locked.Version) {
+			return locked.Version, locked, nil
+		}
+	}
+
+	res, err := r.client.ListPackageVersions(ctx, &v1.ListPackageVersionsRequest{PackageName: packageName})
+	if err != nil {
+		return 0, nil, fmt.Errorf("error listing versions of %s: %v", packageName, err)
+	}
+
+	// Versions are listed newest first
+	for _, pkgVersion := range res.PackageVersions {
+		if constraint.Matches(pkgVersion.Version) {
+			return pkgVersion.Version, nil, nil
+		}
+	}
+
+	return 0, nil, fmt.Errorf("no version of %s satisfies its version constraint", packageName)
+}
+
+// pull downloads a package and verifies its contents against the registry and the lock file
+func (r *dependencyResolver) pull(ctx context.Context, packageName string) error {
+	if _, ok := r.pulled[packageName]; ok {
+		return nil
+	}
+
+	version, locked, err := r.selectVersion(ctx, packageName)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.client.GetPackageVersion(ctx, &v1.GetPackageVersionRequest{
+		PackageName: packageName,
+		Version:     version,
+	})
+	if err != nil {
+		return fmt.Errorf("error downloading %s version %d: %v", packageName, version, err)
+	}
+	warnRenamed(packageName, res)
+	warnDeprecated(packageName, res)
+
+	// Verify integrity
+	if err := verifyFileDigests(packageName, version, res.Files); err != nil {
+		return err
+	}
+
+	hashInputs := make([]proto.ParseStringInput, 0, len(res.Files))
+	importPaths := make([]string, 0, len(res.Files))
+	for _, file := range res.Files {
+		hashInputs = append(hashInputs, proto.ParseStringInput{
+			FileName:     fileImportPath(file),
+			FileContents: file.ProtoContents,
+		})
+		importPaths = append(importPaths, fileImportPath(file))
+	}
+	contentHash := proto.HashFiles(hashInputs...)
+
+	if expected := res.PackageVersion.GetContentHash(); expected != "" && expected != contentHash {
+		return fmt.Errorf("integrity check failed for %s version %d: registry reported hash %s, downloaded files hash to %s", packageName, version, expected, contentHash)
+	}
+	if locked != nil && locked.ContentHash != contentHash {
+		return fmt.Errorf("integrity check failed for %s version %d: lock file expects hash %s, downloaded files hash to %s", packageName, version, locked.ContentHash, contentHash)
+	}
+
+	r.pulled[packageName] = &pulledPackage{
+		locked: config.LockedPackage{
+			Package:     packageName,
+			Version:     version,
+			ContentHash: contentHash,
+			Files:       importPaths,
+		},
+		files: res.Files,
+	}
+	r.order = append(r.order, packageName)
+
+	return nil
+}
+
+// missingImports returns the imports of pulled files that are not provided by any pulled package
+func (r *dependencyResolver) missingImports() ([]string, error) {
+	provided := make(map[string]bool)
+	for _, pkg := range r.pulled {
+		for _, file := range pkg.files {
+			provided[fileImportPath(file)] = true
+		}
+	}
+
+	missing := make([]string, 0)
+	for _, packageName := range r.order {
+		for _, file := range r.pulled[packageName].files {
+			imports, err := proto.ParseImports(fileImportPath(file), file.ProtoContents)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, importPath := range imports {
+				if provided[importPath] || proto.IsStandardImport(importPath) {
+					continue
+				}
+				provided[importPath] = true
+				missing = append(missing, importPath)
+			}
+		}
+	}
+
+	return missing, nil
+}
+
+// resolve pulls the declared dependencies, followed by every package they transitively import
+func (r *dependencyResolver) resolve(ctx context.Context, deps []config.Dependency) error {
+	for _, dep := range deps {
+		if err := r.pull(ctx, dep.Package); err != nil {
+			return err
+		}
+	}
+
+	for {
+		missing, err := r.missingImports()
+		if err != nil {
+			return err
+		}
+
+		if len(missing) == 0 {
+			return nil
+		}
+
+		for _, importPath := range missing {
+			res, err := r.client.ResolveImport(ctx, &v1.ResolveImportRequest{ImportPath: importPath})
+			if err != nil {
+				return fmt.Errorf("error resolving import %s: %v", importPath, err)
+			}
+
+			if pkg, ok := r.pulled[res.PackageName]; ok {
+				return fmt.Errorf("import %s is not provided by %s version %d", importPath, res.PackageName, pkg.locked.Version)
+			}
+
+			if err := r.pull(ctx, res.PackageName); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writePulledFiles writes pulled files into a directory tree mirroring their import paths.
+// Files recorded in the previous lock file that are no longer pulled are removed.
+func writePulledFiles(depsDir string, previous *config.LockFile, pulled map[string]*pulledPackage) error {
+	written := make(map[string]bool)
+
+	for _, pkg := range pulled {
+		for _, file := range pkg.files {
+			importPath := fileImportPath(file)
+			if !filepath.IsLocal(importPath) {
+				return fmt.Errorf("refusing to write file outside the dependency directory: %s", importPath)
+			}
+
+			filePath := filepath.Join(depsDir, filepath.FromSlash(importPath))
+			if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+				return fmt.Errorf("error creating dependency directory: %v", err)
+			}
+
+			if err := os.WriteFile(filePath, []byte(file.ProtoContents), 0644); err != nil {
+				return fmt.Errorf("error writing proto file: %v", err)
+			}
+			written[importPath] = true
+		}
+	}
+
+	for _, locked := range previous.Packages {
+		for _, importPath := range locked.Files {
+			if written[importPath] || !filepath.IsLocal(importPath) {
+				continue
+			}
+
+			err := os.Remove(filepath.Join(depsDir, filepath.FromSlash(importPath)))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("error removing stale proto file: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// pullAction is the action for the pull command
+func pullAction(ctx context.Context, cfg *config.Config, cmd *cli.Command) error {
+
+	workspace := cfg.Workspace
+	if workspace == nil {
+		return fmt.Errorf("no %s workspace file found", config.WorkspaceFileName)
+	}
+
+	lockPath := workspace.LockFilePath()
+	lock, err := config.LoadLockFile(lockPath)
+	if err != nil {
+		return err
+	}
+
+	constraints := make(map[string]config.VersionConstraint)
+	for _, dep := range workspace.Dependencies {
+		constraint, err := config.ParseVersionConstraint(dep.Version)
+		if err != nil {
+			return err
+		}
+		constraints[dep.Package] = constraint
+	}
+
+	// Init client
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	resolver := &dependencyResolver{
+		client:      client,
+		lock:        lock,
+		update:      cmd.Bool(updateFlag),
+		constraints: constraints,
+		pulled:      make(map[string]*pulledPackage),
+	}
+
+	if err := resolver.resolve(ctx, workspace.Dependencies); err != nil {
+		return err
+	}
+
+	// Write the files and record the resolved versions
+	depsDir := workspace.ResolveDepsDir()
+	if err := writePulledFiles(depsDir, lock, resolver.pulled); err != nil {
+		return err
+	}
+
+	newLock := &config.LockFile{}
+	for _, packageName := range resolver.order {
+		pkg := resolver.pulled[packageName]
+		newLock.Packages = append(newLock.Packages, pkg.locked)
+		fmt.Printf("Pulled %s version %d\n", packageName, pkg.locked.Version)
+	}
+
+	if err := config.WriteLockFile(lockPath, newLock); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d package(s) to '%s' and updated %s\n", len(newLock.Packages), depsDir, lockPath)
+	return nil
+}
+
+func makePullAction(config *config.Config) func(ctx context.Context, cmd *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		return pullAction(ctx, config, cmd)
+	}
+}
+
+// PullCommand downloads the workspace's dependencies and their transitive imports
+func PullCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:   "pull",
+		Usage:  "Download the workspace's dependencies and record their exact versions in the lock file",
+		Action: makePullAction(config),
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     endpointFlag,
+				Usage:    "The endpoint to download dependencies from",
+				Required: false,
+				Value:    config.GrpcEndpoint,
+			},
+			&cli.BoolFlag{
+				Name:     updateFlag,
+				Usage:    "Resolve the newest versions satisfying each constraint, ignoring the lock file",
+				Required: false,
+			},
+		}, connectionFlags(config)...),
+	}
+}
@@ -0,0 +1,135 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/config"
+)
+
+const (
+	// Flag names
+	subjectFlag  = "subject"
+	roleFlag     = "role"
+	packagesFlag = "packages"
+	idFlag       = "id"
+)
+
+// printRoleGrant prints a single role grant
+func printRoleGrant(grant *v1.RoleGrant) {
+	fmt.Printf("#%-5d %-32s %-10s %s\n", grant.Id, grant.Subject, grant.Role, grant.PackagePattern)
+}
+
+// roleGrantAction is the action for the role grant command
+func roleGrantAction(ctx context.Context, cmd *cli.Command) error {
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.GrantRole(ctx, &v1.GrantRoleRequest{
+		Subject:        cmd.String(subjectFlag),
+		Role:           cmd.String(roleFlag),
+		PackagePattern: cmd.String(packagesFlag),
+	})
+	if err != nil {
+		return fmt.Errorf("error granting role: %v", err)
+	}
+
+	fmt.Println("Granted role:")
+	printRoleGrant(res.Grant)
+	return nil
+}
+
+// roleRevokeAction is the action for the role revoke command
+func roleRevokeAction(ctx context.Context, cmd *cli.Command) error {
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	id := cmd.Uint64(idFlag)
+	if _, err := client.RevokeRole(ctx, &v1.RevokeRoleRequest{Id: id}); err != nil {
+		return fmt.Errorf("error revoking role: %v", err)
+	}
+
+	fmt.Printf("Revoked role grant #%d\n", id)
+	return nil
+}
+
+// roleListAction is the action for the role list command
+func roleListAction(ctx context.Context, cmd *cli.Command) error {
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.ListRoleGrants(ctx, &v1.ListRoleGrantsRequest{Subject: cmd.String(subjectFlag)})
+	if err != nil {
+		return fmt.Errorf("error listing roles: %v", err)
+	}
+
+	for _, grant := range res.Grants {
+		printRoleGrant(grant)
+	}
+	return nil
+}
+
+// RoleCommand manages role grants on package patterns
+func RoleCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:  "role",
+		Usage: "Manage the roles granted on packages",
+		Commands: []*cli.Command{
+			{
+				Name:   "grant",
+				Usage:  "Grant a role to a subject on a package pattern",
+				Action: roleGrantAction,
+				Flags: registryFlags(config,
+					&cli.StringFlag{
+						Name:     subjectFlag,
+						Usage:    "The subject, e.g. token:ci or the subject claim of a JWT",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     roleFlag,
+						Usage:    "One of reader, publisher or admin",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     packagesFlag,
+						Usage:    "Package pattern: *, an exact package name, or a prefix such as payments.*",
+						Required: true,
+					},
+				),
+			},
+			{
+				Name:   "revoke",
+				Usage:  "Revoke a role grant",
+				Action: roleRevokeAction,
+				Flags: registryFlags(config,
+					&cli.Uint64Flag{
+						Name:     idFlag,
+						Usage:    "The ID of the grant, as shown by `voer role list`",
+						Required: true,
+					},
+				),
+			},
+			{
+				Name:   "list",
+				Usage:  "List role grants",
+				Action: roleListAction,
+				Flags: registryFlags(config,
+					&cli.StringFlag{
+						Name:     subjectFlag,
+						Usage:    "Only list grants for this subject",
+						Required: false,
+					},
+				),
+			},
+		},
+	}
+}
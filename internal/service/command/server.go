@@ -2,19 +2,41 @@ package command
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"gorm.io/gorm"
 
 	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/certs"
 	"github.com/cgund98/voer/internal/infra/config"
+	"github.com/cgund98/voer/internal/infra/events"
+	"github.com/cgund98/voer/internal/infra/health"
 	"github.com/cgund98/voer/internal/infra/logging"
+	"github.com/cgund98/voer/internal/infra/metrics"
+	"github.com/cgund98/voer/internal/infra/postgres"
 	"github.com/cgund98/voer/internal/infra/sqlite"
+	"github.com/cgund98/voer/internal/infra/tracing"
+	"github.com/cgund98/voer/internal/proto"
 	"github.com/cgund98/voer/internal/service/frontend"
 	svc "github.com/cgund98/voer/internal/service/grpc"
+	"github.com/cgund98/voer/internal/service/mirror"
+	"github.com/cgund98/voer/internal/service/webhook"
 )
 
 const (
@@ -23,40 +45,359 @@ const (
 	frontendPortFlag = "frontend-port"
 )
 
+// purgeInterval is how often deleted package versions past their retention period are purged
+const purgeInterval = time.Hour
+
+// newAuthenticator builds the authenticator for the server's endpoints.
+// Returns nil when authentication is disabled.
+func newAuthenticator(config *config.Config, store repo.Store) (auth.Authenticator, error) {
+	if !config.AuthEnabled {
+		return nil, nil
+	}
+
+	chain := auth.Chain{&auth.TokenAuthenticator{Store: store}}
+
+	if config.AuthJWKSPath != "" {
+		jwtAuthenticator, err := auth.NewJWTAuthenticator(auth.JWTConfig{
+			JWKSPath:     config.AuthJWKSPath,
+			Issuer:       config.AuthJWTIssuer,
+			Audience:     config.AuthJWTAudience,
+			SubjectClaim: config.AuthJWTSubjectClaim,
+		})
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, jwtAuthenticator)
+	}
+
+	return chain, nil
+}
+
+// newServerTLSConfig builds the TLS config shared by the gRPC and frontend listeners.
+// Returns nil when no server certificate is configured.
+func newServerTLSConfig(config *config.Config) (*tls.Config, error) {
+	if config.TLSCertPath == "" && config.TLSKeyPath == "" {
+		if config.TLSClientCAPath != "" {
+			return nil, fmt.Errorf("a server certificate is required to verify client certificates")
+		}
+		return nil, nil
+	}
+
+	reloader, err := certs.NewReloader(certs.ServerConfig{
+		CertPath:     config.TLSCertPath,
+		KeyPath:      config.TLSKeyPath,
+		ClientCAPath: config.TLSClientCAPath,
+	}, certs.DefaultReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return reloader.TLSConfig(), nil
+}
+
+// runPurgeJob periodically purges package versions deleted longer than the retention period, until ctx is cancelled
+func runPurgeJob(ctx context.Context, store repo.Store, retention time.Duration) error {
+	logging.Logger.Info("Starting purge job...", "retention", retention.String(), "interval", purgeInterval.String())
+
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := ctrl.PurgeDeletedPackageVersions(ctx, store, time.Now().Add(-retention))
+		if err != nil {
+			logging.Logger.Error("Failed to purge deleted package versions", "error", err)
+		} else if purged > 0 {
+			logging.Logger.Info("Purged deleted package versions", "count", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// newMirror builds the mirror of the configured upstream registry, connecting with the client-side TLS settings
+func newMirror(config *config.Config, store repo.Store) (*mirror.Mirror, error) {
+	creds, err := newTransportCredentials(config.TLSEnabled, certs.ClientConfig{
+		CAPath:   config.TLSCAPath,
+		CertPath: config.TLSClientCertPath,
+		KeyPath:  config.TLSClientKeyPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error configuring TLS: %v", err)
+	}
+
+	client, err := newRegistryClient(config.MirrorUpstream, creds, config.MirrorToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return mirror.NewMirror(config.MirrorUpstream, client, store), nil
+}
+
+// openDB connects to PostgreSQL when a database URL is configured, and to SQLite otherwise
+func openDB(config *config.Config) (*gorm.DB, error) {
+	if config.DatabaseURL != "" {
+		return postgres.NewDB(config.DatabaseURL)
+	}
+	return sqlite.NewDB(config.SqliteDBPath)
+}
+
+// listeners are the gRPC and frontend servers, which are stopped together
+type listeners struct {
+	grpc     *grpc.Server
+	frontend *frontend.Service
+
+	// Reports whether the servers are ready to receive requests
+	readiness *health.Readiness
+
+	// Ends long-lived streams, which would otherwise hold up the shutdown
+	endStreams context.CancelFunc
+}
+
+// shutdown stops the servers gracefully. The servers report they are not ready first, and keep serving for the delay
+// while load balancers stop routing requests to them. Their listeners are then closed, and in-flight requests are
+// given until the timeout to finish before they are cancelled.
+func (l listeners) shutdown(delay, timeout time.Duration) {
+	logging.Logger.Info("Shutting down...", "delay", delay.String(), "timeout", timeout.String())
+	l.readiness.ShutDown()
+	time.Sleep(delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	l.endStreams()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+
+		stopped := make(chan struct{})
+		go func() {
+			l.grpc.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			logging.Logger.Warn("Cancelling gRPC requests that did not finish in time")
+			l.grpc.Stop()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+
+		if err := l.frontend.Shutdown(ctx); err != nil {
+			logging.Logger.Warn("Cancelling frontend requests that did not finish in time", "error", err)
+		}
+	}()
+	wg.Wait()
+}
+
 // serverAction is the action for the port command
 func serverAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
 	// Flags
 	grpcPort := cmd.Int(grpcPortFlag)
 	frontendPort := cmd.Int(frontendPortFlag)
 
+	// Shut down gracefully when interrupted, e.g. by Kubernetes when a pod is replaced
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Export traces
+	if config.TracingEndpoint != "" {
+		shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+			Endpoint:    config.TracingEndpoint,
+			Insecure:    config.TracingInsecure,
+			SampleRatio: config.TracingSampleRatio,
+		})
+		if err != nil {
+			return fmt.Errorf("error initializing tracing: %v", err)
+		}
+		defer func() {
+			if err := shutdownTracing(context.Background()); err != nil {
+				logging.Logger.Error("Failed to flush traces", "error", err)
+			}
+		}()
+	}
+
 	// Initialize DB connection
-	db, err := sqlite.NewDB(config.SqliteDBPath)
+	db, err := openDB(config)
+	if err != nil {
+		return fmt.Errorf("error initializing DB connection: %v", err)
+	}
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return fmt.Errorf("error instrumenting DB connection: %v", err)
+	}
+	if err := db.Use(tracing.GormPlugin{System: db.Dialector.Name()}); err != nil {
+		return fmt.Errorf("error instrumenting DB connection: %v", err)
+	}
+	sqlDB, err := db.DB()
 	if err != nil {
 		return fmt.Errorf("error initializing DB connection: %v", err)
 	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			logging.Logger.Error("Failed to close DB connection", "error", err)
+		}
+	}()
+	store := repo.NewSQLStore(db)
+	metrics.RegisterStoreGauges(store)
+
+	// The server is ready while its database is reachable, until it starts shutting down
+	readiness := health.NewReadiness(sqlDB.PingContext)
+
+	// Load lint rules
+	var lintConfig *proto.LintConfig
+	if config.LintConfigPath != "" {
+		lintConfig, err = proto.LoadLintConfig(config.LintConfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading lint config: %v", err)
+		}
+	}
+
+	// Load webhooks
+	var webhookConfig *webhook.Config
+	if config.WebhookConfigPath != "" {
+		webhookConfig, err = webhook.LoadConfig(config.WebhookConfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading webhook config: %v", err)
+		}
+	}
+
+	// Initialize authentication
+	authenticator, err := newAuthenticator(config, store)
+	if err != nil {
+		return fmt.Errorf("error initializing authentication: %v", err)
+	}
+
+	// Roles are only enforced when callers are authenticated
+	var authorizer *auth.Authorizer
+	if authenticator != nil {
+		authorizer = &auth.Authorizer{Store: store, AdminSubjects: config.AuthAdminSubjects}
+	}
+
+	// Initialize TLS
+	tlsConfig, err := newServerTLSConfig(config)
+	if err != nil {
+		return fmt.Errorf("error initializing TLS: %v", err)
+	}
+
+	// Initialize mirroring of an upstream registry
+	var mirrorSvc *mirror.Mirror
+	if config.MirrorUpstream != "" {
+		mirrorSvc, err = newMirror(config, store)
+		if err != nil {
+			return fmt.Errorf("error initializing mirror: %v", err)
+		}
+		mirrorSvc.RegisterMetrics(metrics.Registry)
+	}
 
 	// Initialize gRPC server
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(svc.LoggerInterceptor))
+	// Panics are recovered and errors converted to statuses within the access log and metrics, so they are recorded
+	// with their final status codes.
+	// Rate limits are checked by IP address before authentication, so failed attempts are limited too, and by subject
+	// after it.
+	interceptors := []grpc.UnaryServerInterceptor{
+		svc.RequestIDInterceptor,
+		svc.AccessLogInterceptor,
+		svc.MetricsInterceptor,
+		svc.RecoveryInterceptor,
+		svc.ErrorStatusInterceptor,
+		svc.RequestMetadataInterceptor,
+	}
+	streamCtx, endStreams := context.WithCancel(context.Background())
+	defer endStreams()
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		svc.RequestIDStreamInterceptor,
+		svc.AccessLogStreamInterceptor,
+		svc.MetricsStreamInterceptor,
+		svc.RecoveryStreamInterceptor,
+		svc.ErrorStatusStreamInterceptor,
+		svc.DrainStreamInterceptor(streamCtx),
+	}
+	if config.GrpcPeerRateLimit > 0 {
+		peerLimiter := svc.NewRateLimiter(config.GrpcPeerRateLimit, config.GrpcPeerRateBurst)
+		interceptors = append(interceptors, svc.RateLimitInterceptor(peerLimiter))
+		streamInterceptors = append(streamInterceptors, svc.RateLimitStreamInterceptor(peerLimiter))
+	}
+	if authenticator != nil {
+		interceptors = append(interceptors, svc.AuthInterceptor(authenticator))
+		streamInterceptors = append(streamInterceptors, svc.AuthStreamInterceptor(authenticator))
+	}
+	if config.GrpcRateLimit > 0 {
+		limiter := svc.NewRateLimiter(config.GrpcRateLimit, config.GrpcRateBurst)
+		interceptors = append(interceptors, svc.RateLimitInterceptor(limiter))
+		streamInterceptors = append(streamInterceptors, svc.RateLimitStreamInterceptor(limiter))
+	}
+	if mirrorSvc != nil {
+		interceptors = append(interceptors, svc.ReadOnlyInterceptor(config.MirrorUpstream))
+	}
+	serverOpts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.MaxRecvMsgSize(config.GrpcMaxRequestBytes),
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	}
+	if tlsConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
+
+	// Changes to packages are published to change streams and webhooks
+	bus := events.NewBus()
 
 	// Register services
-	v1.RegisterPackageSvcServer(grpcServer, &svc.PackageSvc{
-		DB: db,
-	})
+	v1.RegisterPackageSvcServer(grpcServer, svc.NewPackageSvc(store, ctrl.UploadPolicy{
+		LintConfig:             lintConfig,
+		BlockDeprecatedImports: config.BlockDeprecatedImports,
+	}, authorizer, bus))
+	healthgrpc.RegisterHealthServer(grpcServer, svc.NewHealthSvc(readiness))
 
-	// Start frontend and gRPC servers in parallel with an ErrGroup
-	eg, _ := errgroup.WithContext(ctx)
+	// Start frontend and gRPC servers in parallel with an ErrGroup. The servers shut down once the server is
+	// interrupted or any of them fails.
+	eg, egCtx := errgroup.WithContext(ctx)
+
+	// Background jobs run until the servers are stopped, so changes made by in-flight requests are still delivered
+	jobsCtx, stopJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopJobs()
+
+	// Deliver events to webhooks in the background
+	if webhookConfig != nil {
+		dispatcher := webhook.NewDispatcher(webhookConfig, store.WebhookDeliveries(), bus)
+		eg.Go(func() error {
+			return dispatcher.Run(jobsCtx)
+		})
+	}
+
+	// Mirrors replicate packages from upstream in the background, including its purges
+	if mirrorSvc != nil {
+		eg.Go(func() error {
+			return mirrorSvc.Run(jobsCtx)
+		})
+	}
+
+	// Purge deleted package versions in the background
+	if config.DeletedVersionRetention > 0 && mirrorSvc == nil {
+		eg.Go(func() error {
+			return runPurgeJob(jobsCtx, store, config.DeletedVersionRetention)
+		})
+	}
 
 	// Start frontend service
-	frontendSvc := frontend.NewService(config, db)
+	frontendSvc := frontend.NewService(config, store, authenticator, authorizer, bus, mirrorSvc, readiness)
 	frontendSvc.Init()
 
 	eg.Go(func() error {
-		return frontendSvc.Start(frontendPort)
+		return frontendSvc.Start(frontendPort, tlsConfig)
 	})
 
 	// Start gRPC server
 	eg.Go(func() error {
-		logging.Logger.Info("Starting gRPC server...", "port", grpcPort)
+		logging.Logger.Info("Starting gRPC server...", "port", grpcPort, "tls", tlsConfig != nil)
 		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
 		if err != nil {
 			return fmt.Errorf("failed to listen: %v", err)
@@ -69,10 +410,28 @@ func serverAction(ctx context.Context, config *config.Config, cmd *cli.Command)
 		return nil
 	})
 
+	// Stop the servers, then the background jobs, once asked to stop
+	eg.Go(func() error {
+		<-egCtx.Done()
+
+		// Let a second interrupt stop the server immediately
+		stop()
+
+		listeners{
+			grpc:       grpcServer,
+			frontend:   frontendSvc,
+			readiness:  readiness,
+			endStreams: endStreams,
+		}.shutdown(config.ShutdownDelay, config.ShutdownTimeout)
+		stopJobs()
+		return nil
+	})
+
 	if err := eg.Wait(); err != nil {
 		return fmt.Errorf("error encountered by server: %v", err)
 	}
 
+	logging.Logger.Info("Server stopped")
 	return nil
 }
 
@@ -0,0 +1,148 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/config"
+)
+
+const (
+	// Flag names
+	nameFlag      = "name"
+	expiresInFlag = "expires-in"
+)
+
+// formatOptionalTime formats a timestamp, or "never" when it is unset
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// tokenCreateAction is the action for the token create command
+func tokenCreateAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
+	name := cmd.String(nameFlag)
+	expiresIn := cmd.Duration(expiresInFlag)
+
+	if name == "" {
+		return errors.New("token name is required")
+	}
+
+	db, err := openDB(config)
+	if err != nil {
+		return fmt.Errorf("error initializing DB connection: %v", err)
+	}
+
+	var expiresAt *time.Time
+	if expiresIn > 0 {
+		t := time.Now().Add(expiresIn)
+		expiresAt = &t
+	}
+
+	token, apiToken, err := auth.IssueAPIToken(repo.NewSQLStore(db), name, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created token '%s' (expires: %s). It will not be shown again:\n", apiToken.Name, formatOptionalTime(apiToken.ExpiresAt))
+	fmt.Println(token)
+	return nil
+}
+
+// tokenListAction is the action for the token list command
+func tokenListAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
+	db, err := openDB(config)
+	if err != nil {
+		return fmt.Errorf("error initializing DB connection: %v", err)
+	}
+
+	tokens, err := repo.NewSQLStore(db).APITokens().List()
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		fmt.Printf("%-24s created: %s  expires: %s  last used: %s\n", token.Name, token.CreatedAt.Format(time.RFC3339), formatOptionalTime(token.ExpiresAt), formatOptionalTime(token.LastUsedAt))
+	}
+	return nil
+}
+
+// tokenRevokeAction is the action for the token revoke command
+func tokenRevokeAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
+	name := cmd.String(nameFlag)
+
+	db, err := openDB(config)
+	if err != nil {
+		return fmt.Errorf("error initializing DB connection: %v", err)
+	}
+
+	deleted, err := repo.NewSQLStore(db).APITokens().Delete(name)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return fmt.Errorf("token '%s' not found", name)
+	}
+
+	fmt.Printf("Revoked token '%s'\n", name)
+	return nil
+}
+
+func makeTokenAction(config *config.Config, action func(context.Context, *config.Config, *cli.Command) error) func(ctx context.Context, cmd *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		return action(ctx, config, cmd)
+	}
+}
+
+// TokenCommand manages the API tokens stored in the server's database
+func TokenCommand(config *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:  "token",
+		Usage: "Manage API tokens in the registry's database",
+		Commands: []*cli.Command{
+			{
+				Name:   "create",
+				Usage:  "Create an API token",
+				Action: makeTokenAction(config, tokenCreateAction),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     nameFlag,
+						Usage:    "A unique name for the token",
+						Required: true,
+					},
+					&cli.DurationFlag{
+						Name:     expiresInFlag,
+						Usage:    "How long the token is valid for, e.g. 720h. Never expires when omitted",
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:   "list",
+				Usage:  "List API tokens",
+				Action: makeTokenAction(config, tokenListAction),
+			},
+			{
+				Name:   "revoke",
+				Usage:  "Revoke an API token",
+				Action: makeTokenAction(config, tokenRevokeAction),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     nameFlag,
+						Usage:    "The name of the token",
+						Required: true,
+					},
+				},
+			},
+		},
+	}
+}
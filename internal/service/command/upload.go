@@ -2,15 +2,9 @@ package command
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 
-	"github.com/bufbuild/protocompile/linker"
 	"github.com/urfave/cli/v3"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
 	v1 "github.com/cgund98/voer/api/v1"
 	"github.com/cgund98/voer/internal/infra/config"
@@ -21,60 +15,35 @@ const (
 	// Flag names
 	protoFlag    = "proto"
 	endpointFlag = "endpoint"
+	dryRunFlag   = "dry-run"
 )
 
-// findProtoFiles will find all the proto files in a given path
-func findProtoFiles(rootPath string) ([]string, error) {
-	// Look for all .proto files recursively under the given path
-	var protoFiles []string
-
-	// Walk through all files under rootPath
-	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		// Check if file has .proto extension
-		if filepath.Ext(path) == ".proto" {
-			protoFiles = append(protoFiles, path)
-		}
-		return nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("error walking path: %w", err)
-	}
-
-	if len(protoFiles) == 0 {
-		return nil, fmt.Errorf("no proto files found in %s", rootPath)
-	}
-
-	return protoFiles, nil
-}
-
 // uploadAction is the action for the upload command
-func uploadAction(ctx context.Context, cmd *cli.Command) error {
+func uploadAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
 	// Flags
 	protoPath := cmd.String(protoFlag)
-	endpoint := cmd.String(endpointFlag)
+	dryRun := cmd.Bool(dryRunFlag)
 
-	if protoPath == "" {
-		return errors.New("proto file path is required")
+	// Init client
+	client, err := dialRegistry(cmd)
+	if err != nil {
+		return err
 	}
 
 	// Scan for .proto files under the given path
-	filePaths, err := findProtoFiles(protoPath)
+	filePaths, err := resolveProtoFiles(config, protoPath)
 	if err != nil {
 		return err
 	}
 
-	// Parse the proto files
-	protoFiles := make(linker.Files, 0)
-	for _, filePath := range filePaths {
-		curFiles, err := proto.ParsePath(ctx, filePath)
-		if err != nil {
-			return fmt.Errorf("error parsing proto files: %v", err)
-		}
-		protoFiles = append(protoFiles, curFiles...)
+	// Parse the proto files, resolving missing imports from the registry
+	parseOpts := proto.ParseOptions{
+		ImportPaths: importPathsFor(config, cmd, protoPath),
+		Resolver:    registryImportResolver(ctx, client),
+	}
+	protoFiles, diskPaths, err := parseProtoFiles(ctx, parseOpts, filePaths)
+	if err != nil {
+		return err
 	}
 
 	// Validate package names are unique
@@ -83,41 +52,15 @@ func uploadAction(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	// Init client
-	opts := []grpc.DialOption{}
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-
-	conn, err := grpc.NewClient(endpoint, opts...)
+	// Upload the proto files
+	packages, err := buildPackageFiles(ctx, protoFiles, diskPaths)
 	if err != nil {
-		return fmt.Errorf("error creating client: %v", err)
+		return err
 	}
-	client := v1.NewPackageSvcClient(conn)
-
-	// Upload the proto files
-	uploadReq := &v1.UploadPackageVersionRequest{}
-
-	// Group based on package name
-	packageFiles := proto.GroupByPackage(protoFiles)
-	for packageName, files := range packageFiles {
-
-		// Get file contents
-		packageFiles := make([]*v1.ProtoFile, 0)
-		for _, file := range files {
-			fileContents, err := proto.ReadStrings(ctx, file.Path())
-			if err != nil {
-				return fmt.Errorf("error reading proto files: %v", err)
-			}
-
-			packageFiles = append(packageFiles, &v1.ProtoFile{
-				FileName:     filepath.Base(file.Path()),
-				FileContents: fileContents[0].FileContents,
-			})
-		}
 
-		uploadReq.Packages = append(uploadReq.Packages, &v1.PackageFile{
-			PackageName: packageName,
-			Files:       packageFiles,
-		})
+	uploadReq := &v1.UploadPackageVersionRequest{
+		Packages: packages,
+		DryRun:   dryRun,
 	}
 
 	// Upload the proto files
@@ -126,25 +69,73 @@ func uploadAction(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("error uploading proto files: %v", err)
 	}
 
-	fmt.Println("Uploaded schema successfully.")
+	if uploadRes.DryRun {
+		printDryRunPreview(uploadRes)
+		return nil
+	}
+
+	if uploadRes.Unchanged {
+		fmt.Println("Schema is unchanged. No new versions were created.")
+	} else {
+		fmt.Println("Uploaded schema successfully.")
+	}
 	for _, pkgVer := range uploadRes.PackageVersions {
 		fmt.Printf("Created new version of package #%d with version %d\n", pkgVer.PackageId, pkgVer.Version)
 	}
+	for _, pkgVer := range uploadRes.UnchangedPackageVersions {
+		fmt.Printf("Package #%d is unchanged at version %d\n", pkgVer.PackageId, pkgVer.Version)
+	}
 
 	return nil
 }
 
+// printDryRunPreview prints what an upload would have created
+func printDryRunPreview(uploadRes *v1.UploadPackageVersionResponse) {
+	fmt.Println("Dry run: no changes were persisted.")
+	for _, pkgVer := range uploadRes.PackageVersions {
+		fmt.Printf("Would create version %d of package #%d\n", pkgVer.Version, pkgVer.PackageId)
+	}
+	for _, pkgVer := range uploadRes.UnchangedPackageVersions {
+		fmt.Printf("Package #%d is unchanged at version %d\n", pkgVer.PackageId, pkgVer.Version)
+	}
+
+	for _, change := range uploadRes.MessageChanges {
+		fmt.Printf("  %s message %s.%s (version %d)\n", change.ChangeType, change.PackageName, change.MessageName, change.Version)
+	}
+
+	if len(uploadRes.LintViolations) > 0 {
+		fmt.Printf("Found %d lint violation(s):\n", len(uploadRes.LintViolations))
+		printLintViolations(uploadRes.LintViolations)
+	}
+
+	if len(uploadRes.Violations) == 0 {
+		fmt.Println("No compatibility violations found.")
+		return
+	}
+
+	fmt.Printf("Found %d compatibility violation(s):\n", len(uploadRes.Violations))
+	for _, violation := range uploadRes.Violations {
+		fmt.Printf("  %s.%s: %s\n", violation.PackageName, violation.MessageName, violation.Error)
+	}
+}
+
+func makeUploadAction(config *config.Config) func(ctx context.Context, cmd *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		return uploadAction(ctx, config, cmd)
+	}
+}
+
 // UploadCommand will upload a set of proto files to the vör service
 func UploadCommand(config *config.Config) *cli.Command {
 	return &cli.Command{
 		Name:   "upload",
 		Usage:  "Upload a proto file to the vör service",
-		Action: uploadAction,
-		Flags: []cli.Flag{
+		Action: makeUploadAction(config),
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:     protoFlag,
-				Usage:    "The proto file to upload",
-				Required: true,
+				Usage:    "The proto file to upload. Defaults to the workspace roots",
+				Required: false,
 			},
 			&cli.StringFlag{
 				Name:     endpointFlag,
@@ -152,6 +143,12 @@ func UploadCommand(config *config.Config) *cli.Command {
 				Required: false,
 				Value:    config.GrpcEndpoint,
 			},
-		},
+			protoPathFlagDef(),
+			&cli.BoolFlag{
+				Name:     dryRunFlag,
+				Usage:    "Preview the upload without persisting any changes",
+				Required: false,
+			},
+		}, connectionFlags(config)...),
 	}
 }
@@ -4,50 +4,38 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"path/filepath"
 
-	"github.com/bufbuild/protocompile/linker"
 	v1 "github.com/cgund98/voer/api/v1"
 	"github.com/cgund98/voer/internal/infra/config"
 	"github.com/cgund98/voer/internal/proto"
 	"github.com/urfave/cli/v3"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // validateAction is the action for the validate command
-func validateAction(ctx context.Context, cmd *cli.Command) error {
+func validateAction(ctx context.Context, config *config.Config, cmd *cli.Command) error {
 
 	protoPath := cmd.String(protoFlag)
-	endpoint := cmd.String(endpointFlag)
-
-	if protoPath == "" {
-		return errors.New("proto path is required")
-	}
 
 	// Init client
-	opts := []grpc.DialOption{}
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-
-	conn, err := grpc.NewClient(endpoint, opts...)
+	client, err := dialRegistry(cmd)
 	if err != nil {
-		return fmt.Errorf("error creating client: %v", err)
+		return err
 	}
-	client := v1.NewPackageSvcClient(conn)
 
 	// Scan for .proto files under the given path
-	filePaths, err := findProtoFiles(protoPath)
+	filePaths, err := resolveProtoFiles(config, protoPath)
 	if err != nil {
 		return err
 	}
 
-	protoFiles := make(linker.Files, 0)
-	for _, filePath := range filePaths {
-		curFiles, err := proto.ParsePath(ctx, filePath)
-		if err != nil {
-			return fmt.Errorf("error parsing proto files: %v", err)
-		}
-		protoFiles = append(protoFiles, curFiles...)
+	// Parse the proto files, resolving missing imports from the registry
+	parseOpts := proto.ParseOptions{
+		ImportPaths: importPathsFor(config, cmd, protoPath),
+		Resolver:    registryImportResolver(ctx, client),
+	}
+	protoFiles, diskPaths, err := parseProtoFiles(ctx, parseOpts, filePaths)
+	if err != nil {
+		return err
 	}
 
 	// Validate package names are unique
@@ -56,31 +44,12 @@ func validateAction(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	// Upload the proto files
-	validateReq := &v1.ValidatePackageVersionRequest{}
-
-	// Group based on package name
-	packageFiles := proto.GroupByPackage(protoFiles)
-	for packageName, files := range packageFiles {
-
-		// Get file contents
-		packageFiles := make([]*v1.ProtoFile, 0)
-		for _, file := range files {
-			fileContents, err := proto.ReadStrings(ctx, file.Path())
-			if err != nil {
-				return fmt.Errorf("error reading proto files: %v", err)
-			}
-
-			packageFiles = append(packageFiles, &v1.ProtoFile{
-				FileName:     filepath.Base(file.Path()),
-				FileContents: fileContents[0].FileContents,
-			})
-		}
-
-		validateReq.Packages = append(validateReq.Packages, &v1.PackageFile{
-			PackageName: packageName,
-			Files:       packageFiles,
-		})
+	packages, err := buildPackageFiles(ctx, protoFiles, diskPaths)
+	if err != nil {
+		return err
+	}
+	validateReq := &v1.ValidatePackageVersionRequest{
+		Packages: packages,
 	}
 
 	// Validate the proto files
@@ -91,25 +60,38 @@ func validateAction(ctx context.Context, cmd *cli.Command) error {
 
 	if validateRes.IsValid {
 		fmt.Println("Schema validated successfully")
+	} else if len(validateRes.LintViolations) > 0 {
+		fmt.Println("Schema has lint violations.")
+		printLintViolations(validateRes.LintViolations)
 	} else {
 		fmt.Println("Schema is not backwards compatible.")
 		fmt.Printf("Error: %v\n", validateRes.Error)
 	}
 
+	if !validateRes.IsValid && config.Workspace != nil && config.Workspace.Compat.FailOnIncompatible {
+		return errors.New("schema validation failed")
+	}
+
 	return nil
 }
 
+func makeValidateAction(config *config.Config) func(ctx context.Context, cmd *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		return validateAction(ctx, config, cmd)
+	}
+}
+
 // Validate will validate that a proto file is backwards compatible with another
 func ValidateCommand(config *config.Config) *cli.Command {
 	return &cli.Command{
 		Name:   "validate",
 		Usage:  "Validate that a proto file is backwards compatible with any existing packages",
-		Action: validateAction,
-		Flags: []cli.Flag{
+		Action: makeValidateAction(config),
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:     protoFlag,
-				Usage:    "Path to the proto files to validate",
-				Required: true,
+				Usage:    "Path to the proto files to validate. Defaults to the workspace roots",
+				Required: false,
 			},
 			&cli.StringFlag{
 				Name:     endpointFlag,
@@ -117,6 +99,7 @@ func ValidateCommand(config *config.Config) *cli.Command {
 				Required: false,
 				Value:    config.GrpcEndpoint,
 			},
-		},
+			protoPathFlagDef(),
+		}, connectionFlags(config)...),
 	}
 }
@@ -0,0 +1,70 @@
+package frontend
+
+import (
+	"net/http"
+
+	"github.com/ggicci/httpin"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/infra/logging"
+	"github.com/cgund98/voer/internal/ui/components/auditevent"
+)
+
+// RequestMetadataMiddleware attaches the method, client address and user agent of a request to its context
+// so they can be recorded in the audit log
+func RequestMetadataMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithRequestMetadata(r.Context(), audit.RequestMetadata{
+			Method:        r.Method + " " + r.URL.Path,
+			ClientAddress: r.RemoteAddr,
+			UserAgent:     r.UserAgent(),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type ListAuditEventsInput struct {
+	Actor       string `in:"query=actor"`
+	Action      string `in:"query=action"`
+	PackageName string `in:"query=package_name"`
+}
+
+func (s *Service) HandleListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	// Parse inputs
+	input := r.Context().Value(httpin.Input).(*ListAuditEventsInput)
+
+	res, err := ctrl.ListAuditEvents(r.Context(), s.store, s.authorizer, &v1.ListAuditEventsRequest{
+		Actor:       input.Actor,
+		Action:      input.Action,
+		PackageName: input.PackageName,
+	})
+	if err != nil {
+		writeError(w, r, err, "Failed to list Audit Events")
+		return
+	}
+
+	// Format input
+	inputs := []auditevent.AuditEventTableInput{}
+	for _, event := range res.Events {
+		inputs = append(inputs, auditevent.AuditEventTableInput{
+			CreatedAt:     event.CreatedAt.AsTime(),
+			Actor:         event.Actor,
+			Action:        event.Action,
+			PackageName:   event.PackageName,
+			Version:       event.Version,
+			Detail:        event.Detail,
+			ClientAddress: event.ClientAddress,
+			Succeeded:     event.Result == audit.ResultSuccess,
+			Error:         event.Error,
+		})
+	}
+
+	// Render component
+	component := auditevent.AuditEventTable(inputs)
+	err = component.Render(r.Context(), w)
+	if err != nil {
+		logging.Logger.Error("Failed to render Audit Event Table", "error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package frontend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+// publicPaths are served without authentication
+var publicPaths = []string{"/health", "/ready", "/static/"}
+
+// AuthMiddleware rejects requests without valid credentials, except to the public paths and the extra paths given.
+// Browsers are prompted for basic auth, with an API token or JWT as the password.
+func AuthMiddleware(authenticator auth.Authenticator, extraPublicPaths ...string) func(http.Handler) http.Handler {
+	paths := append(slices.Clone(publicPaths), extraPublicPaths...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, path := range paths {
+				if r.URL.Path == path || (strings.HasSuffix(path, "/") && strings.HasPrefix(r.URL.Path, path)) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			credential, err := auth.CredentialFromHeader(r.Header.Get("Authorization"))
+			if err == nil {
+				var principal *auth.Principal
+				principal, err = authenticator.Authenticate(r.Context(), credential)
+				if err == nil {
+					next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+					return
+				}
+			}
+
+			logging.Logger.Warn("Rejected unauthenticated request", "path", r.URL.Path, "error", err)
+			w.Header().Set("WWW-Authenticate", `Basic realm="voer", charset="UTF-8"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		})
+	}
+}
+
+// authorizeRead checks the caller can read a package, writing a forbidden response otherwise
+func (s *Service) authorizeRead(w http.ResponseWriter, r *http.Request, packageName string) bool {
+	err := s.authorizer.Authorize(r.Context(), auth.RoleReader, packageName)
+	if errors.Is(err, auth.ErrPermissionDenied) || errors.Is(err, auth.ErrUnauthenticated) {
+		logging.Logger.Warn("Rejected Package read", "package", packageName, "error", err)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	if err != nil {
+		writeError(w, r, err, "Failed to authorize Package read")
+		return false
+	}
+
+	return true
+}
+
+// readablePage lists a page of items in packages the caller can read, along with the number of such items.
+// Grants are package patterns the database cannot match, so with authorization enabled every item is listed and
+// filtered before the page is cut.
+func readablePage[T any](ctx context.Context, authorizer *auth.Authorizer, limit, offset int, list func(limit, offset int) ([]T, error), count func() (int64, error), packageName func(T) string) ([]T, int64, error) {
+	offset = max(offset, 0)
+
+	if authorizer == nil {
+		items, err := list(limit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err := count()
+		return items, total, err
+	}
+
+	readable, err := authorizer.Filter(ctx, auth.RoleReader)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	all, err := list(-1, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []T
+	for _, item := range all {
+		if readable(packageName(item)) {
+			items = append(items, item)
+		}
+	}
+
+	total := int64(len(items))
+	if offset >= len(items) {
+		return nil, total, nil
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, total, nil
+}
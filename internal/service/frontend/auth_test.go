@@ -0,0 +1,100 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	"github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/config"
+)
+
+func TestReadAuthorization(t *testing.T) {
+	store := repo.NewMemoryStore()
+	_, err := ctrl.CreatePackageVersion(context.Background(), store, nil, ctrl.UploadPolicy{}, &v1.UploadPackageVersionRequest{
+		Packages: []*v1.PackageFile{{
+			PackageName: "payments.v1",
+			Files: []*v1.ProtoFile{{
+				FileName:     "payments.proto",
+				FileContents: "syntax = \"proto3\";\npackage payments.v1;\nmessage Charge { string id = 1; }\n",
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to upload package: %v", err)
+	}
+	if err := store.RoleGrants().Create(&db.RoleGrant{Subject: "alice", Role: string(auth.RoleReader), PackagePattern: "payments.*"}); err != nil {
+		t.Fatalf("Failed to grant role: %v", err)
+	}
+
+	pkg, err := store.Packages().FindByName("payments.v1")
+	if err != nil || pkg == nil || pkg.LatestVersionID == nil {
+		t.Fatalf("Failed to find package: %v", err)
+	}
+
+	fe := NewService(&config.Config{}, store, nil, &auth.Authorizer{Store: store}, nil, nil, nil)
+	fe.Init()
+	get := func(subject, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: subject, Method: auth.MethodToken}))
+		rec := httptest.NewRecorder()
+		fe.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Lists leave out packages the caller cannot read
+	for _, path := range []string{"/packages?page=1", "/messages?page=1"} {
+		if rec := get("alice", path); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "payments.v1") {
+			t.Fatalf("Expected a reader to see payments.v1 in %s, got %d", path, rec.Code)
+		}
+		if rec := get("bob", path); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "payments.v1") {
+			t.Fatalf("Expected a grantless subject to see an empty %s, got %d", path, rec.Code)
+		}
+	}
+
+	for _, path := range []string{
+		fmt.Sprintf("/view/packages/%d", pkg.ID),
+		fmt.Sprintf("/packages-versions?package_id=%d", pkg.ID),
+		fmt.Sprintf("/packages-version-files?package_version_id=%d", *pkg.LatestVersionID),
+	} {
+		if rec := get("alice", path); rec.Code != http.StatusOK {
+			t.Fatalf("Expected a reader to read %s, got %d", path, rec.Code)
+		}
+		if rec := get("bob", path); rec.Code != http.StatusForbidden {
+			t.Fatalf("Expected a grantless subject to be forbidden from %s, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestAuthMiddlewarePublicPaths(t *testing.T) {
+	authenticator := &auth.TokenAuthenticator{Store: repo.NewMemoryStore()}
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	for _, tt := range []struct {
+		name             string
+		extraPublicPaths []string
+		path             string
+		expected         int
+	}{
+		{"health", nil, "/health", http.StatusOK},
+		{"static files", nil, "/static/app.css", http.StatusOK},
+		{"metrics", nil, "/metrics", http.StatusUnauthorized},
+		{"public metrics", []string{"/metrics"}, "/metrics", http.StatusOK},
+		{"pages with public metrics", []string{"/metrics"}, "/packages", http.StatusUnauthorized},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			AuthMiddleware(authenticator, tt.extraPublicPaths...)(http.HandlerFunc(handler)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.expected {
+				t.Fatalf("Expected %d for %s, got %d", tt.expected, tt.path, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package frontend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	handler := CSRFMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, tt := range []struct {
+		name     string
+		method   string
+		headers  map[string]string
+		expected int
+	}{
+		{"reads", http.MethodGet, nil, http.StatusOK},
+		{"htmx changes", http.MethodDelete, map[string]string{"HX-Request": "true"}, http.StatusOK},
+		{"same origin changes", http.MethodPost, map[string]string{"HX-Request": "true", "Origin": "http://registry.example.com"}, http.StatusOK},
+		{"forms", http.MethodPost, nil, http.StatusForbidden},
+		{"cross origin changes", http.MethodPost, map[string]string{"HX-Request": "true", "Origin": "http://evil.example.com"}, http.StatusForbidden},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://registry.example.com/packages/1", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.expected {
+				t.Fatalf("Expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+// errorResponse chooses the status code and message of the response to a failed request. Domain errors from ctrl keep
+// their message, which tells the user what to fix. Any other error may reveal database or driver details, so the
+// message only carries the request ID under which the error is logged.
+func errorResponse(r *http.Request, err error) (int, string) {
+	var (
+		notFoundErr      *ctrl.NotFoundError
+		alreadyExistsErr *ctrl.AlreadyExistsError
+		invalidArgErr    *ctrl.InvalidArgumentError
+		preconditionErr  *ctrl.FailedPreconditionError
+		invalidProtoErr  *ctrl.InvalidProtoError
+		incompatErr      *ctrl.IncompatibleSchemaError
+	)
+
+	switch {
+	case errors.Is(err, auth.ErrPermissionDenied), errors.Is(err, auth.ErrUnauthenticated):
+		return http.StatusForbidden, err.Error()
+	case errors.As(err, &notFoundErr):
+		return http.StatusNotFound, err.Error()
+	case errors.As(err, &alreadyExistsErr), errors.As(err, &preconditionErr), errors.As(err, &incompatErr):
+		return http.StatusConflict, err.Error()
+	case errors.As(err, &invalidArgErr), errors.As(err, &invalidProtoErr):
+		return http.StatusBadRequest, err.Error()
+	default:
+		return http.StatusInternalServerError, fmt.Sprintf("Internal error, logged under request ID %s", middleware.GetReqID(r.Context()))
+	}
+}
+
+// logError logs a failed request, as a warning when the caller is at fault. Returns the status code and message of
+// its response.
+func logError(r *http.Request, err error, logMessage string) (int, string) {
+	code, message := errorResponse(r, err)
+	if code >= http.StatusInternalServerError {
+		logging.Logger.ErrorContext(r.Context(), logMessage, "error", err, "request_id", middleware.GetReqID(r.Context()))
+	} else {
+		logging.Logger.WarnContext(r.Context(), logMessage, "error", err)
+	}
+
+	return code, message
+}
+
+// writeError logs a failed request and writes the response describing its error
+func writeError(w http.ResponseWriter, r *http.Request, err error, logMessage string) {
+	code, message := logError(r, err, logMessage)
+	http.Error(w, message, code)
+}
@@ -0,0 +1,46 @@
+package frontend
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	"github.com/cgund98/voer/internal/infra/auth"
+)
+
+func TestWriteError(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		err      error
+		expected int
+		message  string
+	}{
+		{"denied", auth.ErrPermissionDenied, http.StatusForbidden, auth.ErrPermissionDenied.Error()},
+		{"not found", &ctrl.NotFoundError{Resource: ctrl.ResourcePackage, Name: "foo.v1"}, http.StatusNotFound, "foo.v1 not found"},
+		{"already exists", &ctrl.AlreadyExistsError{Resource: ctrl.ResourcePackage, Name: "foo.v1"}, http.StatusConflict, "foo.v1 already exists"},
+		{"failed precondition", &ctrl.FailedPreconditionError{Description: "version is deleted"}, http.StatusConflict, "version is deleted"},
+		{"invalid argument", &ctrl.InvalidArgumentError{Field: "new_name", Description: "new name is required"}, http.StatusBadRequest, "new name is required"},
+		{"internal", errors.New("pq: connection refused"), http.StatusInternalServerError, "request ID "},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, r, tt.err, "Failed to change Package")
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/packages/1/rename", nil))
+
+			if rec.Code != tt.expected || !strings.Contains(rec.Body.String(), tt.message) {
+				t.Fatalf("Expected %d containing %q, got %d: %s", tt.expected, tt.message, rec.Code, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), "pq:") {
+				t.Fatalf("Expected the internal cause to be hidden, got %s", rec.Body.String())
+			}
+		})
+	}
+}
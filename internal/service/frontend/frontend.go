@@ -1,6 +1,9 @@
 package frontend
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -11,27 +14,53 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-playground/validator"
 	slogchi "github.com/samber/slog-chi"
-	"gorm.io/gorm"
 
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/auth"
 	"github.com/cgund98/voer/internal/infra/config"
+	"github.com/cgund98/voer/internal/infra/events"
+	"github.com/cgund98/voer/internal/infra/health"
 	"github.com/cgund98/voer/internal/infra/logging"
+	"github.com/cgund98/voer/internal/infra/metrics"
+	"github.com/cgund98/voer/internal/service/mirror"
 	"github.com/cgund98/voer/internal/ui/page"
 )
 
 type Service struct {
 	config    *config.Config
 	router    chi.Router
+	server    *http.Server
 	validator *validator.Validate
 
-	db *gorm.DB
+	store repo.Store
+
+	// Authentication and authorization are disabled when nil
+	authenticator auth.Authenticator
+	authorizer    *auth.Authorizer
+
+	// Publishes changes to packages. Events are not published when nil.
+	bus *events.Bus
+
+	// Replicates packages from an upstream registry. Nil when the registry is not a mirror.
+	mirror *mirror.Mirror
+
+	// Reports whether the server is ready to receive requests. Always ready when nil.
+	readiness *health.Readiness
 }
 
-func NewService(config *config.Config, db *gorm.DB) *Service {
+func NewService(config *config.Config, store repo.Store, authenticator auth.Authenticator, authorizer *auth.Authorizer, bus *events.Bus, mirrorSvc *mirror.Mirror, readiness *health.Readiness) *Service {
+	router := chi.NewRouter()
 	return &Service{
-		config:    config,
-		router:    chi.NewRouter(),
-		validator: validator.New(),
-		db:        db,
+		config:        config,
+		router:        router,
+		server:        &http.Server{Handler: router},
+		validator:     validator.New(),
+		store:         store,
+		authenticator: authenticator,
+		authorizer:    authorizer,
+		bus:           bus,
+		mirror:        mirrorSvc,
+		readiness:     readiness,
 	}
 }
 
@@ -43,22 +72,52 @@ func (fe *Service) Init() {
 	httpin_integration.UseGochiURLParam("path", chi.URLParam)
 
 	// Middleware
+	fe.router.Use(middleware.RequestID)
+	fe.router.Use(TelemetryMiddleware)
 	fe.router.Use(slogchi.New(logging.Logger))
 	fe.router.Use(middleware.Recoverer)
+	fe.router.Use(RequestMetadataMiddleware)
+	fe.router.Use(CSRFMiddleware)
+	if fe.authenticator != nil {
+		var extraPublicPaths []string
+		if fe.config.MetricsPublic {
+			extraPublicPaths = append(extraPublicPaths, "/metrics")
+		}
+		fe.router.Use(AuthMiddleware(fe.authenticator, extraPublicPaths...))
+	}
+	if fe.mirror != nil {
+		fe.router.Use(ReadOnlyMiddleware(fe.mirror.Upstream()))
+	}
 
 	fe.router.Handle("/*", templ.Handler(page.NotFoundPage()))
 
 	// Routes
 	fe.router.Handle("/", templ.Handler(page.Messages()))
 	fe.router.Handle("/view/packages", templ.Handler(page.Packages()))
+	fe.router.Handle("/view/access", templ.Handler(page.Access()))
+	fe.router.Handle("/view/audit", templ.Handler(page.Audit()))
 	fe.router.With(httpin.NewInput(PackagePageInput{})).Get("/view/packages/{package_id}", http.HandlerFunc(fe.HandlePackagePage))
 
 	fe.router.With(httpin.NewInput(ListMessagesInput{})).Get("/messages", http.HandlerFunc(fe.HandleListMessages))
 	fe.router.With(httpin.NewInput(ListPackagesInput{})).Get("/packages", http.HandlerFunc(fe.HandleListPackages))
+	fe.router.With(httpin.NewInput(DeletePackageInput{})).Delete("/packages/{package_id}", http.HandlerFunc(fe.HandleDeletePackage))
+	fe.router.With(httpin.NewInput(RenamePackageInput{})).Post("/packages/{package_id}/rename", http.HandlerFunc(fe.HandleRenamePackage))
 	fe.router.With(httpin.NewInput(ListPackageVersionFilesInput{})).Get("/packages-version-files", http.HandlerFunc(fe.HandleListPackageVersionFiles))
 
 	fe.router.With(httpin.NewInput(ListPackageVersionsInput{})).Get("/packages-versions", http.HandlerFunc(fe.HandleListPackageVersions))
 	fe.router.With(httpin.NewInput(DeletePackageVersionInput{})).Delete("/packages-versions/{package_version_id}", http.HandlerFunc(fe.HandleDeletePackageVersion))
+	fe.router.With(httpin.NewInput(DeprecatePackageVersionInput{})).Post("/packages-versions/{package_version_id}/deprecation", http.HandlerFunc(fe.HandleDeprecatePackageVersion))
+	fe.router.With(httpin.NewInput(UndeprecatePackageVersionInput{})).Delete("/packages-versions/{package_version_id}/deprecation", http.HandlerFunc(fe.HandleUndeprecatePackageVersion))
+	fe.router.With(httpin.NewInput(RestorePackageVersionInput{})).Post("/packages-versions/{package_version_id}/restore", http.HandlerFunc(fe.HandleRestorePackageVersion))
+
+	fe.router.Get("/role-grants", http.HandlerFunc(fe.HandleListRoleGrants))
+	fe.router.With(httpin.NewInput(CreateRoleGrantInput{})).Post("/role-grants", http.HandlerFunc(fe.HandleCreateRoleGrant))
+	fe.router.With(httpin.NewInput(DeleteRoleGrantInput{})).Delete("/role-grants/{role_grant_id}", http.HandlerFunc(fe.HandleDeleteRoleGrant))
+
+	fe.router.With(httpin.NewInput(ListAuditEventsInput{})).Get("/audit-events", http.HandlerFunc(fe.HandleListAuditEvents))
+
+	fe.router.Get("/mirror-banner", http.HandlerFunc(fe.HandleMirrorBanner))
+	fe.router.Get("/mirror/status", http.HandlerFunc(fe.HandleMirrorStatus))
 
 	// static files
 	fe.router.Handle("/static/app.css", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -68,17 +127,54 @@ func (fe *Service) Init() {
 		}
 	}))
 
+	// Prometheus metrics
+	fe.router.Handle("/metrics", metrics.Handler())
+
 	// Health Check
 	fe.router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		if _, err := w.Write([]byte("Service is healthy.")); err != nil {
 			logging.Logger.Error("Failed to write health check", "error", err)
 		}
 	})
+
+	// Readiness check, failing once the server starts shutting down
+	fe.router.Get("/ready", func(w http.ResponseWriter, r *http.Request) {
+		if err := fe.readiness.Ready(r.Context()); err != nil {
+			http.Error(w, fmt.Sprintf("Service is not ready: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+		if _, err := w.Write([]byte("Service is ready.")); err != nil {
+			logging.Logger.Error("Failed to write readiness check", "error", err)
+		}
+	})
 }
 
-// Start will listen and serve on a given port
-func (o *Service) Start(port int) error {
+// Start will listen and serve on a given port until Shutdown is called. Serves HTTPS when tlsConfig is non-nil.
+func (o *Service) Start(port int, tlsConfig *tls.Config) error {
 	addr := fmt.Sprintf(":%d", port)
-	logging.Logger.Info("Starting frontend service...", "address", addr)
-	return http.ListenAndServe(addr, o.router)
+	logging.Logger.Info("Starting frontend service...", "address", addr, "tls", tlsConfig != nil)
+
+	o.server.Addr = addr
+	o.server.TLSConfig = tlsConfig
+
+	var err error
+	if tlsConfig == nil {
+		err = o.server.ListenAndServe()
+	} else {
+		err = o.server.ListenAndServeTLS("", "")
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to finish. Requests still running once ctx is
+// done are cancelled by closing their connections.
+func (o *Service) Shutdown(ctx context.Context) error {
+	err := o.server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errors.Join(err, o.server.Close())
+	}
+	return err
 }
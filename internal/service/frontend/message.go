@@ -28,17 +28,16 @@ func (s *Service) HandleListMessages(w http.ResponseWriter, r *http.Request) {
 	limit := pageSize
 	offset := (input.Page - 1) * limit
 
-	messages, err := db.ListMessages(s.db, limit, offset, input.Search)
+	// List and count the messages of packages the caller can read
+	messages, count, err := readablePage(r.Context(), s.authorizer, limit, offset,
+		func(limit, offset int) ([]db.Message, error) {
+			return s.store.Messages().List(limit, offset, input.Search)
+		},
+		func() (int64, error) { return s.store.Messages().Count(input.Search) },
+		func(message db.Message) string { return message.Package.PackageName },
+	)
 	if err != nil {
-		logging.Logger.Error("Failed to list messages", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	count, err := db.CountMessages(s.db, input.Search)
-	if err != nil {
-		logging.Logger.Error("Failed to count messages", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to list messages")
 		return
 	}
 
@@ -57,6 +56,10 @@ func (s *Service) HandleListMessages(w http.ResponseWriter, r *http.Request) {
 		msgInput := msgComponents.MessageCardInput{
 			Title:   message.Name,
 			Package: message.Package.PackageName,
+
+			Deprecated:             message.IsDeprecated(),
+			DeprecationReason:      message.DeprecationReason,
+			DeprecationReplacement: message.DeprecationReplacement,
 		}
 		if message.LatestVersion != nil {
 			msgInput.Version = message.LatestVersion.Version
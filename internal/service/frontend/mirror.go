@@ -0,0 +1,83 @@
+package frontend
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cgund98/voer/internal/infra/logging"
+	mirrorui "github.com/cgund98/voer/internal/ui/components/mirror"
+)
+
+// ReadOnlyMiddleware rejects requests that change packages on a mirror of an upstream registry.
+// Role grants are local to each registry and can still be managed.
+func ReadOnlyMiddleware(upstream string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || strings.HasPrefix(r.URL.Path, "/role-grants") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			http.Error(w, fmt.Sprintf("This registry is a read-only mirror of %s, make changes there instead", upstream), http.StatusForbidden)
+		})
+	}
+}
+
+// HandleMirrorBanner renders the mirror's status, or nothing when the registry is not a mirror
+func (s *Service) HandleMirrorBanner(w http.ResponseWriter, r *http.Request) {
+	if s.mirror == nil {
+		return
+	}
+
+	status := s.mirror.Status()
+	component := mirrorui.MirrorBanner(mirrorui.MirrorBannerInput{
+		Upstream:  status.Upstream,
+		Connected: status.Connected,
+		UpToDate:  status.UpToDate,
+		Lag:       status.Lag(time.Now()).Round(time.Second).String(),
+		LastError: status.LastError,
+	})
+	if err := component.Render(r.Context(), w); err != nil {
+		logging.Logger.Error("Failed to render Mirror Banner", "error", err)
+	}
+}
+
+// mirrorStatusResponse is the JSON body of the mirror status endpoint
+type mirrorStatusResponse struct {
+	Upstream   string     `json:"upstream"`
+	Connected  bool       `json:"connected"`
+	UpToDate   bool       `json:"upToDate"`
+	ChangeID   uint64     `json:"changeId"`
+	SyncedAt   *time.Time `json:"syncedAt"`
+	LagSeconds float64    `json:"lagSeconds"`
+	LastError  string     `json:"lastError,omitempty"`
+}
+
+// HandleMirrorStatus reports how far the mirror is behind its upstream registry
+func (s *Service) HandleMirrorStatus(w http.ResponseWriter, r *http.Request) {
+	if s.mirror == nil {
+		http.Error(w, "This registry is not a mirror", http.StatusNotFound)
+		return
+	}
+
+	status := s.mirror.Status()
+	res := mirrorStatusResponse{
+		Upstream:   status.Upstream,
+		Connected:  status.Connected,
+		UpToDate:   status.UpToDate,
+		ChangeID:   status.ChangeID,
+		LagSeconds: status.Lag(time.Now()).Seconds(),
+		LastError:  status.LastError,
+	}
+	if !status.SyncedAt.IsZero() {
+		res.SyncedAt = &status.SyncedAt
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		logging.Logger.Error("Failed to write mirror status", "error", err)
+	}
+}
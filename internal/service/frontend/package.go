@@ -3,10 +3,13 @@ package frontend
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/ggicci/httpin"
 	"google.golang.org/protobuf/proto"
 
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/ctrl"
 	"github.com/cgund98/voer/internal/entity/db"
 	"github.com/cgund98/voer/internal/infra/logging"
 
@@ -28,29 +31,25 @@ func (s *Service) HandleListPackages(w http.ResponseWriter, r *http.Request) {
 	limit := pageSize
 	offset := (input.Page - 1) * limit
 
-	// List Packages
-	packages, err := db.ListPackages(s.db, limit, offset, input.Search)
+	// List and count the Packages the caller can read
+	packages, count, err := readablePage(r.Context(), s.authorizer, limit, offset,
+		func(limit, offset int) ([]db.Package, error) {
+			return s.store.Packages().List(limit, offset, input.Search)
+		},
+		func() (int64, error) { return s.store.Packages().Count(input.Search) },
+		func(pkg db.Package) string { return pkg.PackageName },
+	)
 	if err != nil {
-		logging.Logger.Error("Failed to list Packages", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Count Packages
-	count, err := db.CountPackages(s.db, input.Search)
-	if err != nil {
-		logging.Logger.Error("Failed to count Packages", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to list Packages")
 		return
 	}
 
 	// Count messages for each package
 	msgCounts := make(map[uint]int)
 	for _, Package := range packages {
-		messageCount, err := db.CountMessagesByPackage(s.db, Package.ID)
+		messageCount, err := s.store.Messages().CountByPackage(Package.ID)
 		if err != nil {
-			logging.Logger.Error("Failed to count Messages", "error", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, r, err, "Failed to count Messages")
 			return
 		}
 		msgCounts[Package.ID] = int(messageCount)
@@ -76,6 +75,10 @@ func (s *Service) HandleListPackages(w http.ResponseWriter, r *http.Request) {
 			msgInput.Version = Package.LatestVersion.Version
 			msgInput.UpdatedAt = Package.LatestVersion.UpdatedAt
 			msgInput.MessageCount = msgCounts[Package.ID]
+
+			msgInput.Deprecated = Package.LatestVersion.IsDeprecated()
+			msgInput.DeprecationReason = Package.LatestVersion.DeprecationReason
+			msgInput.DeprecationReplacement = Package.LatestVersion.DeprecationReplacement
 		}
 		cardInputs[i] = msgInput
 	}
@@ -97,10 +100,16 @@ func (s *Service) HandlePackagePage(w http.ResponseWriter, r *http.Request) {
 	input := r.Context().Value(httpin.Input).(*PackagePageInput)
 
 	// Fetch Package
-	pkg, err := db.GetPackage(s.db, input.PackageID)
+	pkg, err := s.store.Packages().Get(uint(input.PackageID))
 	if err != nil {
-		logging.Logger.Error("Failed to get Package", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to get Package")
+		return
+	}
+	if pkg == nil {
+		http.Error(w, "Package not found", http.StatusNotFound)
+		return
+	}
+	if !s.authorizeRead(w, r, pkg.PackageName) {
 		return
 	}
 
@@ -116,11 +125,20 @@ func (s *Service) HandlePackagePage(w http.ResponseWriter, r *http.Request) {
 		pageInput.PackageUpdatedAt = &pkg.LatestVersion.UpdatedAt
 	}
 
+	// Fetch former names
+	aliases, err := s.store.Packages().ListAliases(pkg.ID)
+	if err != nil {
+		writeError(w, r, err, "Failed to list Package aliases")
+		return
+	}
+	for _, alias := range aliases {
+		pageInput.Aliases = append(pageInput.Aliases, alias.AliasName)
+	}
+
 	// Count messages
-	messageCount, err := db.CountMessagesByPackage(s.db, pkg.ID)
+	messageCount, err := s.store.Messages().CountByPackage(pkg.ID)
 	if err != nil {
-		logging.Logger.Error("Failed to count Messages", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to count Messages")
 		return
 	}
 	pageInput.PackageMessageCount = int(messageCount)
@@ -132,3 +150,75 @@ func (s *Service) HandlePackagePage(w http.ResponseWriter, r *http.Request) {
 		logging.Logger.Error(fmt.Sprintf("Error rendering package page: %v", err))
 	}
 }
+
+type DeletePackageInput struct {
+	PackageID uint64 `in:"path=package_id"`
+}
+
+func (s *Service) HandleDeletePackage(w http.ResponseWriter, r *http.Request) {
+	// Parse inputs
+	input := r.Context().Value(httpin.Input).(*DeletePackageInput)
+
+	pkg, ok := s.getPackage(w, input.PackageID)
+	if !ok {
+		return
+	}
+
+	_, err := ctrl.DeletePackage(r.Context(), s.store, s.bus, s.authorizer, &v1.DeletePackageRequest{PackageName: pkg.PackageName})
+	if !s.checkPackageChange(w, r, err) {
+		return
+	}
+
+	// Leave the page of the deleted package
+	w.Header().Set("HX-Redirect", "/view/packages")
+	w.WriteHeader(http.StatusOK)
+}
+
+type RenamePackageInput struct {
+	PackageID uint64 `in:"path=package_id"`
+	NewName   string `in:"header=HX-Prompt"`
+}
+
+func (s *Service) HandleRenamePackage(w http.ResponseWriter, r *http.Request) {
+	// Parse inputs
+	input := r.Context().Value(httpin.Input).(*RenamePackageInput)
+
+	pkg, ok := s.getPackage(w, input.PackageID)
+	if !ok {
+		return
+	}
+
+	_, err := ctrl.RenamePackage(r.Context(), s.store, s.authorizer, &v1.RenamePackageRequest{
+		PackageName: pkg.PackageName,
+		NewName:     strings.TrimSpace(input.NewName),
+	})
+	if !s.checkPackageChange(w, r, err) {
+		return
+	}
+
+	// Reload the page to show the new name
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusOK)
+}
+
+// getPackage fetches a package by ID, writing a not found response on failure
+func (s *Service) getPackage(w http.ResponseWriter, packageID uint64) (*db.Package, bool) {
+	pkg, err := s.store.Packages().Get(uint(packageID))
+	if err != nil || pkg == nil {
+		logging.Logger.Warn("Failed to get Package", "error", err)
+		http.Error(w, "Package not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return pkg, true
+}
+
+// checkPackageChange writes an error response for a failed change of a package. Returns true if the change succeeded.
+func (s *Service) checkPackageChange(w http.ResponseWriter, r *http.Request, err error) bool {
+	if err != nil {
+		writeError(w, r, err, "Failed to change Package")
+		return false
+	}
+
+	return true
+}
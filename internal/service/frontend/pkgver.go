@@ -3,6 +3,8 @@ package frontend
 import (
 	"net/http"
 
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/ctrl"
 	"github.com/cgund98/voer/internal/entity/db"
 	"github.com/cgund98/voer/internal/infra/logging"
 	"github.com/cgund98/voer/internal/ui/components/pkgver"
@@ -18,11 +20,15 @@ func (s *Service) HandleListPackageVersions(w http.ResponseWriter, r *http.Reque
 	// Parse inputs
 	input := r.Context().Value(httpin.Input).(*ListPackageVersionsInput)
 
+	pkg, ok := s.getPackage(w, uint64(input.PackageID))
+	if !ok || !s.authorizeRead(w, r, pkg.PackageName) {
+		return
+	}
+
 	// List package versions
-	pkgVers, err := db.ListPackageVersions(s.db, input.PackageID)
+	pkgVers, err := s.store.PackageVersions().List(input.PackageID, true)
 	if err != nil {
-		logging.Logger.Error("Failed to list Package Versions", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to list Package Versions")
 		return
 	}
 
@@ -33,6 +39,11 @@ func (s *Service) HandleListPackageVersions(w http.ResponseWriter, r *http.Reque
 			PackageVersionID: pkgVer.ID,
 			Version:          pkgVer.Version,
 			UpdatedAt:        pkgVer.UpdatedAt,
+			DeletedAt:        pkgVer.DeletedAt,
+
+			Deprecated:             pkgVer.IsDeprecated(),
+			DeprecationReason:      pkgVer.DeprecationReason,
+			DeprecationReplacement: pkgVer.DeprecationReplacement,
 		})
 	}
 
@@ -52,52 +63,99 @@ func (s *Service) HandleDeletePackageVersion(w http.ResponseWriter, r *http.Requ
 	// Parse inputs
 	input := r.Context().Value(httpin.Input).(*DeletePackageVersionInput)
 
-	// Attempt to fetch the package version
-	var pkgVer db.PackageVersion
-	err := s.db.Model(&db.PackageVersion{}).Where("id = ?", input.PackageVersionID).First(&pkgVer).Error
-	if err != nil {
-		logging.Logger.Warn("Failed to get Package Version", "error", err)
-		http.Error(w, "Package version not found", http.StatusNotFound)
-		return
-	}
-	// Create transaction
-	tx := s.db.Begin()
-	if err != nil {
-		logging.Logger.Error("Failed to create transaction", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	s.deletePackageVersion(w, r, input.PackageVersionID, false, "Package version deleted successfully")
+}
+
+type RestorePackageVersionInput struct {
+	PackageVersionID uint `in:"path=package_version_id"`
+}
+
+func (s *Service) HandleRestorePackageVersion(w http.ResponseWriter, r *http.Request) {
+	// Parse inputs
+	input := r.Context().Value(httpin.Input).(*RestorePackageVersionInput)
+
+	s.deletePackageVersion(w, r, input.PackageVersionID, true, "Package version restored successfully")
+}
+
+type DeprecatePackageVersionInput struct {
+	PackageVersionID uint   `in:"path=package_version_id"`
+	Reason           string `in:"header=HX-Prompt"`
+}
+
+func (s *Service) HandleDeprecatePackageVersion(w http.ResponseWriter, r *http.Request) {
+	// Parse inputs
+	input := r.Context().Value(httpin.Input).(*DeprecatePackageVersionInput)
+
+	s.setPackageVersionDeprecation(w, r, input.PackageVersionID, input.Reason, false)
+}
+
+type UndeprecatePackageVersionInput struct {
+	PackageVersionID uint `in:"path=package_version_id"`
+}
+
+func (s *Service) HandleUndeprecatePackageVersion(w http.ResponseWriter, r *http.Request) {
+	// Parse inputs
+	input := r.Context().Value(httpin.Input).(*UndeprecatePackageVersionInput)
+
+	s.setPackageVersionDeprecation(w, r, input.PackageVersionID, "", true)
+}
+
+// deletePackageVersion deletes or restores a package version and writes the response
+func (s *Service) deletePackageVersion(w http.ResponseWriter, r *http.Request, packageVersionID uint, restore bool, successMessage string) {
+	pkgVer, ok := s.getPackageVersion(w, packageVersionID)
+	if !ok {
 		return
 	}
 
-	// Delete package version
-	err = db.DeletePackageVersion(tx, input.PackageVersionID)
-	if err != nil {
-		logging.Logger.Error("Failed to delete Package Version", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	_, err := ctrl.DeletePackageVersion(r.Context(), s.store, s.bus, s.authorizer, &v1.DeletePackageVersionRequest{
+		PackageName: pkgVer.Package.PackageName,
+		Version:     uint64(pkgVer.Version),
+		Restore:     restore,
+	})
+	s.writePackageVersionChange(w, r, err, successMessage)
+}
+
+// setPackageVersionDeprecation deprecates a package version, or clears its deprecation, and writes the response
+func (s *Service) setPackageVersionDeprecation(w http.ResponseWriter, r *http.Request, packageVersionID uint, reason string, undeprecate bool) {
+	pkgVer, ok := s.getPackageVersion(w, packageVersionID)
+	if !ok {
 		return
 	}
 
-	// Update messages
-	err = db.AssignLatestVersion(tx, pkgVer.PackageID)
-	if err != nil {
-		logging.Logger.Error("Failed to update messages", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	_, err := ctrl.DeprecatePackageVersion(r.Context(), s.store, s.authorizer, &v1.DeprecatePackageVersionRequest{
+		PackageName: pkgVer.Package.PackageName,
+		Version:     uint64(pkgVer.Version),
+		Reason:      reason,
+		Undeprecate: undeprecate,
+	})
+	s.writePackageVersionChange(w, r, err, "Package version deprecation updated successfully")
+}
+
+// getPackageVersion fetches a package version with its package, writing a not found response on failure
+func (s *Service) getPackageVersion(w http.ResponseWriter, packageVersionID uint) (*db.PackageVersion, bool) {
+	pkgVer, err := s.store.PackageVersions().Get(packageVersionID)
+	if err != nil || pkgVer == nil {
+		logging.Logger.Warn("Failed to get Package Version", "error", err)
+		http.Error(w, "Package version not found", http.StatusNotFound)
+		return nil, false
 	}
 
-	// Commit transaction
-	err = tx.Commit().Error
+	return pkgVer, true
+}
+
+// writePackageVersionChange writes the response to a change of a package version
+func (s *Service) writePackageVersionChange(w http.ResponseWriter, r *http.Request, err error, successMessage string) {
 	if err != nil {
-		logging.Logger.Error("Failed to commit transaction", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to change Package Version")
 		return
 	}
 
 	// Set HX-Trigger header
-	w.Header().Set("HX-Trigger", "package-version-deleted")
+	w.Header().Set("HX-Trigger", "package-version-changed")
 
 	// Respond with text
 	w.WriteHeader(http.StatusOK)
-	_, err = w.Write([]byte("Package version deleted successfully"))
+	_, err = w.Write([]byte(successMessage))
 	if err != nil {
 		logging.Logger.Error("Failed to write response", "error", err)
 	}
@@ -3,8 +3,6 @@ package frontend
 import (
 	"net/http"
 
-	db "github.com/cgund98/voer/internal/entity/db"
-	"github.com/cgund98/voer/internal/infra/logging"
 	pkgverfile "github.com/cgund98/voer/internal/ui/components/pkgverfile"
 	"github.com/ggicci/httpin"
 )
@@ -17,11 +15,15 @@ func (s *Service) HandleListPackageVersionFiles(w http.ResponseWriter, r *http.R
 	// Parse inputs
 	input := r.Context().Value(httpin.Input).(*ListPackageVersionFilesInput)
 
+	pkgVer, ok := s.getPackageVersion(w, input.PackageVersionID)
+	if !ok || !s.authorizeRead(w, r, pkgVer.Package.PackageName) {
+		return
+	}
+
 	// Fetch package version files
-	packageVersionFiles, err := db.ListPackageVersionFiles(s.db, input.PackageVersionID)
+	packageVersionFiles, err := s.store.Files().List(input.PackageVersionID)
 	if err != nil {
-		logging.Logger.Error("Failed to list package version files", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to list package version files")
 		return
 	}
 
@@ -31,6 +33,7 @@ func (s *Service) HandleListPackageVersionFiles(w http.ResponseWriter, r *http.R
 		cardInputs = append(cardInputs, pkgverfile.PackageVersionFileListCardInput{
 			FileName:     file.FileName,
 			FileContents: file.FileContents,
+			Digest:       file.Digest,
 		})
 	}
 
@@ -38,8 +41,7 @@ func (s *Service) HandleListPackageVersionFiles(w http.ResponseWriter, r *http.R
 	component := pkgverfile.PackageVersionFilesList(cardInputs)
 	err = component.Render(r.Context(), w)
 	if err != nil {
-		logging.Logger.Error("Failed to render package version files", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to render package version files")
 		return
 	}
 }
@@ -0,0 +1,93 @@
+package frontend
+
+import (
+	"net/http"
+
+	"github.com/ggicci/httpin"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	"github.com/cgund98/voer/internal/infra/logging"
+	"github.com/cgund98/voer/internal/ui/components/rolegrant"
+)
+
+// renderRoleGrantTable renders the table of the role grants the caller administers, with an optional error message
+// above it
+func (s *Service) renderRoleGrantTable(w http.ResponseWriter, r *http.Request, errorMessage string) {
+	res, err := ctrl.ListRoleGrants(r.Context(), s.store, s.authorizer, &v1.ListRoleGrantsRequest{})
+	if err != nil {
+		writeError(w, r, err, "Failed to list Role Grants")
+		return
+	}
+
+	// Format input
+	inputs := []rolegrant.RoleGrantTableInput{}
+	for _, grant := range res.Grants {
+		inputs = append(inputs, rolegrant.RoleGrantTableInput{
+			RoleGrantID:    uint(grant.Id),
+			Subject:        grant.Subject,
+			Role:           grant.Role,
+			PackagePattern: grant.PackagePattern,
+			CreatedAt:      grant.CreatedAt.AsTime(),
+		})
+	}
+
+	// Render component
+	component := rolegrant.RoleGrantTable(inputs, errorMessage)
+	err = component.Render(r.Context(), w)
+	if err != nil {
+		logging.Logger.Error("Failed to render Role Grant Table", "error", err)
+	}
+}
+
+// roleGrantErrorMessage formats an error from managing grants for display
+func roleGrantErrorMessage(r *http.Request, err error) string {
+	_, message := logError(r, err, "Failed to change Role Grants")
+	return message
+}
+
+func (s *Service) HandleListRoleGrants(w http.ResponseWriter, r *http.Request) {
+	s.renderRoleGrantTable(w, r, "")
+}
+
+type CreateRoleGrantInput struct {
+	Subject        string `in:"form=subject"`
+	Role           string `in:"form=role"`
+	PackagePattern string `in:"form=package_pattern"`
+}
+
+func (s *Service) HandleCreateRoleGrant(w http.ResponseWriter, r *http.Request) {
+	// Parse inputs
+	input := r.Context().Value(httpin.Input).(*CreateRoleGrantInput)
+
+	_, err := ctrl.GrantRole(r.Context(), s.store, s.authorizer, &v1.GrantRoleRequest{
+		Subject:        input.Subject,
+		Role:           input.Role,
+		PackagePattern: input.PackagePattern,
+	})
+
+	errorMessage := ""
+	if err != nil {
+		errorMessage = roleGrantErrorMessage(r, err)
+	}
+
+	s.renderRoleGrantTable(w, r, errorMessage)
+}
+
+type DeleteRoleGrantInput struct {
+	RoleGrantID uint `in:"path=role_grant_id"`
+}
+
+func (s *Service) HandleDeleteRoleGrant(w http.ResponseWriter, r *http.Request) {
+	// Parse inputs
+	input := r.Context().Value(httpin.Input).(*DeleteRoleGrantInput)
+
+	_, err := ctrl.RevokeRole(r.Context(), s.store, s.authorizer, &v1.RevokeRoleRequest{Id: uint64(input.RoleGrantID)})
+
+	errorMessage := ""
+	if err != nil {
+		errorMessage = roleGrantErrorMessage(r, err)
+	}
+
+	s.renderRoleGrantTable(w, r, errorMessage)
+}
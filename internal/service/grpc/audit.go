@@ -0,0 +1,29 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/cgund98/voer/internal/infra/audit"
+)
+
+// RequestMetadataInterceptor attaches the method, client address and user agent of a request to its context
+// so they can be recorded in the audit log
+func RequestMetadataInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	requestMetadata := audit.RequestMetadata{Method: info.FullMethod}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		requestMetadata.ClientAddress = p.Addr.String()
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgents := md.Get("user-agent"); len(userAgents) > 0 {
+			requestMetadata.UserAgent = userAgents[0]
+		}
+	}
+
+	return handler(audit.WithRequestMetadata(ctx, requestMetadata), req)
+}
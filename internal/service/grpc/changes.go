@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/ctrl"
+)
+
+const (
+	// How often a change stream checks for new changes
+	changePollInterval = time.Second
+
+	// How often a change stream sends a heartbeat while there are no new changes
+	changeHeartbeatInterval = 10 * time.Second
+
+	// Most changes read at once
+	changeBatchSize = 100
+)
+
+// WatchChanges streams changes to packages the caller can read, until the caller disconnects.
+// Watching from the start sends a sync change for every package first.
+func (s *PackageSvc) WatchChanges(req *v1.WatchChangesRequest, stream grpc.ServerStreamingServer[v1.Change]) error {
+	ctx := stream.Context()
+	cursor := req.AfterId
+
+	if cursor == 0 {
+		changes, headID, err := ctrl.SnapshotChanges(ctx, s.Store, s.Authorizer)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+		}
+		cursor = headID
+	}
+
+	ticker := time.NewTicker(changePollInterval)
+	defer ticker.Stop()
+
+	var lastHeartbeat time.Time
+	for {
+		changes, lastID, err := ctrl.ListChanges(ctx, s.Store, s.Authorizer, cursor, changeBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+		}
+		cursor = lastID
+
+		// Tell the caller it is up to date, and how far the stream has read, when there is nothing more to send
+		if len(changes) < changeBatchSize && (len(changes) > 0 || time.Since(lastHeartbeat) >= changeHeartbeatInterval) {
+			if err := sendHeartbeat(stream, cursor); err != nil {
+				return err
+			}
+			lastHeartbeat = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendHeartbeat sends a change marking that every change up to the cursor has been sent
+func sendHeartbeat(stream grpc.ServerStreamingServer[v1.Change], cursor uint64) error {
+	return stream.Send(&v1.Change{
+		Id:        cursor,
+		CreatedAt: timestamppb.Now(),
+		Action:    ctrl.ChangeHeartbeat,
+	})
+}
+
+func (s *PackageSvc) ExportPackage(ctx context.Context, req *v1.ExportPackageRequest) (*v1.ExportPackageResponse, error) {
+	return ctrl.ExportPackage(ctx, s.Store, s.Authorizer, req)
+}
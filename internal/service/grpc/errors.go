@@ -0,0 +1,149 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/errreason"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+// errorStatus converts an error returned by a handler into a gRPC status. Domain errors from ctrl get the matching
+// code and error details describing them. Any other error is logged and hidden behind an Internal error, as it may
+// reveal database or driver details.
+func errorStatus(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var (
+		notFoundErr      *ctrl.NotFoundError
+		alreadyExistsErr *ctrl.AlreadyExistsError
+		invalidArgErr    *ctrl.InvalidArgumentError
+		preconditionErr  *ctrl.FailedPreconditionError
+		invalidProtoErr  *ctrl.InvalidProtoError
+		incompatErr      *ctrl.IncompatibleSchemaError
+	)
+
+	switch {
+	case errors.Is(err, auth.ErrPermissionDenied), errors.Is(err, auth.ErrUnauthenticated):
+		return authStatus(err)
+
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+
+	case errors.As(err, &notFoundErr):
+		return detailedStatus(codes.NotFound, err, errreason.NotFound, &errdetails.ResourceInfo{
+			ResourceType: notFoundErr.Resource,
+			ResourceName: notFoundErr.Name,
+			Description:  err.Error(),
+		})
+
+	case errors.As(err, &alreadyExistsErr):
+		return detailedStatus(codes.AlreadyExists, err, errreason.AlreadyExists, &errdetails.ResourceInfo{
+			ResourceType: alreadyExistsErr.Resource,
+			ResourceName: alreadyExistsErr.Name,
+			Description:  err.Error(),
+		})
+
+	case errors.As(err, &invalidArgErr):
+		return detailedStatus(codes.InvalidArgument, err, errreason.InvalidArgument, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       invalidArgErr.Field,
+				Description: invalidArgErr.Description,
+			}},
+		})
+
+	case errors.As(err, &preconditionErr):
+		return detailedStatus(codes.FailedPrecondition, err, preconditionErr.Reason, &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        preconditionErr.Reason,
+				Subject:     preconditionErr.Subject,
+				Description: preconditionErr.Description,
+			}},
+		})
+
+	case errors.As(err, &invalidProtoErr):
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range invalidProtoErr.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       protoViolationField(invalidProtoErr.PackageName, violation),
+				Description: violation.Description,
+				Reason:      violation.Rule,
+			})
+		}
+		return detailedStatus(codes.InvalidArgument, err, errreason.InvalidProto, badRequest)
+
+	case errors.As(err, &incompatErr):
+		failure := &errdetails.PreconditionFailure{}
+		for _, violation := range incompatErr.Violations {
+			failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+				Type:        violation.Rule,
+				Subject:     violation.MessageName,
+				Description: violation.Description,
+			})
+		}
+		return detailedStatus(codes.FailedPrecondition, err, errreason.IncompatibleSchema, failure)
+
+	default:
+		logging.Logger.ErrorContext(ctx, "Request failed with an internal error", "error", err)
+		return internalStatus(ctx)
+	}
+}
+
+// internalStatus builds the Internal status of a request that failed unexpectedly. The cause is only logged, so the
+// status carries the request ID to find it in the logs instead.
+func internalStatus(ctx context.Context) error {
+	return detailedStatus(codes.Internal, errors.New("internal error"), errreason.Internal, &errdetails.RequestInfo{
+		RequestId: requestIDFromContext(ctx),
+	})
+}
+
+// protoViolationField locates a proto violation, e.g. foo.v1/foo.proto:12
+func protoViolationField(packageName string, violation ctrl.ProtoViolation) string {
+	switch {
+	case violation.FileName == "":
+		return packageName
+	case violation.Line > 0:
+		return fmt.Sprintf("%s/%s:%d", packageName, violation.FileName, violation.Line)
+	default:
+		return fmt.Sprintf("%s/%s", packageName, violation.FileName)
+	}
+}
+
+// detailedStatus builds a status with an ErrorInfo for the reason and the given details. The status keeps the
+// error's message, so clients that ignore details still see the whole error.
+func detailedStatus(code codes.Code, err error, reason string, details ...protoadapt.MessageV1) error {
+	st := status.New(code, err.Error())
+	details = append([]protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason, Domain: errreason.Domain}}, details...)
+
+	detailed, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// ErrorStatusInterceptor converts the errors returned by handlers into gRPC statuses with error details.
+// It must be chained after interceptors that inspect status codes, e.g. for metrics and access logs.
+func ErrorStatusInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	res, err := handler(ctx, req)
+	return res, errorStatus(ctx, err)
+}
+
+// ErrorStatusStreamInterceptor converts the errors returned by stream handlers into gRPC statuses with error details
+func ErrorStatusStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return errorStatus(stream.Context(), handler(srv, stream))
+}
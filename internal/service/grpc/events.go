@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"fmt"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/events"
+)
+
+// How many events are buffered for a slow caller before they are dropped
+const eventBufferSize = 100
+
+// WatchEvents streams events published after the call starts for packages the caller can read, until the caller
+// disconnects
+func (s *PackageSvc) WatchEvents(req *v1.WatchEventsRequest, stream grpc.ServerStreamingServer[v1.Event]) error {
+	if req.PackagePattern != "" {
+		if err := auth.ValidatePackagePattern(req.PackagePattern); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	for _, eventType := range req.Types {
+		if !slices.Contains(events.Types, eventType) {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("unknown event type %q", eventType))
+		}
+	}
+	if s.Events == nil {
+		return status.Error(codes.Unavailable, "events are not published by this registry")
+	}
+
+	ctx := stream.Context()
+	subscription := s.Events.Subscribe(eventBufferSize)
+	defer subscription.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-subscription.C:
+			if len(req.Types) > 0 && !slices.Contains(req.Types, event.Type) {
+				continue
+			}
+			if req.PackagePattern != "" && !auth.MatchPackagePattern(req.PackagePattern, event.PackageName) {
+				continue
+			}
+			if err := s.Authorizer.Authorize(ctx, auth.RoleReader, event.PackageName); err != nil {
+				continue
+			}
+
+			if err := stream.Send(toEventProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toEventProto(event events.Event) *v1.Event {
+	return &v1.Event{
+		Id:          event.ID,
+		Type:        event.Type,
+		CreatedAt:   timestamppb.New(event.CreatedAt),
+		Actor:       event.Actor,
+		PackageName: event.PackageName,
+		Version:     uint64(event.Version),
+	}
+}
@@ -0,0 +1,30 @@
+package grpc
+
+import (
+	"context"
+
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/cgund98/voer/internal/infra/health"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+// HealthSvc implements the standard gRPC health check, used by gRPC readiness probes. The registry is reported as
+// serving while it is ready, for the whole server and each of its services.
+type HealthSvc struct {
+	healthgrpc.UnimplementedHealthServer
+
+	Readiness *health.Readiness
+}
+
+func NewHealthSvc(readiness *health.Readiness) *HealthSvc {
+	return &HealthSvc{Readiness: readiness}
+}
+
+func (s *HealthSvc) Check(ctx context.Context, req *healthgrpc.HealthCheckRequest) (*healthgrpc.HealthCheckResponse, error) {
+	if err := s.Readiness.Ready(ctx); err != nil {
+		logging.Logger.WarnContext(ctx, "Server is not ready", "error", err)
+		return &healthgrpc.HealthCheckResponse{Status: healthgrpc.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &healthgrpc.HealthCheckResponse{Status: healthgrpc.HealthCheckResponse_SERVING}, nil
+}
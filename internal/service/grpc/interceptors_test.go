@@ -0,0 +1,215 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/health"
+	"github.com/cgund98/voer/internal/proto"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(0.001, 2)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "token:ci"})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	interceptor := RateLimitInterceptor(limiter)
+	info := &grpc.UnaryServerInfo{FullMethod: "/voer.v1.PackageSvc/UploadPackageVersion"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := interceptor(ctx, nil, info, handler); err != nil {
+			t.Fatalf("Expected request %d within the burst to pass, got %v", i+1, err)
+		}
+	}
+	if _, err := interceptor(ctx, nil, info, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted once the burst is used, got %v", err)
+	}
+
+	// Each client has its own bucket
+	other := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "token:other"})
+	if _, err := interceptor(other, nil, info, handler); err != nil {
+		t.Fatalf("Expected another client to pass, got %v", err)
+	}
+
+	// Before authentication, every connection from an address shares its bucket
+	peerLimiter := RateLimitInterceptor(NewRateLimiter(0.001, 1))
+	fromPort := func(port int) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: port}})
+	}
+	if _, err := peerLimiter(fromPort(5000), nil, info, handler); err != nil {
+		t.Fatalf("Expected the first request from an address to pass, got %v", err)
+	}
+	if _, err := peerLimiter(fromPort(5001), nil, info, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted for a new connection from the same address, got %v", err)
+	}
+}
+
+func TestRateLimitSharedAddress(t *testing.T) {
+	peerLimiter := RateLimitInterceptor(NewRateLimiter(0.001, 3))
+	subjectLimiter := RateLimitInterceptor(NewRateLimiter(0.001, 1))
+	info := &grpc.UnaryServerInfo{FullMethod: "/voer.v1.PackageSvc/UploadPackageVersion"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	// Both subjects connect through the same NAT gateway
+	call := func(subject string) error {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+		_, err := peerLimiter(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			ctx = auth.WithPrincipal(ctx, &auth.Principal{Subject: subject})
+			return subjectLimiter(ctx, req, info, handler)
+		})
+		return err
+	}
+
+	if err := call("token:alice"); err != nil {
+		t.Fatalf("Expected alice's first request to pass, got %v", err)
+	}
+	if err := call("token:alice"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected alice to be limited once its burst is used, got %v", err)
+	}
+	if err := call("token:bob"); err != nil {
+		t.Fatalf("Expected bob not to share alice's limit, got %v", err)
+	}
+	if err := call("token:carol"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected the address to be limited once its burst is used, got %v", err)
+	}
+}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/voer.v1.PackageSvc/GetPackageVersion"}
+	_, err := RecoveryInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("Expected Internal, got %v", err)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	incoming := func(id string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDHeader, id))
+	}
+
+	if id := requestID(incoming("build-1234")); id != "build-1234" {
+		t.Fatalf("Expected the caller's request ID to be kept, got %q", id)
+	}
+	for _, invalid := range []string{"has space", "new\nline", strings.Repeat("a", maxRequestIDLength+1)} {
+		if id := requestID(incoming(invalid)); id == invalid || len(id) != 32 {
+			t.Fatalf("Expected %q to be replaced by a generated ID, got %q", invalid, id)
+		}
+	}
+}
+
+func TestErrorStatusInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/voer.v1.PackageSvc/UploadPackageVersion"}
+	statusOf := func(handlerErr error) *status.Status {
+		_, err := ErrorStatusInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		})
+		return status.Convert(err)
+	}
+
+	incompatible := statusOf(&ctrl.IncompatibleSchemaError{PackageName: "shop", Violations: []ctrl.SchemaViolation{
+		{MessageName: "Order", Rule: proto.RuleFieldRemoved, Description: "field 'id' was removed"},
+	}})
+	if incompatible.Code() != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition, got %v", incompatible.Code())
+	}
+	var failure *errdetails.PreconditionFailure
+	for _, detail := range incompatible.Details() {
+		if d, ok := detail.(*errdetails.PreconditionFailure); ok {
+			failure = d
+		}
+	}
+	if failure == nil || len(failure.Violations) != 1 || failure.Violations[0].Type != proto.RuleFieldRemoved {
+		t.Fatalf("Expected the violation in the details, got %v", incompatible.Details())
+	}
+
+	for _, tt := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{&ctrl.NotFoundError{Resource: ctrl.ResourcePackage, Name: "shop"}, codes.NotFound},
+		{&ctrl.InvalidProtoError{PackageName: "shop"}, codes.InvalidArgument},
+		{fmt.Errorf("%w: missing credentials", auth.ErrUnauthenticated), codes.Unauthenticated},
+		{status.Error(codes.ResourceExhausted, "too many requests"), codes.ResourceExhausted},
+		{errors.New("database is locked"), codes.Internal},
+	} {
+		if code := statusOf(tt.err).Code(); code != tt.code {
+			t.Errorf("Expected %v for %q, got %v", tt.code, tt.err, code)
+		}
+	}
+
+	// Internal errors are only logged, and point to the logs with the request ID
+	ctx := withRequestID(context.Background(), "build-1234")
+	_, err := ErrorStatusInterceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.New(`pq: relation "packages" does not exist`)
+	})
+	internal := status.Convert(err)
+	if internal.Code() != codes.Internal || internal.Message() != "internal error" {
+		t.Fatalf("Expected a generic Internal error, got %v", internal)
+	}
+	var requestInfo *errdetails.RequestInfo
+	for _, detail := range internal.Details() {
+		if d, ok := detail.(*errdetails.RequestInfo); ok {
+			requestInfo = d
+		}
+	}
+	if requestInfo == nil || requestInfo.RequestId != "build-1234" {
+		t.Fatalf("Expected the request ID in the details, got %v", internal.Details())
+	}
+}
+
+// testServerStream is a server stream that only has a context
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestDrainStreamInterceptor(t *testing.T) {
+	drainCtx, drain := context.WithCancel(context.Background())
+	interceptor := DrainStreamInterceptor(drainCtx)
+	info := &grpc.StreamServerInfo{FullMethod: "/voer.v1.PackageSvc/WatchChanges"}
+	stream := &testServerStream{ctx: context.Background()}
+
+	drain()
+	err := interceptor(nil, stream, info, func(srv interface{}, stream grpc.ServerStream) error {
+		<-stream.Context().Done()
+		return nil
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("Expected a drained stream to fail with Unavailable, got %v", err)
+	}
+}
+
+func TestHealthSvc(t *testing.T) {
+	readiness := health.NewReadiness(func(ctx context.Context) error { return nil })
+	healthSvc := NewHealthSvc(readiness)
+
+	res, err := healthSvc.Check(context.Background(), &healthgrpc.HealthCheckRequest{})
+	if err != nil || res.Status != healthgrpc.HealthCheckResponse_SERVING {
+		t.Fatalf("Expected SERVING, got %v (%v)", res, err)
+	}
+
+	readiness.ShutDown()
+	res, err = healthSvc.Check(context.Background(), &healthgrpc.HealthCheckRequest{})
+	if err != nil || res.Status != healthgrpc.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Expected NOT_SERVING once shutting down, got %v (%v)", res, err)
+	}
+}
@@ -2,14 +2,131 @@ package grpc
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
 	"time"
+	"unicode"
 
-	"github.com/cgund98/voer/internal/infra/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/cgund98/voer/internal/infra/logging"
 )
 
-func LoggerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	logging.Logger.Info("Received request", "grpc.method", info.FullMethod, "request.time", time.Now().Format(time.RFC3339))
+// RequestIDHeader carries the ID of a request. Callers may set it to correlate their own logs with the server's,
+// and it is returned in the response headers.
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLength bounds the length of request IDs accepted from callers
+const maxRequestIDLength = 128
+
+// contextStream is a server stream whose context was replaced by an interceptor
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestID returns the request ID sent by the caller when it is valid, and a new random ID otherwise
+func requestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(RequestIDHeader); len(ids) > 0 && validRequestID(ids[0]) {
+		return ids[0]
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+type requestIDKey struct{}
+
+// withRequestID adds a request ID to the context, log records and trace span of a request
+func withRequestID(ctx context.Context, id string) context.Context {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", id))
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return logging.WithAttrs(ctx, slog.String("request_id", id))
+}
+
+// requestIDFromContext returns the ID assigned to the current request, if any
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDInterceptor assigns each request an ID, which is added to its logs and returned in the response headers
+func RequestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	id := requestID(ctx)
+	if err := grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id)); err != nil {
+		logging.Logger.WarnContext(ctx, "Failed to set request ID header", "error", err)
+	}
+
+	return handler(withRequestID(ctx, id), req)
+}
+
+// RequestIDStreamInterceptor assigns each stream an ID, which is added to its logs and returned in the response headers
+func RequestIDStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := requestID(stream.Context())
+	if err := stream.SetHeader(metadata.Pairs(RequestIDHeader, id)); err != nil {
+		logging.Logger.WarnContext(stream.Context(), "Failed to set request ID header", "error", err)
+	}
+
+	return handler(srv, &contextStream{ServerStream: stream, ctx: withRequestID(stream.Context(), id)})
+}
+
+// AccessLogInterceptor logs each request once it is handled, with its status code, latency and caller
+func AccessLogInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+	logAccess(ctx, "Handled request", info.FullMethod, start, err)
+	return res, err
+}
+
+// AccessLogStreamInterceptor logs each stream once it ends, with its status code, duration and caller
+func AccessLogStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, stream)
+	logAccess(stream.Context(), "Handled stream", info.FullMethod, start, err)
+	return err
+}
+
+// logAccess logs a handled request. Server errors are logged as errors, and other failures as warnings.
+func logAccess(ctx context.Context, msg, method string, start time.Time, err error) {
+	code := status.Code(err)
+	args := []any{"grpc.method", method, "grpc.code", code.String(), "duration", time.Since(start).String()}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		args = append(args, "peer", p.Addr.String())
+	}
 
-	return handler(ctx, req)
+	switch code {
+	case codes.OK:
+		logging.Logger.InfoContext(ctx, msg, args...)
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		logging.Logger.ErrorContext(ctx, msg, append(args, "error", err)...)
+	default:
+		logging.Logger.WarnContext(ctx, msg, append(args, "error", err)...)
+	}
 }
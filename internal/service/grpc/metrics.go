@@ -0,0 +1,32 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/cgund98/voer/internal/infra/metrics"
+)
+
+// MetricsInterceptor counts and times requests by method and status code
+func MetricsInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+	observeRequest(info.FullMethod, start, err)
+	return res, err
+}
+
+// MetricsStreamInterceptor counts and times streams by method and status code. Streams are timed until they end.
+func MetricsStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, stream)
+	observeRequest(info.FullMethod, start, err)
+	return err
+}
+
+func observeRequest(method string, start time.Time, err error) {
+	metrics.GRPCRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	metrics.GRPCRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
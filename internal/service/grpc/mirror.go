@@ -0,0 +1,34 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/cgund98/voer/api/v1"
+)
+
+// mirroredMethods change packages, which a mirror only receives from its upstream registry
+var mirroredMethods = map[string]bool{
+	v1.PackageSvc_UploadPackageVersion_FullMethodName:    true,
+	v1.PackageSvc_DeprecatePackageVersion_FullMethodName: true,
+	v1.PackageSvc_DeprecateMessage_FullMethodName:        true,
+	v1.PackageSvc_DeletePackageVersion_FullMethodName:    true,
+	v1.PackageSvc_DeletePackage_FullMethodName:           true,
+	v1.PackageSvc_RenamePackage_FullMethodName:           true,
+}
+
+// ReadOnlyInterceptor rejects requests that change packages on a mirror of an upstream registry.
+// Role grants are local to each registry and can still be managed.
+func ReadOnlyInterceptor(upstream string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if mirroredMethods[info.FullMethod] {
+			return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("this registry is a read-only mirror of %s, make changes there instead", upstream))
+		}
+
+		return handler(ctx, req)
+	}
+}
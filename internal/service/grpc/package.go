@@ -2,30 +2,168 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	v1 "github.com/cgund98/voer/api/v1"
 	"github.com/cgund98/voer/internal/entity/ctrl"
-	"gorm.io/gorm"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/audit"
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/events"
 )
 
 type PackageSvc struct {
 	v1.UnimplementedPackageSvcServer
 
-	DB *gorm.DB
+	Store repo.Store
+
+	// Rules enforced on uploads and validations
+	Policy ctrl.UploadPolicy
+
+	// Checks the caller's roles. Authorization is disabled when nil.
+	Authorizer *auth.Authorizer
+
+	// Publishes changes to packages. Events are not published when nil.
+	Events *events.Bus
+}
+
+func NewPackageSvc(store repo.Store, policy ctrl.UploadPolicy, authorizer *auth.Authorizer, bus *events.Bus) *PackageSvc {
+	return &PackageSvc{Store: store, Policy: policy, Authorizer: authorizer, Events: bus}
 }
 
-func NewPackageSvc(db *gorm.DB) *PackageSvc {
-	return &PackageSvc{DB: db}
+// authorizePackages checks the caller holds a role on every package in a request
+func (s *PackageSvc) authorizePackages(ctx context.Context, role auth.Role, packages []*v1.PackageFile) error {
+	for _, pkg := range packages {
+		if err := s.Authorizer.Authorize(ctx, role, pkg.PackageName); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *PackageSvc) UploadPackageVersion(ctx context.Context, req *v1.UploadPackageVersionRequest) (*v1.UploadPackageVersionResponse, error) {
-	return ctrl.CreatePackageVersion(ctx, s.DB, req)
+	res, err := s.uploadPackageVersion(ctx, req)
+	if err != nil && !req.DryRun {
+		s.recordFailedUpload(ctx, req, err)
+	}
+	return res, err
+}
+
+func (s *PackageSvc) uploadPackageVersion(ctx context.Context, req *v1.UploadPackageVersionRequest) (*v1.UploadPackageVersionResponse, error) {
+	if err := s.authorizePackages(ctx, auth.RolePublisher, req.Packages); err != nil {
+		return nil, err
+	}
+	return ctrl.CreatePackageVersion(ctx, s.Store, s.Events, s.Policy, req)
+}
+
+// recordFailedUpload records a failed audit event for each package in an upload.
+// Successful uploads are recorded by the transaction creating the new versions.
+func (s *PackageSvc) recordFailedUpload(ctx context.Context, req *v1.UploadPackageVersionRequest, err error) {
+	for _, reqPkg := range req.Packages {
+		audit.Record(ctx, s.Store.AuditEvents(), audit.Event{Action: audit.ActionUpload, PackageName: reqPkg.PackageName}, err)
+	}
 }
 
 func (s *PackageSvc) ValidatePackageVersion(ctx context.Context, req *v1.ValidatePackageVersionRequest) (*v1.ValidatePackageVersionResponse, error) {
-	return ctrl.ValidatePackageVersion(ctx, s.DB, req)
+	if err := s.authorizePackages(ctx, auth.RoleReader, req.Packages); err != nil {
+		return nil, err
+	}
+	return ctrl.ValidatePackageVersion(ctx, s.Store, s.Policy, req)
 }
 
 func (s *PackageSvc) GetPackageVersion(ctx context.Context, req *v1.GetPackageVersionRequest) (*v1.GetPackageVersionResponse, error) {
-	return ctrl.GetPackageVersion(ctx, s.DB, req)
+	if err := s.Authorizer.Authorize(ctx, auth.RoleReader, req.PackageName); err != nil {
+		return nil, err
+	}
+
+	// Authorize against the current name of renamed packages as well
+	packageName, err := ctrl.ResolvePackageName(s.Store, req.PackageName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Authorizer.Authorize(ctx, auth.RoleReader, packageName); err != nil {
+		return nil, hideDenied(err, ctrl.ResourcePackage, req.PackageName)
+	}
+	return ctrl.GetPackageVersion(ctx, s.Store, req)
+}
+
+func (s *PackageSvc) ResolveImport(ctx context.Context, req *v1.ResolveImportRequest) (*v1.ResolveImportResponse, error) {
+	// The package is only known once the import is resolved, so callers who cannot read any package are rejected first
+	if err := s.Authorizer.AuthorizeAny(ctx, auth.RoleReader); err != nil {
+		return nil, err
+	}
+
+	res, err := ctrl.ResolveImport(ctx, s.Store, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Authorizer.Authorize(ctx, auth.RoleReader, res.PackageName); err != nil {
+		return nil, hideDenied(err, ctrl.ResourceImport, req.ImportPath)
+	}
+	return res, nil
+}
+
+// hideDenied reports a resource the caller may not read as not found, so callers cannot tell whether it exists
+func hideDenied(err error, resource, name string) error {
+	if errors.Is(err, auth.ErrPermissionDenied) {
+		return &ctrl.NotFoundError{Resource: resource, Name: name}
+	}
+	return err
+}
+
+func (s *PackageSvc) ListPackageVersions(ctx context.Context, req *v1.ListPackageVersionsRequest) (*v1.ListPackageVersionsResponse, error) {
+	if err := s.Authorizer.Authorize(ctx, auth.RoleReader, req.PackageName); err != nil {
+		return nil, err
+	}
+	return ctrl.ListPackageVersions(ctx, s.Store, req)
+}
+
+// WhoAmI returns the caller identified by the request's credentials
+func (s *PackageSvc) WhoAmI(ctx context.Context, req *v1.WhoAmIRequest) (*v1.WhoAmIResponse, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return &v1.WhoAmIResponse{}, nil
+	}
+
+	return &v1.WhoAmIResponse{
+		Subject: principal.Subject,
+		Method:  principal.Method,
+	}, nil
+}
+
+func (s *PackageSvc) GrantRole(ctx context.Context, req *v1.GrantRoleRequest) (*v1.GrantRoleResponse, error) {
+	return ctrl.GrantRole(ctx, s.Store, s.Authorizer, req)
+}
+
+func (s *PackageSvc) RevokeRole(ctx context.Context, req *v1.RevokeRoleRequest) (*v1.RevokeRoleResponse, error) {
+	return ctrl.RevokeRole(ctx, s.Store, s.Authorizer, req)
+}
+
+func (s *PackageSvc) ListRoleGrants(ctx context.Context, req *v1.ListRoleGrantsRequest) (*v1.ListRoleGrantsResponse, error) {
+	return ctrl.ListRoleGrants(ctx, s.Store, s.Authorizer, req)
+}
+
+func (s *PackageSvc) ListAuditEvents(ctx context.Context, req *v1.ListAuditEventsRequest) (*v1.ListAuditEventsResponse, error) {
+	return ctrl.ListAuditEvents(ctx, s.Store, s.Authorizer, req)
+}
+
+func (s *PackageSvc) DeprecatePackageVersion(ctx context.Context, req *v1.DeprecatePackageVersionRequest) (*v1.DeprecatePackageVersionResponse, error) {
+	return ctrl.DeprecatePackageVersion(ctx, s.Store, s.Authorizer, req)
+}
+
+func (s *PackageSvc) DeprecateMessage(ctx context.Context, req *v1.DeprecateMessageRequest) (*v1.DeprecateMessageResponse, error) {
+	return ctrl.DeprecateMessage(ctx, s.Store, s.Authorizer, req)
+}
+
+func (s *PackageSvc) DeletePackageVersion(ctx context.Context, req *v1.DeletePackageVersionRequest) (*v1.DeletePackageVersionResponse, error) {
+	return ctrl.DeletePackageVersion(ctx, s.Store, s.Events, s.Authorizer, req)
+}
+
+func (s *PackageSvc) DeletePackage(ctx context.Context, req *v1.DeletePackageRequest) (*v1.DeletePackageResponse, error) {
+	return ctrl.DeletePackage(ctx, s.Store, s.Events, s.Authorizer, req)
+}
+
+func (s *PackageSvc) RenamePackage(ctx context.Context, req *v1.RenamePackageRequest) (*v1.RenamePackageResponse, error) {
+	return ctrl.RenamePackage(ctx, s.Store, s.Authorizer, req)
 }
@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	"github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/auth"
+)
+
+func TestReadsHideUnreadablePackages(t *testing.T) {
+	ctx := context.Background()
+	store := repo.NewMemoryStore()
+	_, err := ctrl.CreatePackageVersion(ctx, store, nil, ctrl.UploadPolicy{}, &v1.UploadPackageVersionRequest{
+		Packages: []*v1.PackageFile{{
+			PackageName: "legacy.payments",
+			Files: []*v1.ProtoFile{{
+				FileName:     "payments.proto",
+				ImportPath:   "legacy/payments.proto",
+				FileContents: "syntax = \"proto3\";\npackage legacy.payments;\nmessage Charge { string id = 1; }\n",
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to upload package: %v", err)
+	}
+	if _, err := ctrl.RenamePackage(ctx, store, nil, &v1.RenamePackageRequest{PackageName: "legacy.payments", NewName: "payments.v1"}); err != nil {
+		t.Fatalf("Failed to rename package: %v", err)
+	}
+	if err := store.RoleGrants().Create(&db.RoleGrant{Subject: "alice", Role: string(auth.RoleReader), PackagePattern: "legacy.*"}); err != nil {
+		t.Fatalf("Failed to grant role: %v", err)
+	}
+
+	svc := NewPackageSvc(store, ctrl.UploadPolicy{}, &auth.Authorizer{Store: store}, nil)
+	alice := auth.WithPrincipal(ctx, &auth.Principal{Subject: "alice", Method: auth.MethodToken})
+	bob := auth.WithPrincipal(ctx, &auth.Principal{Subject: "bob", Method: auth.MethodToken})
+
+	// Packages the caller cannot read look the same whether they exist or not
+	for _, name := range []string{"legacy.payments", "legacy.missing"} {
+		var notFoundErr *ctrl.NotFoundError
+		if _, err := svc.GetPackageVersion(alice, &v1.GetPackageVersionRequest{PackageName: name, Version: 1}); !errors.As(err, &notFoundErr) {
+			t.Fatalf("Expected %s to be not found, got %v", name, err)
+		}
+	}
+	for _, importPath := range []string{"legacy/payments.proto", "legacy/missing.proto"} {
+		var notFoundErr *ctrl.NotFoundError
+		if _, err := svc.ResolveImport(alice, &v1.ResolveImportRequest{ImportPath: importPath}); !errors.As(err, &notFoundErr) {
+			t.Fatalf("Expected %s to be not found, got %v", importPath, err)
+		}
+		if _, err := svc.ResolveImport(bob, &v1.ResolveImportRequest{ImportPath: importPath}); !errors.Is(err, auth.ErrPermissionDenied) {
+			t.Fatalf("Expected a grantless subject to be denied %s, got %v", importPath, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+// idleClientTTL is how long a client's rate limit is remembered after its last request
+const idleClientTTL = 10 * time.Minute
+
+// RateLimiter limits the rate of requests of each client with a token bucket
+type RateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	clients   map[string]*rateLimitedClient
+	lastSweep time.Time
+}
+
+type rateLimitedClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter allows each client requestsPerSecond requests per second, with bursts of up to burst requests
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		limit:     rate.Limit(requestsPerSecond),
+		burst:     burst,
+		clients:   make(map[string]*rateLimitedClient),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow reports whether a client may make a request now, using up one of its tokens if so
+func (l *RateLimiter) Allow(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	c, ok := l.clients[client]
+	if !ok {
+		c = &rateLimitedClient{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[client] = c
+	}
+	c.lastSeen = now
+
+	return c.limiter.AllowN(now, 1)
+}
+
+// sweep forgets clients that have been idle long enough for their bucket to refill
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleClientTTL {
+		return
+	}
+	l.lastSweep = now
+
+	for client, c := range l.clients {
+		if now.Sub(c.lastSeen) > idleClientTTL {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// rateLimitKey identifies the client making a request: its subject when authenticated, and its IP address otherwise
+func rateLimitKey(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return "subject:" + principal.Subject
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:" + host
+}
+
+// checkRateLimit rejects a request with ResourceExhausted once its client runs out of tokens
+func checkRateLimit(ctx context.Context, limiter *RateLimiter, method string) error {
+	client := rateLimitKey(ctx)
+	if limiter.Allow(client) {
+		return nil
+	}
+
+	logging.Logger.WarnContext(ctx, "Rate limited request", "grpc.method", method, "client", client)
+	return status.Error(codes.ResourceExhausted, "too many requests, slow down and retry later")
+}
+
+// RateLimitInterceptor rejects requests from clients exceeding their rate limit. Clients are limited by subject when
+// it runs after authentication, and by IP address when it runs before, which also limits failed authentication attempts.
+func RateLimitInterceptor(limiter *RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkRateLimit(ctx, limiter, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor rejects streams from clients exceeding their rate limit. Opening a stream counts as
+// a single request, however many messages it carries.
+func RateLimitStreamInterceptor(limiter *RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkRateLimit(stream.Context(), limiter, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, stream)
+	}
+}
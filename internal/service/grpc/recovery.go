@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+// recovered converts a panic into an Internal status, logging the panic and its stack trace
+func recovered(ctx context.Context, method string, value any) error {
+	logging.Logger.ErrorContext(ctx, "Recovered from panic", "grpc.method", method, "panic", value, "stack", string(debug.Stack()))
+	return internalStatus(ctx)
+}
+
+// RecoveryInterceptor keeps a panicking handler from crashing the server, failing the request with Internal instead
+func RecoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			res, err = nil, recovered(ctx, info.FullMethod, value)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// RecoveryStreamInterceptor keeps a panicking stream handler from crashing the server, failing the stream with
+// Internal instead
+func RecoveryStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = recovered(stream.Context(), info.FullMethod, value)
+		}
+	}()
+
+	return handler(srv, stream)
+}
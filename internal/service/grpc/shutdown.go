@@ -0,0 +1,26 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DrainStreamInterceptor ends streams once ctx is done, so long-lived streams such as WatchChanges do not hold up a
+// graceful shutdown. Streams ended this way fail with Unavailable, telling clients to reconnect.
+func DrainStreamInterceptor(ctx context.Context) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		streamCtx, cancel := context.WithCancel(stream.Context())
+		defer cancel()
+		stop := context.AfterFunc(ctx, cancel)
+		defer stop()
+
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: streamCtx})
+		if ctx.Err() != nil && stream.Context().Err() == nil {
+			return status.Error(codes.Unavailable, "server is shutting down, reconnect to continue")
+		}
+		return err
+	}
+}
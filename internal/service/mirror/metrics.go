@@ -0,0 +1,36 @@
+package mirror
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterMetrics registers gauges of the mirror's connection, progress and lag, read from its status on each scrape
+func (m *Mirror) RegisterMetrics(registerer prometheus.Registerer) {
+	gauge := func(name, help string, value func(status Status) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   "voer",
+			Name:        name,
+			Help:        help,
+			ConstLabels: prometheus.Labels{"upstream": m.upstream},
+		}, func() float64 {
+			return value(m.Status())
+		})
+	}
+
+	registerer.MustRegister(
+		gauge("mirror_connected", "Whether the mirror is following its upstream registry's changes.", func(status Status) float64 {
+			if status.Connected {
+				return 1
+			}
+			return 0
+		}),
+		gauge("mirror_lag_seconds", "How far the mirror is behind its upstream registry.", func(status Status) float64 {
+			return status.Lag(time.Now()).Seconds()
+		}),
+		gauge("mirror_change_id", "ID of the last upstream change replicated.", func(status Status) float64 {
+			return float64(status.ChangeID)
+		}),
+	)
+}
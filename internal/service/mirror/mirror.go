@@ -0,0 +1,284 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+const (
+	// Delays before reconnecting to the upstream registry, doubling after each failed attempt
+	minRetryDelay = time.Second
+	maxRetryDelay = 30 * time.Second
+)
+
+// Status describes how far a mirror has caught up with its upstream registry
+type Status struct {
+	Upstream string
+
+	// Whether the mirror is currently following the upstream registry's changes
+	Connected bool
+
+	// Whether every change sent by the upstream registry so far has been replicated
+	UpToDate bool
+
+	// ID of the last upstream change replicated
+	ChangeID uint64
+
+	// When the mirror was last up to date. Zero when it has never been.
+	SyncedAt time.Time
+
+	// Error that interrupted the last connection, if any
+	LastError string
+
+	// When the mirror started following the upstream registry
+	StartedAt time.Time
+}
+
+// Lag returns how far the mirror is behind its upstream registry, counted from when it was last up to date
+func (s Status) Lag(now time.Time) time.Duration {
+	switch {
+	case s.Connected && s.UpToDate:
+		return 0
+	case s.SyncedAt.IsZero():
+		return now.Sub(s.StartedAt)
+	default:
+		return now.Sub(s.SyncedAt)
+	}
+}
+
+// Mirror replicates the packages of an upstream registry into the local store by following its changes
+type Mirror struct {
+	upstream string
+	client   v1.PackageSvcClient
+	store    repo.Store
+
+	mu     sync.Mutex
+	status Status
+
+	// Packages synced since watching from the start, or nil when resuming from a change
+	synced map[string]bool
+}
+
+func NewMirror(upstream string, client v1.PackageSvcClient, store repo.Store) *Mirror {
+	return &Mirror{
+		upstream: upstream,
+		client:   client,
+		store:    store,
+		status:   Status{Upstream: upstream, StartedAt: time.Now()},
+	}
+}
+
+// Upstream returns the endpoint of the upstream registry
+func (m *Mirror) Upstream() string {
+	return m.upstream
+}
+
+// Status returns a copy of the mirror's current status
+func (m *Mirror) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+func (m *Mirror) updateStatus(update func(status *Status)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	update(&m.status)
+}
+
+// Run follows the upstream registry's changes until ctx is cancelled, reconnecting whenever the stream breaks
+func (m *Mirror) Run(ctx context.Context) error {
+	logging.Logger.Info("Starting mirror...", "upstream", m.upstream)
+
+	cursor, err := m.store.MirrorCursors().Get(m.upstream)
+	if err != nil {
+		return fmt.Errorf("failed to load mirror cursor: %v", err)
+	}
+	if cursor != nil {
+		m.updateStatus(func(status *Status) {
+			status.ChangeID = uint64(cursor.ChangeID)
+			status.SyncedAt = cursor.UpdatedAt
+		})
+	}
+
+	delay := minRetryDelay
+	for {
+		progressed, err := m.follow(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		m.updateStatus(func(status *Status) {
+			status.Connected = false
+			status.UpToDate = false
+			status.LastError = err.Error()
+		})
+
+		if progressed {
+			delay = minRetryDelay
+		}
+		logging.Logger.Warn("Lost connection to upstream registry", "upstream", m.upstream, "error", err, "retry_in", delay.String(), "lag", m.Status().Lag(time.Now()).String())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// follow replicates changes from a single stream until it breaks. Reports whether any change was received.
+func (m *Mirror) follow(ctx context.Context) (bool, error) {
+	afterID := m.Status().ChangeID
+
+	// Watching from the start syncs every package, after which local packages missing upstream are removed
+	m.synced = nil
+	if afterID == 0 {
+		m.synced = make(map[string]bool)
+	}
+
+	stream, err := m.client.WatchChanges(ctx, &v1.WatchChangesRequest{AfterId: afterID})
+	if err != nil {
+		return false, err
+	}
+
+	progressed := false
+	for {
+		change, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return progressed, errors.New("stream closed by upstream registry")
+		} else if err != nil {
+			return progressed, err
+		}
+
+		if !progressed {
+			progressed = true
+			logging.Logger.Info("Connected to upstream registry", "upstream", m.upstream, "after_change", afterID)
+			m.updateStatus(func(status *Status) {
+				status.Connected = true
+				status.LastError = ""
+			})
+		}
+
+		if err := m.apply(ctx, change); err != nil {
+			return progressed, err
+		}
+	}
+}
+
+// apply replicates a single change and records how far the mirror has caught up
+func (m *Mirror) apply(ctx context.Context, change *v1.Change) error {
+	if change.Action == ctrl.ChangeHeartbeat {
+		if m.synced != nil {
+			if err := m.removeUnsynced(); err != nil {
+				return err
+			}
+			m.synced = nil
+		}
+		if err := m.saveCursor(change.Id); err != nil {
+			return err
+		}
+
+		wasUpToDate := m.Status().UpToDate
+		m.updateStatus(func(status *Status) {
+			status.UpToDate = true
+			status.SyncedAt = time.Now()
+		})
+		if !wasUpToDate {
+			logging.Logger.Info("Mirror is up to date", "upstream", m.upstream, "change", change.Id)
+		}
+		return nil
+	}
+
+	m.updateStatus(func(status *Status) {
+		status.UpToDate = false
+	})
+
+	if change.PackageName != "" {
+		if err := m.replicate(ctx, change); err != nil {
+			return fmt.Errorf("failed to replicate %s: %v", change.PackageName, err)
+		}
+	}
+
+	// Sync changes sent when watching from the start have no ID
+	if change.Id > 0 {
+		return m.saveCursor(change.Id)
+	}
+	return nil
+}
+
+// replicate copies the current state of a changed package from the upstream registry
+func (m *Mirror) replicate(ctx context.Context, change *v1.Change) error {
+	lag := time.Since(change.CreatedAt.AsTime()).String()
+
+	res, err := m.client.ExportPackage(ctx, &v1.ExportPackageRequest{PackageName: change.PackageName})
+	if status.Code(err) == codes.NotFound {
+		if err := ctrl.RemoveReplicatedPackage(ctx, m.store, change.PackageName); err != nil {
+			return err
+		}
+		logging.Logger.Info("Removed mirrored package", "package", change.PackageName, "action", change.Action, "lag", lag)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	stats, err := ctrl.ReplicatePackages(ctx, m.store, bytes.NewReader(res.Archive))
+	if err != nil {
+		return err
+	}
+	if m.synced != nil {
+		m.synced[res.PackageName] = true
+	}
+
+	logging.Logger.Info("Replicated package", "package", res.PackageName, "action", change.Action, "versions", stats.Versions, "lag", lag)
+	return nil
+}
+
+// removeUnsynced removes local packages that were not synced when watching from the start
+func (m *Mirror) removeUnsynced() error {
+	packages, err := m.store.Packages().List(-1, 0, "")
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range packages {
+		if m.synced[pkg.PackageName] {
+			continue
+		}
+		if err := m.store.Packages().Delete(pkg.ID); err != nil {
+			return err
+		}
+		logging.Logger.Info("Removed package missing from upstream registry", "package", pkg.PackageName)
+	}
+	return nil
+}
+
+// saveCursor persists the ID of the last change replicated, so the mirror resumes from it after a restart
+func (m *Mirror) saveCursor(changeID uint64) error {
+	if changeID <= m.Status().ChangeID {
+		return nil
+	}
+
+	if err := m.store.MirrorCursors().Set(m.upstream, uint(changeID)); err != nil {
+		return fmt.Errorf("failed to save mirror cursor: %v", err)
+	}
+
+	m.updateStatus(func(status *Status) {
+		status.ChangeID = changeID
+	})
+	return nil
+}
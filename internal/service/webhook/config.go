@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cgund98/voer/internal/infra/auth"
+	"github.com/cgund98/voer/internal/infra/events"
+)
+
+// Config lists the webhooks events are delivered to
+type Config struct {
+	Webhooks []Webhook `yaml:"webhooks" json:"webhooks"`
+}
+
+// Webhook is a receiver of events
+type Webhook struct {
+	// Unique name identifying the webhook in delivery logs
+	Name string `yaml:"name" json:"name"`
+	URL  string `yaml:"url" json:"url"`
+
+	// Key signing each delivery, given directly or read from an environment variable
+	Secret    string `yaml:"secret" json:"secret"`
+	SecretEnv string `yaml:"secretEnv" json:"secretEnv"`
+
+	// Event types delivered to the webhook. Every type is delivered when empty.
+	Events []string `yaml:"events" json:"events"`
+
+	// Package patterns, e.g. "payments.*", whose events are delivered. Every package is delivered when empty.
+	Packages []string `yaml:"packages" json:"packages"`
+}
+
+// LoadConfig reads a webhook configuration from a YAML or JSON file, resolving secrets given by environment variable
+func LoadConfig(path string) (*Config, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook config: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook config: %w", err)
+	}
+
+	for i := range config.Webhooks {
+		webhook := &config.Webhooks[i]
+		if webhook.Secret == "" && webhook.SecretEnv != "" {
+			webhook.Secret = os.Getenv(webhook.SecretEnv)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// Validate ensures every webhook has a unique name, an HTTP(S) URL, a secret and known event types and patterns
+func (c *Config) Validate() error {
+	names := make(map[string]bool)
+	for _, webhook := range c.Webhooks {
+		if webhook.Name == "" {
+			return fmt.Errorf("webhook %s has no name", webhook.URL)
+		}
+		if names[webhook.Name] {
+			return fmt.Errorf("duplicate webhook name: %s", webhook.Name)
+		}
+		names[webhook.Name] = true
+
+		parsed, err := url.Parse(webhook.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("webhook %s has an invalid URL: %q", webhook.Name, webhook.URL)
+		}
+
+		if webhook.Secret == "" {
+			return fmt.Errorf("webhook %s has no secret to sign deliveries with", webhook.Name)
+		}
+
+		for _, eventType := range webhook.Events {
+			if !slices.Contains(events.Types, eventType) {
+				return fmt.Errorf("webhook %s has an unknown event type: %s", webhook.Name, eventType)
+			}
+		}
+		for _, pattern := range webhook.Packages {
+			if err := auth.ValidatePackagePattern(pattern); err != nil {
+				return fmt.Errorf("webhook %s: %w", webhook.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Matches returns true if an event should be delivered to the webhook
+func (w *Webhook) Matches(event events.Event) bool {
+	if len(w.Events) > 0 && !slices.Contains(w.Events, event.Type) {
+		return false
+	}
+	if len(w.Packages) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(w.Packages, func(pattern string) bool {
+		return auth.MatchPackagePattern(pattern, event.PackageName)
+	})
+}
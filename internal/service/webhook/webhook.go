@@ -0,0 +1,208 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/events"
+	"github.com/cgund98/voer/internal/infra/logging"
+)
+
+const (
+	// Delivery headers
+	HeaderEvent     = "X-Voer-Event"
+	HeaderDelivery  = "X-Voer-Delivery"
+	HeaderTimestamp = "X-Voer-Timestamp"
+	HeaderSignature = "X-Voer-Signature"
+
+	// Delivery results
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+
+	// How many events are queued for each webhook before they are dropped
+	queueSize = 1000
+
+	// How long a receiver has to respond to a delivery
+	deliveryTimeout = 10 * time.Second
+)
+
+// defaultRetryDelays are the delays before each retry of a failed delivery
+var defaultRetryDelays = []time.Duration{time.Second, 5 * time.Second, 30 * time.Second, 2 * time.Minute}
+
+// Sign returns the signature of a delivery: the hex encoded HMAC-SHA256 of the timestamp, a dot and the body,
+// prefixed with "sha256="
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher delivers published events to webhooks, retrying failed deliveries and logging every attempt
+type Dispatcher struct {
+	webhooks     []Webhook
+	deliveries   repo.WebhookDeliveryRepository
+	client       *http.Client
+	subscription *events.Subscription
+
+	retryDelays []time.Duration
+}
+
+// NewDispatcher subscribes to the events published to a bus, which are delivered once the dispatcher runs
+func NewDispatcher(config *Config, deliveries repo.WebhookDeliveryRepository, bus *events.Bus) *Dispatcher {
+	return &Dispatcher{
+		webhooks:     config.Webhooks,
+		deliveries:   deliveries,
+		client:       &http.Client{Timeout: deliveryTimeout},
+		subscription: bus.Subscribe(queueSize),
+		retryDelays:  defaultRetryDelays,
+	}
+}
+
+// Run delivers events until ctx is cancelled. Each webhook receives its events in order.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	logging.Logger.Info("Starting webhook dispatcher...", "webhooks", len(d.webhooks))
+	defer d.subscription.Close()
+
+	var wg sync.WaitGroup
+	queues := make([]chan events.Event, len(d.webhooks))
+	for i := range d.webhooks {
+		queues[i] = make(chan events.Event, queueSize)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.work(ctx, &d.webhooks[i], queues[i])
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case event := <-d.subscription.C:
+			for i := range d.webhooks {
+				if !d.webhooks[i].Matches(event) {
+					continue
+				}
+				select {
+				case queues[i] <- event:
+				default:
+					logging.Logger.Warn("Dropped event for slow webhook", "webhook", d.webhooks[i].Name, "event", event.ID, "type", event.Type)
+				}
+			}
+		}
+	}
+}
+
+// work delivers a webhook's queued events one at a time until ctx is cancelled
+func (d *Dispatcher) work(ctx context.Context, webhook *Webhook, queue <-chan events.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-queue:
+			d.deliver(ctx, webhook, event)
+		}
+	}
+}
+
+// deliver sends an event to a webhook, retrying until the receiver accepts it or every retry has failed
+func (d *Dispatcher) deliver(ctx context.Context, webhook *Webhook, event events.Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		logging.Logger.Error("Failed to encode event", "event", event.ID, "error", err)
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := d.attempt(ctx, webhook, event, body, attempt)
+		if err == nil {
+			return
+		}
+
+		if attempt > len(d.retryDelays) {
+			logging.Logger.Error("Gave up delivering event to webhook", "webhook", webhook.Name, "event", event.ID, "attempts", attempt, "error", err)
+			return
+		}
+
+		delay := d.retryDelays[attempt-1]
+		logging.Logger.Warn("Failed to deliver event to webhook", "webhook", webhook.Name, "event", event.ID, "attempt", attempt, "retry_in", delay.String(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attempt makes a single delivery and records it in the delivery log
+func (d *Dispatcher) attempt(ctx context.Context, webhook *Webhook, event events.Event, body []byte, attempt int) error {
+	start := time.Now()
+	statusCode, err := d.post(ctx, webhook, event, body)
+
+	delivery := &entity.WebhookDelivery{
+		Webhook:     webhook.Name,
+		URL:         webhook.URL,
+		EventID:     event.ID,
+		EventType:   event.Type,
+		PackageName: event.PackageName,
+		Version:     event.Version,
+		Attempt:     attempt,
+		StatusCode:  statusCode,
+		DurationMS:  time.Since(start).Milliseconds(),
+		Result:      ResultSuccess,
+	}
+	if err != nil {
+		delivery.Result = ResultFailure
+		delivery.Error = err.Error()
+	}
+
+	if createErr := d.deliveries.Create(delivery); createErr != nil {
+		logging.Logger.Error("Failed to record webhook delivery", "webhook", webhook.Name, "event", event.ID, "error", createErr)
+	}
+
+	return err
+}
+
+// post sends a signed event to a webhook. Any response other than 2xx is an error.
+func (d *Dispatcher) post(ctx context.Context, webhook *Webhook, event events.Event, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "voer-webhook")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, event.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("receiver responded with %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/cgund98/voer/api/v1"
+	"github.com/cgund98/voer/internal/entity/ctrl"
+	entity "github.com/cgund98/voer/internal/entity/db"
+	"github.com/cgund98/voer/internal/entity/repo"
+	"github.com/cgund98/voer/internal/infra/events"
+)
+
+// receiver records the events delivered to it, failing the first delivery of each event
+type receiver struct {
+	mu       sync.Mutex
+	attempts map[string]int
+	received chan events.Event
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if Sign("s3cret", r.Header.Get(HeaderTimestamp), body) != r.Header.Get(HeaderSignature) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var event events.Event
+	if err := json.Unmarshal(body, &event); err != nil || event.Type != r.Header.Get(HeaderEvent) {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+
+	rc.mu.Lock()
+	rc.attempts[event.ID]++
+	first := rc.attempts[event.ID] == 1
+	rc.mu.Unlock()
+
+	if first {
+		http.Error(w, "try again", http.StatusServiceUnavailable)
+		return
+	}
+	rc.received <- event
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rc := &receiver{attempts: make(map[string]int), received: make(chan events.Event, 10)}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	config := &Config{Webhooks: []Webhook{{
+		Name:     "ci",
+		URL:      server.URL,
+		Secret:   "s3cret",
+		Packages: []string{"hooks.*"},
+	}}}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Invalid config: %v", err)
+	}
+
+	store := repo.NewMemoryStore()
+	bus := events.NewBus()
+	dispatcher := NewDispatcher(config, store.WebhookDeliveries(), bus)
+	dispatcher.retryDelays = []time.Duration{10 * time.Millisecond}
+
+	done := make(chan struct{})
+	go func() {
+		_ = dispatcher.Run(ctx)
+		close(done)
+	}()
+
+	upload := func(packageName string) {
+		_, err := ctrl.CreatePackageVersion(ctx, store, bus, ctrl.UploadPolicy{}, &v1.UploadPackageVersionRequest{
+			Packages: []*v1.PackageFile{{
+				PackageName: packageName,
+				Files: []*v1.ProtoFile{{
+					FileName:     "test.proto",
+					FileContents: "syntax = \"proto3\";\npackage " + packageName + ";\nmessage A { string x = 1; }\n",
+				}},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("Failed to upload %s: %v", packageName, err)
+		}
+	}
+
+	// Packages outside the webhook's patterns are not delivered
+	upload("other")
+	upload("hooks.v1")
+	if _, err := ctrl.DeletePackageVersion(ctx, store, bus, nil, &v1.DeletePackageVersionRequest{PackageName: "hooks.v1", Version: 1}); err != nil {
+		t.Fatalf("Failed to delete version: %v", err)
+	}
+
+	for _, expected := range []string{events.TypePackageVersionCreated, events.TypePackageVersionDeleted} {
+		select {
+		case event := <-rc.received:
+			if event.Type != expected || event.PackageName != "hooks.v1" || event.Version != 1 {
+				t.Fatalf("Expected %s of hooks.v1 v1, got %+v", expected, event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %s", expected)
+		}
+	}
+
+	// Every attempt is logged, including the failed first attempts
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deliveries, err := store.WebhookDeliveries().List(entity.WebhookDeliveryFilter{Webhook: "ci"})
+		if err != nil {
+			t.Fatalf("Failed to list deliveries: %v", err)
+		}
+		if len(deliveries) == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 4 deliveries, got %d", len(deliveries))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+
+	failed, _ := store.WebhookDeliveries().List(entity.WebhookDeliveryFilter{Result: ResultFailure})
+	if len(failed) != 2 || failed[0].StatusCode != http.StatusServiceUnavailable || failed[0].Attempt != 1 {
+		t.Fatalf("Expected 2 failed first attempts, got %+v", failed)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Webhook{Name: "ci", URL: "https://example.com/hook", Secret: "s3cret"}
+
+	tests := []struct {
+		name    string
+		webhook func(w Webhook) Webhook
+	}{
+		{"missing name", func(w Webhook) Webhook { w.Name = ""; return w }},
+		{"invalid url", func(w Webhook) Webhook { w.URL = "ftp://example.com"; return w }},
+		{"missing secret", func(w Webhook) Webhook { w.Secret = ""; return w }},
+		{"unknown event", func(w Webhook) Webhook { w.Events = []string{"package.created"}; return w }},
+		{"invalid pattern", func(w Webhook) Webhook { w.Packages = []string{"pay*"}; return w }},
+	}
+
+	if err := (&Config{Webhooks: []Webhook{valid}}).Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if err := (&Config{Webhooks: []Webhook{valid, valid}}).Validate(); err == nil {
+		t.Fatal("Expected duplicate names to be rejected")
+	}
+	for _, tt := range tests {
+		if err := (&Config{Webhooks: []Webhook{tt.webhook(valid)}}).Validate(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}